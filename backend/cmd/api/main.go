@@ -22,6 +22,14 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Schema management runs instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	// Create dependency container
 	cont, err := container.NewContainer(cfg)
 	if err != nil {
@@ -55,12 +63,33 @@ func main() {
 		}
 	}()
 
+	// Metrics are served on their own port so they are not exposed with the API
+	metricsSrv := cont.MetricsServer()
+	if metricsSrv != nil {
+		go func() {
+			cont.Logger().Info("starting metrics server",
+				logger.String("address", metricsSrv.Addr),
+			)
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				cont.Logger().Error("metrics server error",
+					logger.Error(err),
+				)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	cont.Logger().Info("shutting down gracefully")
+	// Fail readiness first so load balancers drain the instance while it
+	// still serves requests
+	cont.Health().MarkShuttingDown()
+	cont.Logger().Info("shutting down gracefully",
+		logger.String("drain_delay", cfg.Server.DrainDelay.String()),
+	)
+	time.Sleep(cfg.Server.DrainDelay)
 
 	// Create shutdown context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -74,5 +103,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			cont.Logger().Error("metrics server forced to shutdown",
+				logger.Error(err),
+			)
+		}
+	}
+
 	cont.Logger().Info("server stopped")
 }
@@ -0,0 +1,25 @@
+// Command openapi writes the API's OpenAPI document. It is run by go
+// generate in internal/interface/api/docs.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/openapi"
+)
+
+func main() {
+	root := flag.String("root", ".", "module root directory")
+	out := flag.String("out", "internal/interface/api/docs/openapi.json", "file to write the document to")
+	flag.Parse()
+
+	doc, err := openapi.Generate(*root)
+	if err != nil {
+		log.Fatalf("Failed to generate OpenAPI document: %v", err)
+	}
+	if err := os.WriteFile(*out, doc, 0o644); err != nil {
+		log.Fatalf("Failed to write %s: %v", *out, err)
+	}
+}
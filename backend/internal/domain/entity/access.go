@@ -0,0 +1,131 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PollVisibility controls who can find and vote in a poll
+type PollVisibility string
+
+const (
+	// VisibilityPublic polls are listed and open to everyone
+	VisibilityPublic PollVisibility = "public"
+	// VisibilityUnlisted polls are open to anyone with the link but are
+	// left out of listings
+	VisibilityUnlisted PollVisibility = "unlisted"
+	// VisibilityPrivate polls are unlisted and only accept votes with an
+	// invite code or from an allowlisted voter
+	VisibilityPrivate PollVisibility = "private"
+)
+
+// ParsePollVisibility validates a visibility name, defaulting to public
+func ParsePollVisibility(s string) (PollVisibility, error) {
+	switch v := PollVisibility(s); v {
+	case "":
+		return VisibilityPublic, nil
+	case VisibilityPublic, VisibilityUnlisted, VisibilityPrivate:
+		return v, nil
+	default:
+		return "", ErrInvalidPollVisibility
+	}
+}
+
+// AllowlistKind is the identity an allowlist entry matches on
+type AllowlistKind string
+
+const (
+	AllowlistEmail  AllowlistKind = "email"
+	AllowlistUserID AllowlistKind = "user_id"
+)
+
+// AllowlistEntry admits a single voter to a private poll
+type AllowlistEntry struct {
+	Kind  AllowlistKind
+	Value string
+}
+
+// NewAllowlistEntry validates and normalizes an allowlist entry
+func NewAllowlistEntry(kind AllowlistKind, value string) (AllowlistEntry, error) {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case AllowlistEmail:
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value {
+			return AllowlistEntry{}, ErrInvalidAllowlistEntry
+		}
+		value = strings.ToLower(value)
+	case AllowlistUserID:
+		if value == "" {
+			return AllowlistEntry{}, ErrInvalidAllowlistEntry
+		}
+	default:
+		return AllowlistEntry{}, ErrInvalidAllowlistEntry
+	}
+	return AllowlistEntry{Kind: kind, Value: value}, nil
+}
+
+// Invite lets its holder vote in a private poll up to MaxUses times in total
+type Invite struct {
+	ID        uuid.UUID
+	PollID    uuid.UUID
+	CodeHash  string
+	MaxUses   int
+	Uses      int
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+	RevokedAt *time.Time
+
+	// Code is only populated on the invite returned from creation
+	Code string
+}
+
+// NewInvite creates an invite with a fresh random code. A maxUses of zero
+// makes the invite single-use.
+func NewInvite(pollID uuid.UUID, maxUses int, expiresAt *time.Time) (*Invite, error) {
+	if maxUses == 0 {
+		maxUses = 1
+	}
+	if maxUses < 0 {
+		return nil, ErrInvalidInvite
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidInvite
+	}
+
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate invite code: %w", err)
+	}
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+
+	return &Invite{
+		ID:        uuid.New(),
+		PollID:    pollID,
+		CodeHash:  HashInviteCode(code),
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+		Code:      code,
+	}, nil
+}
+
+// HashInviteCode returns the stored form of an invite code. Codes are
+// case-insensitive so they survive being read out or retyped.
+func HashInviteCode(code string) string {
+	hash := sha256.Sum256([]byte(strings.ToUpper(strings.TrimSpace(code))))
+	return hex.EncodeToString(hash[:])
+}
+
+// IsPrivate reports whether votes need an invite or allowlist entry
+func (p *Poll) IsPrivate() bool {
+	return p.Visibility == VisibilityPrivate
+}
@@ -0,0 +1,72 @@
+package entity
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+)
+
+// Actor is the caller on whose behalf a request is made, along with any
+// credentials they presented
+type Actor struct {
+	Identifier  VoteIdentifier
+	OwnerToken  string
+	InviteCode  string
+	Password    string
+	AccessToken string
+	IsAdmin     bool
+
+	// ProofOfWork is set once the handler has verified a solved challenge
+	ProofOfWork bool
+
+	// UserID and Email are set when an authenticating proxy vouches for them
+	UserID string
+	Email  string
+
+	// RequestID ties changes the actor makes to the request logs
+	RequestID string
+}
+
+type actorContextKey struct{}
+
+// ContextWithActor returns a copy of ctx carrying the actor
+func ContextWithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the actor on ctx, or an anonymous actor if none
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorContextKey{}).(Actor)
+	return actor
+}
+
+// NewOwnerToken generates a random secret that identifies a poll's creator
+func NewOwnerToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate owner token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashOwnerToken returns the form of an owner token stored with the poll
+func HashOwnerToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// IsOwnedBy reports whether the actor presented the poll's owner token
+func (p *Poll) IsOwnedBy(actor Actor) bool {
+	if p.OwnerTokenHash == "" || actor.OwnerToken == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(p.OwnerTokenHash), []byte(HashOwnerToken(actor.OwnerToken))) == 1
+}
+
+// CanManage reports whether the actor may administer the poll
+func (p *Poll) CanManage(actor Actor) bool {
+	return actor.IsAdmin || p.IsOwnedBy(actor)
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TrendingScore is a poll's time-decayed vote velocity over a sliding window
+type TrendingScore struct {
+	PollID      uuid.UUID
+	RecentVotes int
+	Score       float64
+}
+
+// TrendingPoll pairs a poll with its trending score
+type TrendingPoll struct {
+	Poll         *Poll
+	RecentVotes  int
+	VotesPerHour float64
+	Score        float64
+}
+
+// TrendingPolls is a ranked snapshot of trending polls
+type TrendingPolls struct {
+	Polls       []TrendingPoll
+	Window      time.Duration
+	GeneratedAt time.Time
+}
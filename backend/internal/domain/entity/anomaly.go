@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AnomalySignal is a voting pattern that suggests ballot-stuffing
+type AnomalySignal string
+
+const (
+	// SignalSubnetBurst counts votes in the poll from the vote's IP subnet
+	SignalSubnetBurst AnomalySignal = "subnet_burst"
+	// SignalFingerprintsPerIP counts distinct fingerprints voting in the
+	// poll from the vote's IP
+	SignalFingerprintsPerIP AnomalySignal = "fingerprints_per_ip"
+	// SignalUserAgentBurst counts votes in the poll with the vote's user agent
+	SignalUserAgentBurst AnomalySignal = "user_agent_burst"
+)
+
+// AnomalyRule flags a vote when its signal, counted over Window and
+// including the vote itself, exceeds Threshold. Flagged rules add their
+// Weight to the vote's score.
+type AnomalyRule struct {
+	Signal    AnomalySignal
+	Window    time.Duration
+	Threshold int
+	Weight    int
+}
+
+// QuarantineStatus tracks a flagged vote through review
+type QuarantineStatus string
+
+const (
+	// QuarantinePending votes are left out of results until reviewed
+	QuarantinePending QuarantineStatus = "quarantined"
+	// QuarantineApproved votes were cleared by an admin and count again
+	QuarantineApproved QuarantineStatus = "approved"
+	// QuarantineRejected votes were confirmed bad and never count
+	QuarantineRejected QuarantineStatus = "rejected"
+)
+
+// QuarantinedVote is a vote held back from results by anomaly detection
+type QuarantinedVote struct {
+	VoteID        uuid.UUID
+	PollID        uuid.UUID
+	OptionID      uuid.UUID
+	Status        QuarantineStatus
+	Reasons       []AnomalySignal
+	Score         int
+	CreatedAt     time.Time
+	QuarantinedAt time.Time
+	ReviewedAt    *time.Time
+}
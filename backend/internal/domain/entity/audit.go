@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction is a change made to a poll that is recorded in its audit log
+type AuditAction string
+
+const (
+	AuditPollCreated  AuditAction = "poll.created"
+	AuditPollUpdated  AuditAction = "poll.updated"
+	AuditPollClosed   AuditAction = "poll.closed"
+	AuditPollDeleted  AuditAction = "poll.deleted"
+	AuditPollArchived AuditAction = "poll.archived"
+	AuditPollRestored AuditAction = "poll.restored"
+)
+
+// FieldChange is one poll field's value before and after a change. Before
+// is nil for a new poll.
+type FieldChange struct {
+	Field  string
+	Before interface{}
+	After  interface{}
+}
+
+// AuditEvent records who changed a poll, when, and how
+type AuditEvent struct {
+	ID     uuid.UUID
+	PollID uuid.UUID
+	Action AuditAction
+
+	// Actor describes the caller: admin, owner, user:<id> or anonymous
+	Actor        string
+	RequestID    string
+	ClientIPHash string
+	Changes      []FieldChange
+	CreatedAt    time.Time
+}
+
+// NewAuditEvent records action on poll by actor. before is nil when the
+// poll is being created.
+func NewAuditEvent(action AuditAction, before, after *Poll, actor Actor) *AuditEvent {
+	return &AuditEvent{
+		ID:           uuid.New(),
+		PollID:       after.ID,
+		Action:       action,
+		Actor:        actor.auditName(after),
+		RequestID:    actor.RequestID,
+		ClientIPHash: actor.Identifier.IPHash,
+		Changes:      DiffPolls(before, after),
+		CreatedAt:    time.Now(),
+	}
+}
+
+func (a Actor) auditName(poll *Poll) string {
+	switch {
+	case a.IsAdmin:
+		return "admin"
+	case poll.IsOwnedBy(a):
+		return "owner"
+	case a.UserID != "":
+		return "user:" + a.UserID
+	default:
+		return "anonymous"
+	}
+}
+
+// auditedFields returns the poll fields tracked by the audit log. Secrets
+// such as the password hash are reduced to whether they are set.
+func auditedFields(p *Poll) map[string]interface{} {
+	options := make([]string, len(p.Options))
+	for i, opt := range p.Options {
+		options[i] = opt.OptionText
+	}
+
+	return map[string]interface{}{
+		"question":           p.Question,
+		"options":            options,
+		"is_active":          p.IsActive,
+		"expires_at":         p.ExpiresAt,
+		"starts_at":          p.StartsAt,
+		"archived_at":        p.ArchivedAt,
+		"deleted_at":         p.DeletedAt,
+		"results_visibility": string(p.ResultsVisibility),
+		"visibility":         string(p.Visibility),
+		"dedup_policy":       string(p.DedupPolicy),
+		"proof_of_work":      p.ProofOfWork,
+		"password_protected": p.PasswordHash != "",
+	}
+}
+
+// auditedFieldOrder lists audited fields in the order changes are reported
+var auditedFieldOrder = []string{
+	"question", "options", "is_active", "expires_at", "starts_at", "archived_at", "deleted_at",
+	"results_visibility", "visibility", "dedup_policy", "proof_of_work", "password_protected",
+}
+
+// DiffPolls lists the audited fields that differ between before and after.
+// With no before, every field is reported as set.
+func DiffPolls(before, after *Poll) []FieldChange {
+	afterFields := auditedFields(after)
+	var beforeFields map[string]interface{}
+	if before != nil {
+		beforeFields = auditedFields(before)
+	}
+
+	changes := make([]FieldChange, 0)
+	for _, field := range auditedFieldOrder {
+		change := FieldChange{Field: field, After: afterFields[field]}
+		if beforeFields != nil {
+			change.Before = beforeFields[field]
+			if equalFieldValues(change.Before, change.After) {
+				continue
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// equalFieldValues compares field values, treating times as equal when
+// they are the same instant and nil pointers as equal to each other
+func equalFieldValues(a, b interface{}) bool {
+	ta, aIsTime := a.(*time.Time)
+	tb, bIsTime := b.(*time.Time)
+	if aIsTime && bIsTime {
+		if ta == nil || tb == nil {
+			return ta == nil && tb == nil
+		}
+		return ta.Equal(*tb)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// Challenge is a proof-of-work puzzle a voter must solve before voting in a
+// poll that requires one. The solution is a string whose SHA-256 hash,
+// taken together with the token, starts with Difficulty zero bits.
+type Challenge struct {
+	Token      string
+	Difficulty int
+	ExpiresAt  time.Time
+}
+
+// RequiresProofOfWork reports whether votes need a solved challenge
+func (p *Poll) RequiresProofOfWork() bool {
+	return p.ProofOfWork
+}
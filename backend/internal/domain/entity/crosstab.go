@@ -0,0 +1,286 @@
+package entity
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// CrossTabCount is the number of voters who chose RowOptionID in the row
+// poll and ColumnOptionID in the column poll
+type CrossTabCount struct {
+	RowOptionID    uuid.UUID
+	ColumnOptionID uuid.UUID
+	Votes          int
+}
+
+// CrossTabCell is one cell of a contingency table. Suppressed cells carry no
+// count or percentage.
+type CrossTabCell struct {
+	RowOptionID    uuid.UUID
+	ColumnOptionID uuid.UUID
+	Votes          int
+	Percentage     float64
+	Suppressed     bool
+}
+
+// CrossTabMargin is a row or column total of a contingency table
+type CrossTabMargin struct {
+	OptionID   uuid.UUID
+	Votes      int
+	Percentage float64
+}
+
+// CrossTab is a contingency table of answers to two polls given by the same
+// voters, with a chi-square test of independence. TestSuppressed is set
+// when the test is withheld because the table has suppressed cells.
+type CrossTab struct {
+	RowPollID        uuid.UUID
+	ColumnPollID     uuid.UUID
+	TotalVoters      int
+	MinCellSize      int
+	Cells            []CrossTabCell
+	Rows             []CrossTabMargin
+	Columns          []CrossTabMargin
+	ChiSquare        float64
+	DegreesOfFreedom int
+	PValue           float64
+	TestSuppressed   bool
+}
+
+// BuildCrossTab assembles the contingency table for two polls. Cells with
+// fewer than minCellSize voters are suppressed, and when a row or column
+// would have a single suppressed cell its next smallest cell is suppressed
+// too so the hidden count cannot be recovered from the margins. The
+// chi-square test is only given when no cell is suppressed: with the margins
+// published, the statistic would give the hidden counts away.
+func BuildCrossTab(rowPoll, columnPoll *Poll, counts []CrossTabCount, minCellSize int) *CrossTab {
+	rows, cols := len(rowPoll.Options), len(columnPoll.Options)
+	rowIndex := make(map[uuid.UUID]int, rows)
+	for i, opt := range rowPoll.Options {
+		rowIndex[opt.ID] = i
+	}
+	colIndex := make(map[uuid.UUID]int, cols)
+	for j, opt := range columnPoll.Options {
+		colIndex[opt.ID] = j
+	}
+
+	observed := make([][]int, rows)
+	for i := range observed {
+		observed[i] = make([]int, cols)
+	}
+	rowTotals := make([]int, rows)
+	colTotals := make([]int, cols)
+	total := 0
+	for _, count := range counts {
+		i, okRow := rowIndex[count.RowOptionID]
+		j, okCol := colIndex[count.ColumnOptionID]
+		if !okRow || !okCol {
+			continue
+		}
+		observed[i][j] += count.Votes
+		rowTotals[i] += count.Votes
+		colTotals[j] += count.Votes
+		total += count.Votes
+	}
+
+	crossTab := &CrossTab{
+		RowPollID:    rowPoll.ID,
+		ColumnPollID: columnPoll.ID,
+		TotalVoters:  total,
+		MinCellSize:  minCellSize,
+		Cells:        make([]CrossTabCell, 0, rows*cols),
+		Rows:         make([]CrossTabMargin, rows),
+		Columns:      make([]CrossTabMargin, cols),
+	}
+
+	suppressed := suppressCells(observed, minCellSize)
+	for i, rowOpt := range rowPoll.Options {
+		for j, colOpt := range columnPoll.Options {
+			cell := CrossTabCell{
+				RowOptionID:    rowOpt.ID,
+				ColumnOptionID: colOpt.ID,
+				Suppressed:     suppressed[i][j],
+			}
+			if cell.Suppressed {
+				crossTab.TestSuppressed = true
+			} else {
+				cell.Votes = observed[i][j]
+				cell.Percentage = percentOf(observed[i][j], total)
+			}
+			crossTab.Cells = append(crossTab.Cells, cell)
+		}
+		crossTab.Rows[i] = CrossTabMargin{
+			OptionID:   rowOpt.ID,
+			Votes:      rowTotals[i],
+			Percentage: percentOf(rowTotals[i], total),
+		}
+	}
+	for j, colOpt := range columnPoll.Options {
+		crossTab.Columns[j] = CrossTabMargin{
+			OptionID:   colOpt.ID,
+			Votes:      colTotals[j],
+			Percentage: percentOf(colTotals[j], total),
+		}
+	}
+
+	if crossTab.TestSuppressed {
+		return crossTab
+	}
+	crossTab.ChiSquare, crossTab.DegreesOfFreedom = chiSquare(observed, rowTotals, colTotals, total)
+	crossTab.PValue = chiSquarePValue(crossTab.ChiSquare, crossTab.DegreesOfFreedom)
+
+	return crossTab
+}
+
+// suppressCells marks cells below minSize, then applies complementary
+// suppression until no row or column has exactly one suppressed cell
+func suppressCells(observed [][]int, minSize int) [][]bool {
+	suppressed := make([][]bool, len(observed))
+	for i := range observed {
+		suppressed[i] = make([]bool, len(observed[i]))
+		for j, votes := range observed[i] {
+			suppressed[i][j] = votes > 0 && votes < minSize
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for i := range observed {
+			cells := make([][2]int, len(observed[i]))
+			for j := range observed[i] {
+				cells[j] = [2]int{i, j}
+			}
+			changed = suppressComplement(observed, suppressed, cells) || changed
+		}
+		for j := range observed[0] {
+			cells := make([][2]int, len(observed))
+			for i := range observed {
+				cells[i] = [2]int{i, j}
+			}
+			changed = suppressComplement(observed, suppressed, cells) || changed
+		}
+	}
+
+	return suppressed
+}
+
+// suppressComplement hides the smallest visible cell of a line that has
+// exactly one suppressed cell, returning whether anything changed
+func suppressComplement(observed [][]int, suppressed [][]bool, line [][2]int) bool {
+	hidden, smallest := 0, -1
+	for k, cell := range line {
+		if suppressed[cell[0]][cell[1]] {
+			hidden++
+			continue
+		}
+		if smallest < 0 || observed[cell[0]][cell[1]] < observed[line[smallest][0]][line[smallest][1]] {
+			smallest = k
+		}
+	}
+	if hidden != 1 || smallest < 0 {
+		return false
+	}
+	suppressed[line[smallest][0]][line[smallest][1]] = true
+	return true
+}
+
+// chiSquare returns Pearson's chi-square statistic for independence and its
+// degrees of freedom. Empty rows and columns are left out of both.
+func chiSquare(observed [][]int, rowTotals, colTotals []int, total int) (float64, int) {
+	if total == 0 {
+		return 0, 0
+	}
+
+	var statistic float64
+	usedRows, usedCols := 0, 0
+	for _, t := range rowTotals {
+		if t > 0 {
+			usedRows++
+		}
+	}
+	for _, t := range colTotals {
+		if t > 0 {
+			usedCols++
+		}
+	}
+
+	for i := range observed {
+		for j := range observed[i] {
+			expected := float64(rowTotals[i]) * float64(colTotals[j]) / float64(total)
+			if expected == 0 {
+				continue
+			}
+			diff := float64(observed[i][j]) - expected
+			statistic += diff * diff / expected
+		}
+	}
+
+	if usedRows < 2 || usedCols < 2 {
+		return 0, 0
+	}
+	return statistic, (usedRows - 1) * (usedCols - 1)
+}
+
+// chiSquarePValue is the upper tail probability of the chi-square
+// distribution, i.e. the regularized upper incomplete gamma Q(df/2, x/2)
+func chiSquarePValue(statistic float64, df int) float64 {
+	if df <= 0 {
+		return 1
+	}
+	return upperIncompleteGamma(float64(df)/2, statistic/2)
+}
+
+func upperIncompleteGamma(a, x float64) float64 {
+	if x <= 0 {
+		return 1
+	}
+	lgamma, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lgamma)
+
+	if x < a+1 {
+		// Series expansion of the lower function P(a, x)
+		sum, term := 1/a, 1/a
+		for n := 1; n < 500; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*1e-14 {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+
+	// Lentz's continued fraction for Q(a, x)
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < 500; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < 1e-14 {
+			break
+		}
+	}
+	return math.Min(1, prefix*h)
+}
+
+func percentOf(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
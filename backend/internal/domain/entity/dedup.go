@@ -0,0 +1,61 @@
+package entity
+
+// DedupPolicy decides which identifiers make two votes in a poll duplicates
+type DedupPolicy string
+
+const (
+	// DedupFingerprint allows one vote per browser fingerprint
+	DedupFingerprint DedupPolicy = "fingerprint"
+	// DedupIP allows one vote per IP address
+	DedupIP DedupPolicy = "ip"
+	// DedupEither rejects a vote if its IP or its fingerprint has voted
+	DedupEither DedupPolicy = "either"
+	// DedupBoth rejects a vote only if the same IP and fingerprint have voted
+	DedupBoth DedupPolicy = "both"
+	// DedupUser allows one vote per authenticated user and rejects
+	// anonymous votes
+	DedupUser DedupPolicy = "user"
+	// DedupNone accepts every vote
+	DedupNone DedupPolicy = "none"
+)
+
+// ParseDedupPolicy validates a policy name, defaulting to both
+func ParseDedupPolicy(s string) (DedupPolicy, error) {
+	switch p := DedupPolicy(s); p {
+	case "":
+		return DedupBoth, nil
+	case DedupFingerprint, DedupIP, DedupEither, DedupBoth, DedupUser, DedupNone:
+		return p, nil
+	default:
+		return "", ErrInvalidDedupPolicy
+	}
+}
+
+// Validate checks that the identifier carries what the policy matches on
+func (p DedupPolicy) Validate(identifier VoteIdentifier) error {
+	hasIP := identifier.IPHash != ""
+	hasFingerprint := identifier.FingerprintHash != ""
+
+	var ok bool
+	switch p {
+	case DedupFingerprint:
+		ok = hasFingerprint
+	case DedupIP:
+		ok = hasIP
+	case DedupEither:
+		ok = hasIP || hasFingerprint
+	case DedupBoth:
+		ok = hasIP && hasFingerprint
+	case DedupUser:
+		if identifier.UserID == "" {
+			return ErrVoterNotAuthenticated
+		}
+		ok = true
+	case DedupNone:
+		ok = true
+	}
+	if !ok {
+		return ErrInvalidVoteIdentifier
+	}
+	return nil
+}
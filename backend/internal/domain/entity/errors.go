@@ -1,13 +1,98 @@
 package entity
 
-import "errors"
+import (
+	"net/http"
+	"strings"
+)
 
 var (
-	ErrInsufficientOptions   = errors.New("at least two options are required")
-	ErrPollInactive          = errors.New("poll is inactive")
-	ErrPollExpired           = errors.New("poll has expired")
-	ErrPollNotFound          = errors.New("poll not found")
-	ErrInvalidOption         = errors.New("invalid option")
-	ErrDuplicateVote         = errors.New("duplicate vote")
-	ErrInvalidVoteIdentifier = errors.New("invalid vote identifier")
+	ErrInsufficientOptions      = newError("INSUFFICIENT_OPTIONS", http.StatusBadRequest, "A poll needs at least two options")
+	ErrPollInactive             = newError("POLL_INACTIVE", http.StatusForbidden, "This poll is no longer active")
+	ErrPollExpired              = newError("POLL_EXPIRED", http.StatusForbidden, "This poll has expired")
+	ErrPollArchived             = newError("POLL_ARCHIVED", http.StatusForbidden, "This poll has been archived")
+	ErrPollNotStarted           = newError("POLL_NOT_STARTED", http.StatusForbidden, "This poll has not opened for voting yet")
+	ErrInvalidSchedule          = newError("INVALID_SCHEDULE", http.StatusBadRequest, "A poll must start before it expires")
+	ErrPollNotFound             = newError("POLL_NOT_FOUND", http.StatusNotFound, "Poll not found")
+	ErrInvalidOption            = newError("INVALID_OPTION", http.StatusBadRequest, "The option does not belong to this poll")
+	ErrDuplicateVote            = newError("DUPLICATE_VOTE", http.StatusConflict, "You have already voted in this poll")
+	ErrInvalidVoteIdentifier    = newError("INVALID_VOTE_IDENTIFIER", http.StatusBadRequest, "The voter could not be identified")
+	ErrInvalidCursor            = newError("INVALID_CURSOR", http.StatusBadRequest, "The pagination cursor is invalid")
+	ErrInvalidBucket            = newError("INVALID_BUCKET", http.StatusBadRequest, "Timeline bucket must be one of 1m, 1h, 1d or 1w")
+	ErrInvalidCrossTab          = newError("INVALID_CROSSTAB", http.StatusBadRequest, "Cross-tabulation requires two different polls")
+	ErrInvalidResultsVisibility = newError("INVALID_RESULTS_VISIBILITY", http.StatusBadRequest, "Results visibility must be one of public, after_vote, after_close or creator_only")
+	ErrResultsHidden            = newError("RESULTS_HIDDEN", http.StatusForbidden, "Results for this poll are not visible yet")
+	ErrInvalidPollVisibility    = newError("INVALID_VISIBILITY", http.StatusBadRequest, "Visibility must be one of public, unlisted or private")
+	ErrInvalidAllowlistEntry    = newError("INVALID_ALLOWLIST", http.StatusBadRequest, "Allowlist entries must be valid emails or non-empty user IDs")
+	ErrPollPrivate              = newError("POLL_PRIVATE", http.StatusForbidden, "This poll requires an invite code")
+	ErrInvalidInvite            = newError("INVALID_INVITE", http.StatusForbidden, "The invite code is invalid, expired or used up")
+	ErrInviteNotFound           = newError("INVITE_NOT_FOUND", http.StatusNotFound, "Invite not found")
+	ErrNotPollOwner             = newError("NOT_POLL_OWNER", http.StatusForbidden, "Only the poll owner can do this")
+	ErrInvalidPollPassword      = newError("INVALID_PASSWORD_SETTING", http.StatusBadRequest, "Poll passwords must be between 4 and 72 bytes")
+	ErrPasswordRequired         = newError("PASSWORD_REQUIRED", http.StatusUnauthorized, "This poll is password protected")
+	ErrIncorrectPassword        = newError("INCORRECT_PASSWORD", http.StatusUnauthorized, "The poll password is incorrect")
+	ErrTooManyPasswordAttempts  = newError("TOO_MANY_ATTEMPTS", http.StatusTooManyRequests, "Too many failed password attempts; try again later")
+	ErrInvalidDedupPolicy       = newError("INVALID_DEDUP_POLICY", http.StatusBadRequest, "Duplicate vote policy must be fingerprint, ip, either, both, user or none")
+	ErrVoterNotAuthenticated    = newError("AUTHENTICATION_REQUIRED", http.StatusUnauthorized, "This poll only accepts votes from signed-in users")
+	ErrProofOfWorkRequired      = newError("CHALLENGE_REQUIRED", http.StatusForbidden, "Solve a challenge from /challenge before voting in this poll")
+	ErrInvalidProofOfWork       = newError("INVALID_CHALLENGE", http.StatusBadRequest, "The challenge solution is invalid, expired or already used")
+	ErrChallengeNotRequired     = newError("CHALLENGE_NOT_REQUIRED", http.StatusBadRequest, "This poll does not require a challenge")
+	ErrQuarantinedVoteNotFound  = newError("VOTE_NOT_FOUND", http.StatusNotFound, "No vote awaiting review was found")
+
+	// ErrInvalidRequest is a request that could not be parsed
+	ErrInvalidRequest = newError("INVALID_REQUEST", http.StatusBadRequest, "The request is malformed")
+	// ErrValidation is a well-formed request with invalid fields; use
+	// WithFields to say which
+	ErrValidation = newError("VALIDATION_FAILED", http.StatusBadRequest, "The request has invalid fields")
+	// ErrInternal stands in for any error that is not a domain error, so
+	// its details stay out of responses
+	ErrInternal = newError("INTERNAL_ERROR", http.StatusInternalServerError, "An internal error occurred")
 )
+
+// Error is a domain error. Code is stable for clients to match on, Status
+// is the HTTP status it maps to and Message is safe to show to clients.
+// Match it with errors.Is against the sentinels above, or errors.As to read
+// its fields.
+type Error struct {
+	Code    string
+	Status  int
+	Message string
+
+	// Fields lists the rejected request fields of a validation error
+	Fields []FieldError
+}
+
+// FieldError explains why one request field was rejected
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func newError(code string, status int, message string) *Error {
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+
+	fields := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		fields[i] = field.Field + ": " + field.Message
+	}
+	return e.Message + ": " + strings.Join(fields, "; ")
+}
+
+// Is matches errors with the same code, so copies made by WithFields still
+// match their sentinel
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithFields returns a copy of e listing the rejected fields
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	copied := *e
+	copied.Fields = fields
+	return &copied
+}
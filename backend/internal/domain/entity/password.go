@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPollPasswordLength = 4
+	// bcrypt ignores anything past 72 bytes
+	maxPollPasswordLength = 72
+)
+
+// SetPassword protects the poll with a bcrypt hash of password
+func (p *Poll) SetPassword(password string) error {
+	if len(password) < minPollPasswordLength || len(password) > maxPollPasswordLength {
+		return ErrInvalidPollPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	p.PasswordHash = string(hash)
+	return nil
+}
+
+// IsPasswordProtected reports whether a password is needed to view or vote
+func (p *Poll) IsPasswordProtected() bool {
+	return p.PasswordHash != ""
+}
+
+// CheckPassword reports whether password matches the poll's password
+func (p *Poll) CheckPassword(password string) bool {
+	if !p.IsPasswordProtected() {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(p.PasswordHash), []byte(password)) == nil
+}
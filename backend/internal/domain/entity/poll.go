@@ -14,8 +14,52 @@ type Poll struct {
 	ExpiresAt *time.Time
 	IsActive  bool
 	UpdatedAt time.Time
+
+	ArchivedAt *time.Time
+	DeletedAt  *time.Time
+
+	// OwnerTokenHash identifies the creator; OwnerToken is only populated on
+	// the poll returned from creation
+	OwnerTokenHash string
+	OwnerToken     string
+
+	// PasswordHash is the bcrypt hash of the poll's password, if any
+	PasswordHash string
+
+	// ResultsHidden is set when vote counts have been redacted for the caller
+	ResultsHidden bool
+
+	PollSettings
+}
+
+// PollSettings holds the optional behaviour chosen when a poll is created
+type PollSettings struct {
+	StartsAt          *time.Time
+	ResultsVisibility ResultsVisibility
+	Visibility        PollVisibility
+	DedupPolicy       DedupPolicy
+
+	// ProofOfWork requires voters to solve a challenge before each vote
+	ProofOfWork bool
+
+	// Allowlist is stored separately from the poll and is only populated
+	// when the poll is created
+	Allowlist []AllowlistEntry
+
+	// Password is hashed into Poll.PasswordHash by ApplySettings and never kept
+	Password string
 }
 
+// PollStatus is the lifecycle state of a poll at a point in time
+type PollStatus string
+
+const (
+	PollStatusScheduled PollStatus = "scheduled"
+	PollStatusActive    PollStatus = "active"
+	PollStatusExpired   PollStatus = "expired"
+	PollStatusClosed    PollStatus = "closed"
+)
+
 type Option struct {
 	ID         uuid.UUID
 	PollID     uuid.UUID
@@ -31,18 +75,31 @@ type Vote struct {
 	OptionID        uuid.UUID
 	IPHash          string
 	FingerprintHash string
+	UserID          string
+	SubnetHash      string
+	UserAgentHash   string
+	HashKeyID       string
 	CreatedAt       time.Time
+
+	// DedupPolicy is copied from the poll so the database can enforce it
+	DedupPolicy DedupPolicy
 }
 
 type PollStats struct {
-	TotalVotes int
-	Options    []OptionStats
+	TotalVotes     int
+	Options        []OptionStats
+	Leader         *LeaderComparison
+	TooCloseToCall bool
+	ResultsHidden  bool
 }
 
 type OptionStats struct {
 	OptionID   uuid.UUID
 	VoteCount  int
 	Percentage float64
+	// Wilson score interval bounds, in percent
+	ConfidenceLow  float64
+	ConfidenceHigh float64
 }
 
 // NewPoll creates a new poll with the given options
@@ -72,15 +129,78 @@ func NewPoll(question string, options []string, expiresAt *time.Time) (*Poll, er
 		ExpiresAt: expiresAt,
 		IsActive:  true,
 		UpdatedAt: now,
+
+		PollSettings: PollSettings{
+			ResultsVisibility: ResultsPublic,
+			Visibility:        VisibilityPublic,
+			DedupPolicy:       DedupBoth,
+		},
 	}, nil
 }
 
+// ApplySettings validates and applies creation-time settings to the poll
+func (p *Poll) ApplySettings(settings PollSettings) error {
+	if settings.StartsAt != nil && p.ExpiresAt != nil && !settings.StartsAt.Before(*p.ExpiresAt) {
+		return ErrInvalidSchedule
+	}
+
+	visibility, err := ParseResultsVisibility(string(settings.ResultsVisibility))
+	if err != nil {
+		return err
+	}
+	settings.ResultsVisibility = visibility
+
+	pollVisibility, err := ParsePollVisibility(string(settings.Visibility))
+	if err != nil {
+		return err
+	}
+	settings.Visibility = pollVisibility
+
+	dedupPolicy, err := ParseDedupPolicy(string(settings.DedupPolicy))
+	if err != nil {
+		return err
+	}
+	settings.DedupPolicy = dedupPolicy
+
+	if settings.Password != "" {
+		if err := p.SetPassword(settings.Password); err != nil {
+			return err
+		}
+		settings.Password = ""
+	}
+
+	p.PollSettings = settings
+	return nil
+}
+
+// Status reports the lifecycle state of the poll at the given time
+func (p *Poll) Status(now time.Time) PollStatus {
+	switch {
+	case p.ExpiresAt != nil && !p.ExpiresAt.After(now):
+		return PollStatusExpired
+	case !p.IsActive || p.IsArchived():
+		return PollStatusClosed
+	case p.StartsAt != nil && p.StartsAt.After(now):
+		return PollStatusScheduled
+	default:
+		return PollStatusActive
+	}
+}
+
 // Vote records a vote for the given option
 func (p *Poll) Vote(optionID uuid.UUID, identifier VoteIdentifier) (*Vote, error) {
+	if p.IsArchived() {
+		return nil, ErrPollArchived
+	}
+
 	if !p.IsActive {
 		return nil, ErrPollInactive
 	}
 
+	if p.StartsAt != nil && p.StartsAt.After(time.Now()) {
+		return nil, ErrPollNotStarted
+	}
+
 	if p.ExpiresAt != nil && p.ExpiresAt.Before(time.Now()) {
 		return nil, ErrPollExpired
 	}
@@ -103,7 +223,12 @@ func (p *Poll) Vote(optionID uuid.UUID, identifier VoteIdentifier) (*Vote, error
 		OptionID:        optionID,
 		IPHash:          identifier.IPHash,
 		FingerprintHash: identifier.FingerprintHash,
+		UserID:          identifier.UserID,
+		SubnetHash:      identifier.SubnetHash,
+		UserAgentHash:   identifier.UserAgentHash,
+		HashKeyID:       identifier.KeyID,
 		CreatedAt:       time.Now(),
+		DedupPolicy:     p.DedupPolicy,
 	}
 
 	targetOption.VoteCount++
@@ -112,6 +237,16 @@ func (p *Poll) Vote(optionID uuid.UUID, identifier VoteIdentifier) (*Vote, error
 	return vote, nil
 }
 
+// IsArchived reports whether the poll has been archived and is read-only
+func (p *Poll) IsArchived() bool {
+	return p.ArchivedAt != nil
+}
+
+// IsDeleted reports whether the poll has been soft-deleted
+func (p *Poll) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
 func (p *Poll) updatePercentages() {
 	total := 0
 	for _, opt := range p.Options {
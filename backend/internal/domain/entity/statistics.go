@@ -0,0 +1,109 @@
+package entity
+
+import (
+	"math"
+
+	"github.com/google/uuid"
+)
+
+// ConfidenceLevel is the confidence level used for result intervals and the
+// leader significance test
+const ConfidenceLevel = 0.95
+
+// confidenceZ is the two-sided critical value for ConfidenceLevel
+const confidenceZ = 1.959963984540054
+
+// LeaderComparison tests whether the leading option is ahead of the
+// runner-up by more than sampling noise would explain
+type LeaderComparison struct {
+	LeaderID   uuid.UUID
+	RunnerUpID uuid.UUID
+	// Margin is the lead in percentage points
+	Margin      float64
+	ZScore      float64
+	PValue      float64
+	Significant bool
+}
+
+// NewPollStats derives totals, percentages, Wilson score intervals and the
+// leader comparison from raw per-option vote counts
+func NewPollStats(options []OptionStats) *PollStats {
+	stats := &PollStats{
+		Options: options,
+	}
+	if stats.Options == nil {
+		stats.Options = make([]OptionStats, 0)
+	}
+
+	for _, opt := range stats.Options {
+		stats.TotalVotes += opt.VoteCount
+	}
+
+	for i := range stats.Options {
+		opt := &stats.Options[i]
+		if stats.TotalVotes > 0 {
+			opt.Percentage = float64(opt.VoteCount) / float64(stats.TotalVotes) * 100
+		}
+		low, high := WilsonInterval(opt.VoteCount, stats.TotalVotes)
+		opt.ConfidenceLow, opt.ConfidenceHigh = low*100, high*100
+	}
+
+	stats.Leader = compareLeader(stats.Options, stats.TotalVotes)
+	stats.TooCloseToCall = stats.Leader == nil || !stats.Leader.Significant
+
+	return stats
+}
+
+// WilsonInterval returns the Wilson score interval for successes out of
+// total at ConfidenceLevel, as proportions between 0 and 1. With no
+// observations the interval spans the whole range.
+func WilsonInterval(successes, total int) (float64, float64) {
+	if total <= 0 {
+		return 0, 1
+	}
+
+	n := float64(total)
+	p := float64(successes) / n
+	z2 := confidenceZ * confidenceZ
+
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := confidenceZ / (1 + z2/n) * math.Sqrt(p*(1-p)/n+z2/(4*n*n))
+
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// compareLeader runs a two-sided test on the top two options. Conditional on
+// a vote going to one of the two, each is equally likely under the null
+// hypothesis, so z = (n1 - n2) / sqrt(n1 + n2).
+func compareLeader(options []OptionStats, total int) *LeaderComparison {
+	if len(options) < 2 || total == 0 {
+		return nil
+	}
+
+	first, second := 0, 1
+	if options[second].VoteCount > options[first].VoteCount {
+		first, second = second, first
+	}
+	for i := 2; i < len(options); i++ {
+		switch {
+		case options[i].VoteCount > options[first].VoteCount:
+			first, second = i, first
+		case options[i].VoteCount > options[second].VoteCount:
+			second = i
+		}
+	}
+
+	leader, runnerUp := options[first], options[second]
+	diff := float64(leader.VoteCount - runnerUp.VoteCount)
+	z := diff / math.Sqrt(float64(leader.VoteCount+runnerUp.VoteCount))
+	pValue := math.Erfc(math.Abs(z) / math.Sqrt2)
+
+	return &LeaderComparison{
+		LeaderID:    leader.OptionID,
+		RunnerUpID:  runnerUp.OptionID,
+		Margin:      leader.Percentage - runnerUp.Percentage,
+		ZScore:      z,
+		PValue:      pValue,
+		Significant: pValue < 1-ConfidenceLevel,
+	}
+}
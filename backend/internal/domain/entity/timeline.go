@@ -0,0 +1,158 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TimelineInterval is the width of a timeline bucket, named after the
+// Postgres date_trunc unit used to build it
+type TimelineInterval string
+
+const (
+	IntervalMinute TimelineInterval = "minute"
+	IntervalHour   TimelineInterval = "hour"
+	IntervalDay    TimelineInterval = "day"
+	IntervalWeek   TimelineInterval = "week"
+)
+
+// ParseTimelineInterval accepts a unit name or its short form (1m, 1h, 1d, 1w)
+func ParseTimelineInterval(s string) (TimelineInterval, error) {
+	switch s {
+	case "1m", string(IntervalMinute):
+		return IntervalMinute, nil
+	case "", "1h", string(IntervalHour):
+		return IntervalHour, nil
+	case "1d", string(IntervalDay):
+		return IntervalDay, nil
+	case "1w", string(IntervalWeek):
+		return IntervalWeek, nil
+	default:
+		return "", ErrInvalidBucket
+	}
+}
+
+// Duration is the width of one bucket
+func (i TimelineInterval) Duration() time.Duration {
+	switch i {
+	case IntervalMinute:
+		return time.Minute
+	case IntervalDay:
+		return 24 * time.Hour
+	case IntervalWeek:
+		return 7 * 24 * time.Hour
+	default:
+		return time.Hour
+	}
+}
+
+// TimelineCount is the number of votes an option received in one bucket
+type TimelineCount struct {
+	Bucket   time.Time
+	OptionID uuid.UUID
+	Votes    int
+}
+
+// TimelineBucket holds per-option vote counts for one bucket along with the
+// running totals up to and including it
+type TimelineBucket struct {
+	Start      time.Time
+	Votes      map[uuid.UUID]int
+	Cumulative map[uuid.UUID]int
+	Leader     *uuid.UUID
+}
+
+// LeadChange records when an option became the sole leader
+type LeadChange struct {
+	OptionID uuid.UUID
+	At       time.Time
+}
+
+// Timeline is the history of a poll's votes over time
+type Timeline struct {
+	PollID      uuid.UUID
+	Interval    TimelineInterval
+	Buckets     []TimelineBucket
+	LeadChanges []LeadChange
+}
+
+// BuildTimeline folds per-bucket counts, ordered by bucket, into a timeline
+// with cumulative series and lead changes. Buckets without votes between the
+// first and the last vote are filled in with zero counts.
+func BuildTimeline(poll *Poll, interval TimelineInterval, counts []TimelineCount) *Timeline {
+	timeline := &Timeline{
+		PollID:      poll.ID,
+		Interval:    interval,
+		Buckets:     make([]TimelineBucket, 0),
+		LeadChanges: make([]LeadChange, 0),
+	}
+
+	running := make(map[uuid.UUID]int, len(poll.Options))
+	for _, opt := range poll.Options {
+		running[opt.ID] = 0
+	}
+
+	if len(counts) == 0 {
+		return timeline
+	}
+
+	var leader *uuid.UUID
+	last := counts[len(counts)-1].Bucket
+	for i, start := 0, counts[0].Bucket; !start.After(last); start = start.Add(interval.Duration()) {
+		bucket := TimelineBucket{
+			Start:      start,
+			Votes:      make(map[uuid.UUID]int, len(poll.Options)),
+			Cumulative: make(map[uuid.UUID]int, len(poll.Options)),
+		}
+		for _, opt := range poll.Options {
+			bucket.Votes[opt.ID] = 0
+		}
+
+		for ; i < len(counts) && !counts[i].Bucket.After(start); i++ {
+			bucket.Votes[counts[i].OptionID] += counts[i].Votes
+			running[counts[i].OptionID] += counts[i].Votes
+		}
+		for id, total := range running {
+			bucket.Cumulative[id] = total
+		}
+
+		// The lead only changes hands when another option is strictly ahead;
+		// during a tie the previous leader keeps it
+		if next := strictLeader(poll.Options, running); next != nil && (leader == nil || *next != *leader) {
+			leader = next
+			timeline.LeadChanges = append(timeline.LeadChanges, LeadChange{
+				OptionID: *leader,
+				At:       bucket.Start,
+			})
+		}
+		if leader != nil {
+			id := *leader
+			bucket.Leader = &id
+		}
+
+		timeline.Buckets = append(timeline.Buckets, bucket)
+	}
+
+	return timeline
+}
+
+// strictLeader returns the option with the most votes, or nil on a tie
+func strictLeader(options []Option, totals map[uuid.UUID]int) *uuid.UUID {
+	var best *uuid.UUID
+	bestVotes, tied := -1, false
+	for i := range options {
+		votes := totals[options[i].ID]
+		switch {
+		case votes > bestVotes:
+			best, bestVotes, tied = &options[i].ID, votes, false
+		case votes == bestVotes:
+			tied = true
+		}
+	}
+	if tied || bestVotes <= 0 {
+		return nil
+	}
+	id := *best
+	return &id
+}
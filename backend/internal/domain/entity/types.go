@@ -4,6 +4,19 @@ package entity
 type VoteIdentifier struct {
 	IPHash          string
 	FingerprintHash string
+
+	// UserID is the authenticated voter, if any
+	UserID string
+
+	// SubnetHash and UserAgentHash feed anomaly detection only
+	SubnetHash    string
+	UserAgentHash string
+
+	// KeyID names the server key the hashes were computed with
+	KeyID string
+	// Rotated holds the same voter hashed under keys that are being
+	// rotated out, so votes cast before a rotation still count as duplicates
+	Rotated []VoteIdentifier
 }
 
 // Factory method for VoteIdentifier
@@ -13,14 +26,3 @@ func NewVoteIdentifier(ipHash, fingerprintHash string) VoteIdentifier {
 		FingerprintHash: fingerprintHash,
 	}
 }
-
-// Validate checks if the identifier is valid
-func (v VoteIdentifier) Validate() error {
-	if v.IPHash == "" {
-		return ErrInvalidVoteIdentifier
-	}
-	if v.FingerprintHash == "" {
-		return ErrInvalidVoteIdentifier
-	}
-	return nil
-}
@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// ResultsVisibility controls who can see a poll's vote counts
+type ResultsVisibility string
+
+const (
+	ResultsPublic      ResultsVisibility = "public"
+	ResultsAfterVote   ResultsVisibility = "after_vote"
+	ResultsAfterClose  ResultsVisibility = "after_close"
+	ResultsCreatorOnly ResultsVisibility = "creator_only"
+)
+
+// ParseResultsVisibility validates a visibility name, defaulting to public
+func ParseResultsVisibility(s string) (ResultsVisibility, error) {
+	switch v := ResultsVisibility(s); v {
+	case "":
+		return ResultsPublic, nil
+	case ResultsPublic, ResultsAfterVote, ResultsAfterClose, ResultsCreatorOnly:
+		return v, nil
+	default:
+		return "", ErrInvalidResultsVisibility
+	}
+}
+
+// ResultsVisibleTo reports whether the actor may see vote counts. hasVoted
+// is only consulted for ResultsAfterVote. Admins and the poll's creator can
+// always see results.
+func (p *Poll) ResultsVisibleTo(actor Actor, hasVoted bool, now time.Time) bool {
+	if p.CanManage(actor) {
+		return true
+	}
+
+	switch p.ResultsVisibility {
+	case ResultsAfterVote:
+		return hasVoted
+	case ResultsAfterClose:
+		status := p.Status(now)
+		return status == PollStatusExpired || status == PollStatusClosed
+	case ResultsCreatorOnly:
+		return false
+	default:
+		return true
+	}
+}
+
+// RedactResults clears vote counts and marks the results as hidden
+func (p *Poll) RedactResults() {
+	options := make([]Option, len(p.Options))
+	for i, opt := range p.Options {
+		opt.VoteCount = 0
+		opt.Percentage = 0
+		options[i] = opt
+	}
+	p.Options = options
+	p.ResultsHidden = true
+}
+
+// Redact clears all counts and derived statistics
+func (s *PollStats) Redact() {
+	options := make([]OptionStats, len(s.Options))
+	for i, opt := range s.Options {
+		options[i] = OptionStats{OptionID: opt.OptionID}
+	}
+	s.Options = options
+	s.TotalVotes = 0
+	s.Leader = nil
+	s.TooCloseToCall = false
+	s.ResultsHidden = true
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+)
+
+// PollSort is the key a poll listing is ordered by
+type PollSort string
+
+const (
+	SortCreated PollSort = "created"
+	SortVotes   PollSort = "votes"
+	SortExpiry  PollSort = "expiry"
+)
+
+// PollFilter narrows and orders a poll listing. Zero values mean "no
+// constraint"; the default order is newest first.
+type PollFilter struct {
+	Status        entity.PollStatus
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	ExpiresAfter  *time.Time
+	ExpiresBefore *time.Time
+	Search        string
+	Sort          PollSort
+	Ascending     bool
+
+	IncludeDeleted bool
+	// IncludeUnlisted also lists unlisted, private and password-protected polls
+	IncludeUnlisted bool
+}
+
+// Validate checks that the filter only uses supported values, naming each
+// rejected field after its query parameter. It is the one place filters are
+// checked; PollService.ListPolls calls it before anything is queried.
+func (f PollFilter) Validate() error {
+	var fields []entity.FieldError
+
+	switch f.Status {
+	case "", entity.PollStatusActive, entity.PollStatusExpired, entity.PollStatusScheduled:
+	default:
+		fields = append(fields, entity.FieldError{Field: "status", Message: "Must be one of active expired scheduled"})
+	}
+
+	switch f.Sort {
+	case "", SortCreated, SortVotes, SortExpiry:
+	default:
+		fields = append(fields, entity.FieldError{Field: "sort", Message: "Must be one of created votes expiry"})
+	}
+
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && f.CreatedAfter.After(*f.CreatedBefore) {
+		fields = append(fields, entity.FieldError{Field: "created_after", Message: "Must not be after created_before"})
+	}
+	if f.ExpiresAfter != nil && f.ExpiresBefore != nil && f.ExpiresAfter.After(*f.ExpiresBefore) {
+		fields = append(fields, entity.FieldError{Field: "expires_after", Message: "Must not be after expires_before"})
+	}
+
+	if len(fields) > 0 {
+		return entity.ErrValidation.WithFields(fields...)
+	}
+	return nil
+}
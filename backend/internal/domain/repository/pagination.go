@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// Cursor marks a position in a (sort key, id) ordering of polls. Time holds
+// the key for created and expiry sorts, Votes the key for the votes sort.
+// Backward cursors page towards the start of the listing.
+type Cursor struct {
+	Sort      PollSort  `json:"s,omitempty"`
+	Ascending bool      `json:"a,omitempty"`
+	Time      time.Time `json:"t,omitempty"`
+	Votes     int       `json:"v,omitempty"`
+	ID        uuid.UUID `json:"i"`
+	Backward  bool      `json:"b,omitempty"`
+}
+
+// PageRequest selects a page of results either by keyset cursor or, for
+// older clients, by page number
+type PageRequest struct {
+	Limit        int
+	Cursor       *Cursor
+	Page         int
+	IncludeTotal bool
+}
+
+// PollPage is a page of polls along with cursors to its neighbours
+type PollPage struct {
+	Polls      []*entity.Poll
+	NextCursor *Cursor
+	PrevCursor *Cursor
+	Total      *int
+}
+
+// Encode returns the opaque string form handed to API clients
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Matches reports whether the cursor was issued for the filter's ordering
+func (c Cursor) Matches(filter PollFilter) bool {
+	return normalizeSort(c.Sort) == normalizeSort(filter.Sort) && c.Ascending == filter.Ascending
+}
+
+// DecodeCursor parses a cursor previously produced by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, entity.ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, entity.ErrInvalidCursor
+	}
+	if cursor.ID == uuid.Nil {
+		return nil, entity.ErrInvalidCursor
+	}
+	if normalizeSort(cursor.Sort) != SortVotes && cursor.Time.IsZero() {
+		return nil, entity.ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+func normalizeSort(sort PollSort) PollSort {
+	if sort == "" {
+		return SortCreated
+	}
+	return sort
+}
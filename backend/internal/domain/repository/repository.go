@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
 	"github.com/google/uuid"
@@ -10,15 +11,73 @@ import (
 type PollRepository interface {
 	Create(ctx context.Context, poll *entity.Poll) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Poll, error)
+	GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*entity.Poll, error)
+	// GetByIDs returns the polls that exist among ids, in the same order
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Poll, error)
 	Update(ctx context.Context, poll *entity.Poll) error
 	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context, page, limit int) ([]*entity.Poll, error)
+	SoftDelete(ctx context.Context, id uuid.UUID) error
+	Archive(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+	List(ctx context.Context, filter PollFilter, page PageRequest) (*PollPage, error)
 }
 
 type VoteRepository interface {
 	Create(ctx context.Context, vote *entity.Vote) error
-	HasVoted(ctx context.Context, pollID uuid.UUID, identifier entity.VoteIdentifier) (bool, error)
+	// HasVoted reports whether a vote matching the identifier under the
+	// given policy exists; it is always false for DedupNone
+	HasVoted(ctx context.Context, pollID uuid.UUID, policy entity.DedupPolicy, identifier entity.VoteIdentifier) (bool, error)
 	GetPollStats(ctx context.Context, pollID uuid.UUID) (*entity.PollStats, error)
+	CountSince(ctx context.Context, pollID uuid.UUID, since time.Time) (int, error)
+	// ExpireLegacyIdentifiers replaces the unkeyed IP and fingerprint hashes
+	// of votes cast before castBefore, returning how many were replaced
+	ExpireLegacyIdentifiers(ctx context.Context, castBefore time.Time) (int64, error)
+}
+
+// QuarantineRepository backs anomaly detection and the review of votes it
+// holds back from results
+type QuarantineRepository interface {
+	// CountSignal measures the signal for the vote's poll since the given time
+	CountSignal(ctx context.Context, vote *entity.Vote, signal entity.AnomalySignal, since time.Time) (int, error)
+	Quarantine(ctx context.Context, voteID uuid.UUID, reasons []entity.AnomalySignal, score int) error
+	ListQuarantined(ctx context.Context, pollID uuid.UUID) ([]entity.QuarantinedVote, error)
+	// Review settles a pending quarantined vote, or returns
+	// ErrQuarantinedVoteNotFound
+	Review(ctx context.Context, pollID, voteID uuid.UUID, status entity.QuarantineStatus) error
+}
+
+// AuditRepository stores the change history of polls
+type AuditRepository interface {
+	Record(ctx context.Context, event *entity.AuditEvent) error
+	// ListByPoll returns a poll's audit events, newest first
+	ListByPoll(ctx context.Context, pollID uuid.UUID) ([]entity.AuditEvent, error)
+}
+
+// AccessRepository stores the invites and allowlists that admit voters to
+// private polls
+type AccessRepository interface {
+	CreateInvite(ctx context.Context, invite *entity.Invite) error
+	RevokeInvite(ctx context.Context, pollID, inviteID uuid.UUID) error
+	// RedeemInvite atomically uses up one redemption of a live invite, or
+	// fails with entity.ErrInvalidInvite
+	RedeemInvite(ctx context.Context, pollID uuid.UUID, codeHash string) error
+	IsAllowlisted(ctx context.Context, pollID uuid.UUID, userID, email string) (bool, error)
+}
+
+// ChallengeRepository remembers solved proof-of-work challenges until they
+// expire, so each is accepted once however many instances verify it
+type ChallengeRepository interface {
+	// MarkUsed records the challenge, reporting false if it was already used
+	MarkUsed(ctx context.Context, tokenHash string, expiresAt time.Time) (bool, error)
+	// PurgeExpired forgets challenges that expired by now
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type AnalyticsRepository interface {
+	TrendingScores(ctx context.Context, since time.Time, halfLife time.Duration, limit int) ([]entity.TrendingScore, error)
+	VoteTimeline(ctx context.Context, pollID uuid.UUID, interval entity.TimelineInterval) ([]entity.TimelineCount, error)
+	CrossTabCounts(ctx context.Context, rowPollID, columnPollID uuid.UUID) ([]entity.CrossTabCount, error)
 }
 
 type TransactionManager interface {
@@ -26,6 +85,9 @@ type TransactionManager interface {
 }
 
 type Transaction interface {
+	// Context returns a context carrying the transaction; repository calls
+	// made with it run inside the transaction
+	Context() context.Context
 	Commit() error
 	Rollback() error
 }
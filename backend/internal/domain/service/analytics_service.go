@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+type AnalyticsService interface {
+	TrendingPolls(ctx context.Context, limit int) (*entity.TrendingPolls, error)
+	PollTimeline(ctx context.Context, pollID uuid.UUID, interval entity.TimelineInterval) (*entity.Timeline, error)
+	CrossTab(ctx context.Context, rowPollID, columnPollID uuid.UUID) (*entity.CrossTab, error)
+}
+
+// TrendingOptions tunes how trending polls are ranked and cached
+type TrendingOptions struct {
+	Window     time.Duration
+	HalfLife   time.Duration
+	CacheTTL   time.Duration
+	MaxResults int
+}
+
+// CrossTabOptions controls disclosure in cross-tabulations
+type CrossTabOptions struct {
+	// MinCellSize is the smallest group of voters reported in a cell
+	MinCellSize int
+}
+
+type analyticsService struct {
+	pollRepo      repository.PollRepository
+	voteRepo      repository.VoteRepository
+	analyticsRepo repository.AnalyticsRepository
+	trending      TrendingOptions
+	crossTab      CrossTabOptions
+	gate          *PasswordGate
+
+	mu            sync.Mutex
+	trendingCache *entity.TrendingPolls
+	cachedUntil   time.Time
+	refresh       *trendingRefresh
+}
+
+// trendingRefresh is a recompute of the trending ranking in progress.
+// Callers that find the cache stale while it runs wait for it instead of
+// starting their own.
+type trendingRefresh struct {
+	done   chan struct{}
+	result *entity.TrendingPolls
+	err    error
+}
+
+// trendingRefreshTimeout bounds a recompute, which no longer ends with the
+// request that started it
+const trendingRefreshTimeout = 30 * time.Second
+
+func NewAnalyticsService(
+	pollRepo repository.PollRepository,
+	voteRepo repository.VoteRepository,
+	analyticsRepo repository.AnalyticsRepository,
+	trending TrendingOptions,
+	crossTab CrossTabOptions,
+	gate *PasswordGate,
+) AnalyticsService {
+	return &analyticsService{
+		pollRepo:      pollRepo,
+		voteRepo:      voteRepo,
+		analyticsRepo: analyticsRepo,
+		trending:      trending,
+		crossTab:      crossTab,
+		gate:          gate,
+	}
+}
+
+func (s *analyticsService) TrendingPolls(ctx context.Context, limit int) (*entity.TrendingPolls, error) {
+	if limit < 1 || limit > s.trending.MaxResults {
+		limit = s.trending.MaxResults
+	}
+
+	ranking, err := s.trendingRanking(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := *ranking
+	if len(result.Polls) > limit {
+		result.Polls = result.Polls[:limit]
+	}
+
+	// The cache is shared between callers, so redact copies of the polls
+	polls := make([]entity.TrendingPoll, len(result.Polls))
+	for i, tp := range result.Polls {
+		poll := *tp.Poll
+		if err := redactPoll(ctx, nil, &poll); err != nil {
+			return nil, err
+		}
+		tp.Poll = &poll
+		polls[i] = tp
+	}
+	result.Polls = polls
+
+	return &result, nil
+}
+
+// trendingRanking returns the full ranking. It is cached briefly so the
+// landing page does not aggregate votes on every request, and recomputed
+// outside the lock so cache hits never wait on the database.
+func (s *analyticsService) trendingRanking(ctx context.Context) (*entity.TrendingPolls, error) {
+	s.mu.Lock()
+	if s.trendingCache != nil && time.Now().Before(s.cachedUntil) {
+		cached := s.trendingCache
+		s.mu.Unlock()
+		return cached, nil
+	}
+	refresh := s.refresh
+	if refresh == nil {
+		refresh = &trendingRefresh{done: make(chan struct{})}
+		s.refresh = refresh
+		// The recompute is shared, so it must not fail because the request
+		// that started it went away
+		go s.refreshTrending(context.WithoutCancel(ctx), refresh)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-refresh.done:
+		return refresh.result, refresh.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *analyticsService) refreshTrending(ctx context.Context, refresh *trendingRefresh) {
+	ctx, cancel := context.WithTimeout(ctx, trendingRefreshTimeout)
+	defer cancel()
+
+	refresh.result, refresh.err = s.computeTrending(ctx)
+
+	s.mu.Lock()
+	if refresh.err == nil {
+		s.trendingCache = refresh.result
+		s.cachedUntil = time.Now().Add(s.trending.CacheTTL)
+	}
+	s.refresh = nil
+	s.mu.Unlock()
+	close(refresh.done)
+}
+
+func (s *analyticsService) computeTrending(ctx context.Context) (*entity.TrendingPolls, error) {
+	now := time.Now()
+
+	scores, err := s.analyticsRepo.TrendingScores(ctx, now.Add(-s.trending.Window), s.trending.HalfLife, s.trending.MaxResults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending scores: %w", err)
+	}
+
+	trending := &entity.TrendingPolls{
+		Polls:       make([]entity.TrendingPoll, 0, len(scores)),
+		Window:      s.trending.Window,
+		GeneratedAt: now,
+	}
+
+	ids := make([]uuid.UUID, len(scores))
+	for i, score := range scores {
+		ids[i] = score.PollID
+	}
+	polls, err := s.pollRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending polls: %w", err)
+	}
+	byID := make(map[uuid.UUID]*entity.Poll, len(polls))
+	for _, poll := range polls {
+		byID[poll.ID] = poll
+	}
+
+	for _, score := range scores {
+		poll, ok := byID[score.PollID]
+		if !ok {
+			// The poll may have been deleted since the votes were counted
+			continue
+		}
+		// The ranking is shared by all callers, so it only includes polls
+		// whose results anyone may see
+		if !poll.ResultsVisibleTo(entity.Actor{}, false, now) {
+			continue
+		}
+
+		var votesPerHour float64
+		if hours := s.trending.Window.Hours(); hours > 0 {
+			votesPerHour = float64(score.RecentVotes) / hours
+		}
+		trending.Polls = append(trending.Polls, entity.TrendingPoll{
+			Poll:         poll,
+			RecentVotes:  score.RecentVotes,
+			VotesPerHour: votesPerHour,
+			Score:        score.Score,
+		})
+	}
+
+	return trending, nil
+}
+
+func (s *analyticsService) PollTimeline(ctx context.Context, pollID uuid.UUID, interval entity.TimelineInterval) (*entity.Timeline, error) {
+	poll, err := s.pollRepo.GetByID(ctx, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+	if err := s.requireResults(ctx, poll); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.analyticsRepo.VoteTimeline(ctx, pollID, interval)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vote timeline: %w", err)
+	}
+
+	return entity.BuildTimeline(poll, interval, counts), nil
+}
+
+func (s *analyticsService) CrossTab(ctx context.Context, rowPollID, columnPollID uuid.UUID) (*entity.CrossTab, error) {
+	if rowPollID == columnPollID {
+		return nil, entity.ErrInvalidCrossTab
+	}
+
+	rowPoll, err := s.pollRepo.GetByID(ctx, rowPollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+	columnPoll, err := s.pollRepo.GetByID(ctx, columnPollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+	for _, poll := range []*entity.Poll{rowPoll, columnPoll} {
+		if err := s.requireResults(ctx, poll); err != nil {
+			return nil, err
+		}
+	}
+
+	counts, err := s.analyticsRepo.CrossTabCounts(ctx, rowPollID, columnPollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cross-tab counts: %w", err)
+	}
+
+	return entity.BuildCrossTab(rowPoll, columnPoll, counts, s.crossTab.MinCellSize), nil
+}
+
+// requireResults fails if the actor on ctx may not access the poll or see
+// its vote counts
+func (s *analyticsService) requireResults(ctx context.Context, poll *entity.Poll) error {
+	if err := s.gate.Authorize(ctx, poll); err != nil {
+		return err
+	}
+
+	visible, err := resultsVisible(ctx, s.voteRepo, poll)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		return entity.ErrResultsHidden
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+// AnomalyService scores recorded votes against anomaly rules and lets admins
+// review the votes it quarantines
+type AnomalyService interface {
+	// Inspect scores the vote and quarantines it if the score reaches the
+	// threshold, reporting whether it did
+	Inspect(ctx context.Context, vote *entity.Vote) (bool, error)
+	ListQuarantined(ctx context.Context, pollID uuid.UUID) ([]entity.QuarantinedVote, error)
+	ReviewVote(ctx context.Context, pollID, voteID uuid.UUID, approve bool) error
+}
+
+// AnomalyOptions configures which rules are checked and how many points a
+// vote must score to be quarantined
+type AnomalyOptions struct {
+	Rules           []entity.AnomalyRule
+	QuarantineScore int
+}
+
+type anomalyService struct {
+	repo repository.QuarantineRepository
+	opts AnomalyOptions
+}
+
+func NewAnomalyService(repo repository.QuarantineRepository, opts AnomalyOptions) AnomalyService {
+	if opts.QuarantineScore < 1 {
+		opts.QuarantineScore = 1
+	}
+	return &anomalyService{
+		repo: repo,
+		opts: opts,
+	}
+}
+
+func (s *anomalyService) Inspect(ctx context.Context, vote *entity.Vote) (bool, error) {
+	var reasons []entity.AnomalySignal
+	score := 0
+
+	for _, rule := range s.opts.Rules {
+		if rule.Threshold < 1 || !hasSignal(vote, rule.Signal) {
+			continue
+		}
+
+		count, err := s.repo.CountSignal(ctx, vote, rule.Signal, vote.CreatedAt.Add(-rule.Window))
+		if err != nil {
+			return false, fmt.Errorf("failed to count %s: %w", rule.Signal, err)
+		}
+		if count > rule.Threshold {
+			reasons = append(reasons, rule.Signal)
+			score += max(rule.Weight, 1)
+		}
+	}
+
+	if score < s.opts.QuarantineScore {
+		return false, nil
+	}
+
+	if err := s.repo.Quarantine(ctx, vote.ID, reasons, score); err != nil {
+		return false, fmt.Errorf("failed to quarantine vote: %w", err)
+	}
+	return true, nil
+}
+
+// hasSignal skips rules the vote carries no data for, such as a missing
+// user agent, so they cannot match every other vote without one
+func hasSignal(vote *entity.Vote, signal entity.AnomalySignal) bool {
+	switch signal {
+	case entity.SignalSubnetBurst:
+		return vote.SubnetHash != ""
+	case entity.SignalFingerprintsPerIP:
+		return vote.IPHash != ""
+	case entity.SignalUserAgentBurst:
+		return vote.UserAgentHash != ""
+	default:
+		return false
+	}
+}
+
+func (s *anomalyService) ListQuarantined(ctx context.Context, pollID uuid.UUID) ([]entity.QuarantinedVote, error) {
+	votes, err := s.repo.ListQuarantined(ctx, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quarantined votes: %w", err)
+	}
+	return votes, nil
+}
+
+func (s *anomalyService) ReviewVote(ctx context.Context, pollID, voteID uuid.UUID, approve bool) error {
+	status := entity.QuarantineRejected
+	if approve {
+		status = entity.QuarantineApproved
+	}
+
+	if err := s.repo.Review(ctx, pollID, voteID, status); err != nil {
+		return fmt.Errorf("failed to review vote: %w", err)
+	}
+	return nil
+}
+
+// HandleVoteRecorded inspects votes published on the event bus. Failures are
+// passed to onError since there is no caller to return them to.
+func HandleVoteRecorded(s AnomalyService, onError func(error)) func(event interface{}) {
+	return func(event interface{}) {
+		recorded, ok := event.(VoteRecordedEvent)
+		if !ok || recorded.Vote == nil {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if _, err := s.Inspect(ctx, recorded.Vote); err != nil {
+			onError(err)
+		}
+	}
+}
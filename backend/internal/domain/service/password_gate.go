@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// PollAccessTokens issues and verifies short-lived tokens that stand in for
+// a poll's password
+type PollAccessTokens interface {
+	Issue(pollID uuid.UUID) (string, time.Time, error)
+	Verify(token string, pollID uuid.UUID) bool
+}
+
+// AttemptLimiter throttles password attempts. Allow takes one attempt and
+// reports whether any were left; Refund gives it back.
+type AttemptLimiter interface {
+	Allow(key string) bool
+	Refund(key string)
+}
+
+// PasswordGate guards password-protected polls. Callers get in with the
+// password itself or with an access token issued after it was verified.
+type PasswordGate struct {
+	tokens PollAccessTokens
+	// perPoll caps failed attempts on a poll however many clients make
+	// them; perClient caps each client so one guesser does not use up the
+	// poll's whole allowance
+	perPoll   AttemptLimiter
+	perClient AttemptLimiter
+}
+
+func NewPasswordGate(tokens PollAccessTokens, perPoll, perClient AttemptLimiter) *PasswordGate {
+	return &PasswordGate{
+		tokens:    tokens,
+		perPoll:   perPoll,
+		perClient: perClient,
+	}
+}
+
+// Authorize checks that the actor on ctx may access the poll
+func (g *PasswordGate) Authorize(ctx context.Context, poll *entity.Poll) error {
+	if !poll.IsPasswordProtected() {
+		return nil
+	}
+
+	actor := entity.ActorFromContext(ctx)
+	if poll.CanManage(actor) {
+		return nil
+	}
+	if actor.AccessToken != "" && g.tokens != nil && g.tokens.Verify(actor.AccessToken, poll.ID) {
+		return nil
+	}
+	if actor.Password == "" {
+		return entity.ErrPasswordRequired
+	}
+
+	return g.checkPassword(ctx, poll, actor.Password)
+}
+
+// Unlock verifies the password and issues an access token for the poll
+func (g *PasswordGate) Unlock(ctx context.Context, poll *entity.Poll, password string) (string, time.Time, error) {
+	if poll.IsPasswordProtected() {
+		if err := g.checkPassword(ctx, poll, password); err != nil {
+			return "", time.Time{}, err
+		}
+	}
+	return g.tokens.Issue(poll.ID)
+}
+
+// checkPassword compares the password, limiting attempts per client and per
+// poll so neither one client nor many rotating addresses can keep guessing.
+// Attempts are taken before the comparison so concurrent guesses cannot all
+// get through, and given back on success so only failures count.
+func (g *PasswordGate) checkPassword(ctx context.Context, poll *entity.Poll, password string) error {
+	pollKey := poll.ID.String()
+	clientKey := pollKey + ":" + entity.ActorFromContext(ctx).Identifier.IPHash
+
+	if !allow(g.perClient, clientKey) {
+		return entity.ErrTooManyPasswordAttempts
+	}
+	if !allow(g.perPoll, pollKey) {
+		// The client's attempt was never made
+		refund(g.perClient, clientKey)
+		return entity.ErrTooManyPasswordAttempts
+	}
+
+	if !poll.CheckPassword(password) {
+		return entity.ErrIncorrectPassword
+	}
+
+	refund(g.perClient, clientKey)
+	refund(g.perPoll, pollKey)
+	return nil
+}
+
+// allow takes an attempt from limiter, which may be nil for no limit
+func allow(limiter AttemptLimiter, key string) bool {
+	return limiter == nil || limiter.Allow(key)
+}
+
+func refund(limiter AttemptLimiter, key string) {
+	if limiter != nil {
+		limiter.Refund(key)
+	}
+}
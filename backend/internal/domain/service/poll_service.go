@@ -11,52 +11,87 @@ import (
 )
 
 type PollService interface {
-	CreatePoll(ctx context.Context, question string, options []string, expiresAt *time.Time) (*entity.Poll, error)
-	GetPoll(ctx context.Context, id uuid.UUID) (*entity.Poll, error)
+	CreatePoll(ctx context.Context, question string, options []string, expiresAt *time.Time, settings entity.PollSettings) (*entity.Poll, error)
+	GetPoll(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Poll, error)
 	Vote(ctx context.Context, pollID, optionID uuid.UUID, identifier entity.VoteIdentifier) error
-	ListPolls(ctx context.Context, page, limit int) ([]*entity.Poll, error)
+	ListPolls(ctx context.Context, filter repository.PollFilter, page repository.PageRequest) (*repository.PollPage, error)
 	DeletePoll(ctx context.Context, id uuid.UUID) error
+	ArchivePoll(ctx context.Context, id uuid.UUID) error
+	RestorePoll(ctx context.Context, id uuid.UUID) error
+	PurgeDeletedPolls(ctx context.Context, retention time.Duration) (int64, error)
+	ExpireLegacyVoterHashes(ctx context.Context, retention time.Duration) (int64, error)
 	UpdatePoll(ctx context.Context, id uuid.UUID, question string, isActive bool, expiresAt *time.Time) error
 	GetPollStats(ctx context.Context, id uuid.UUID) (*entity.PollStats, error)
+	CreateInvite(ctx context.Context, pollID uuid.UUID, maxUses int, expiresAt *time.Time) (*entity.Invite, error)
+	RevokeInvite(ctx context.Context, pollID, inviteID uuid.UUID) error
+	UnlockPoll(ctx context.Context, pollID uuid.UUID, password string) (string, time.Time, error)
+	RecentVotes(ctx context.Context, pollID uuid.UUID, since time.Time) (int, error)
+	AuditLog(ctx context.Context, pollID uuid.UUID) ([]entity.AuditEvent, error)
 }
 
 type pollService struct {
-	pollRepo  repository.PollRepository
-	voteRepo  repository.VoteRepository
-	txManager repository.TransactionManager
-	eventBus  EventBus
+	pollRepo   repository.PollRepository
+	voteRepo   repository.VoteRepository
+	accessRepo repository.AccessRepository
+	auditRepo  repository.AuditRepository
+	txManager  repository.TransactionManager
+	eventBus   EventBus
+	gate       *PasswordGate
 }
 
 func NewPollService(
 	pollRepo repository.PollRepository,
 	voteRepo repository.VoteRepository,
+	accessRepo repository.AccessRepository,
+	auditRepo repository.AuditRepository,
 	txManager repository.TransactionManager,
 	eventBus EventBus,
+	gate *PasswordGate,
 ) PollService {
 	return &pollService{
-		pollRepo:  pollRepo,
-		voteRepo:  voteRepo,
-		txManager: txManager,
-		eventBus:  eventBus,
+		pollRepo:   pollRepo,
+		voteRepo:   voteRepo,
+		accessRepo: accessRepo,
+		auditRepo:  auditRepo,
+		txManager:  txManager,
+		eventBus:   eventBus,
+		gate:       gate,
 	}
 }
 
-func (s *pollService) CreatePoll(ctx context.Context, question string, options []string, expiresAt *time.Time) (*entity.Poll, error) {
+func (s *pollService) CreatePoll(ctx context.Context, question string, options []string, expiresAt *time.Time, settings entity.PollSettings) (*entity.Poll, error) {
 	poll, err := entity.NewPoll(question, options, expiresAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create poll: %w", err)
 	}
 
+	if err := poll.ApplySettings(settings); err != nil {
+		return nil, fmt.Errorf("failed to create poll: %w", err)
+	}
+
+	// The plaintext token is handed back once; only its hash is stored
+	ownerToken, err := entity.NewOwnerToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create poll: %w", err)
+	}
+	poll.OwnerToken = ownerToken
+	poll.OwnerTokenHash = entity.HashOwnerToken(ownerToken)
+
 	tx, err := s.txManager.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
+	ctx = tx.Context()
 
 	if err := s.pollRepo.Create(ctx, poll); err != nil {
 		return nil, fmt.Errorf("failed to save poll: %w", err)
 	}
 
+	if err := s.audit(ctx, entity.AuditPollCreated, nil, poll); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -65,37 +100,66 @@ func (s *pollService) CreatePoll(ctx context.Context, question string, options [
 	return poll, nil
 }
 
-func (s *pollService) GetPoll(ctx context.Context, id uuid.UUID) (*entity.Poll, error) {
-	poll, err := s.pollRepo.GetByID(ctx, id)
+func (s *pollService) GetPoll(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Poll, error) {
+	var poll *entity.Poll
+	var err error
+	if includeDeleted {
+		poll, err = s.pollRepo.GetByIDIncludingDeleted(ctx, id)
+	} else {
+		poll, err = s.pollRepo.GetByID(ctx, id)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get poll: %w", err)
 	}
+
+	if err := s.gate.Authorize(ctx, poll); err != nil {
+		return nil, err
+	}
+
+	if err := redactPoll(ctx, s.voteRepo, poll); err != nil {
+		return nil, err
+	}
 	return poll, nil
 }
 
 func (s *pollService) Vote(ctx context.Context, pollID, optionID uuid.UUID, identifier entity.VoteIdentifier) error {
-	if err := identifier.Validate(); err != nil {
-		return fmt.Errorf("invalid vote identifier: %w", err)
-	}
-
 	tx, err := s.txManager.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
+	ctx = tx.Context()
 
-	// Check if already voted
-	hasVoted, err := s.voteRepo.HasVoted(ctx, pollID, identifier)
+	poll, err := s.pollRepo.GetByID(ctx, pollID)
 	if err != nil {
-		return fmt.Errorf("failed to check vote status: %w", err)
+		return fmt.Errorf("failed to get poll: %w", err)
 	}
-	if hasVoted {
-		return entity.ErrDuplicateVote
+
+	if err := s.gate.Authorize(ctx, poll); err != nil {
+		return err
 	}
 
-	poll, err := s.pollRepo.GetByID(ctx, pollID)
+	if poll.RequiresProofOfWork() && !entity.ActorFromContext(ctx).ProofOfWork {
+		return entity.ErrProofOfWorkRequired
+	}
+
+	if err := poll.DedupPolicy.Validate(identifier); err != nil {
+		return err
+	}
+
+	// Admit the voter before looking up their earlier votes, so callers who
+	// may not vote cannot probe who has
+	if err := s.authorizeVote(ctx, poll); err != nil {
+		return err
+	}
+
+	// Check if already voted under the poll's policy
+	hasVoted, err := s.voteRepo.HasVoted(ctx, pollID, poll.DedupPolicy, identifier)
 	if err != nil {
-		return fmt.Errorf("failed to get poll: %w", err)
+		return fmt.Errorf("failed to check vote status: %w", err)
+	}
+	if hasVoted {
+		return entity.ErrDuplicateVote
 	}
 
 	vote, err := poll.Vote(optionID, identifier)
@@ -123,19 +187,32 @@ func (s *pollService) Vote(ctx context.Context, pollID, optionID uuid.UUID, iden
 	return nil
 }
 
-func (s *pollService) ListPolls(ctx context.Context, page, limit int) ([]*entity.Poll, error) {
-	if page < 1 {
-		page = 1
+func (s *pollService) ListPolls(ctx context.Context, filter repository.PollFilter, page repository.PageRequest) (*repository.PollPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	if page.Limit < 1 || page.Limit > 100 {
+		page.Limit = 10
 	}
-	if limit < 1 || limit > 100 {
-		limit = 10
+	// A cursor takes precedence over page-number pagination
+	if page.Cursor != nil {
+		page.Page = 0
+	} else if page.Page < 1 {
+		page.Page = 1
 	}
 
-	polls, err := s.pollRepo.List(ctx, page, limit)
+	result, err := s.pollRepo.List(ctx, filter, page)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list polls: %w", err)
 	}
-	return polls, nil
+
+	for _, poll := range result.Polls {
+		if err := redactPoll(ctx, nil, poll); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
 }
 
 func (s *pollService) DeletePoll(ctx context.Context, id uuid.UUID) error {
@@ -144,11 +221,90 @@ func (s *pollService) DeletePoll(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
+	ctx = tx.Context()
 
-	if err := s.pollRepo.Delete(ctx, id); err != nil {
+	poll, err := s.pollRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	// Polls are only soft-deleted here; PurgeDeletedPolls removes them for good
+	// once the retention period has passed.
+	if err := s.pollRepo.SoftDelete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete poll: %w", err)
 	}
 
+	deleted := *poll
+	now := time.Now()
+	deleted.DeletedAt = &now
+	if err := s.audit(ctx, entity.AuditPollDeleted, poll, &deleted); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pollService) ArchivePoll(ctx context.Context, id uuid.UUID) error {
+	tx, err := s.txManager.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	ctx = tx.Context()
+
+	poll, err := s.pollRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	if err := s.pollRepo.Archive(ctx, id); err != nil {
+		return fmt.Errorf("failed to archive poll: %w", err)
+	}
+
+	archived := *poll
+	if archived.ArchivedAt == nil {
+		now := time.Now()
+		archived.ArchivedAt = &now
+	}
+	if err := s.audit(ctx, entity.AuditPollArchived, poll, &archived); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pollService) RestorePoll(ctx context.Context, id uuid.UUID) error {
+	tx, err := s.txManager.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	ctx = tx.Context()
+
+	poll, err := s.pollRepo.GetByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	if err := s.pollRepo.Restore(ctx, id); err != nil {
+		return fmt.Errorf("failed to restore poll: %w", err)
+	}
+
+	restored := *poll
+	restored.DeletedAt = nil
+	restored.ArchivedAt = nil
+	if err := s.audit(ctx, entity.AuditPollRestored, poll, &restored); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -156,18 +312,44 @@ func (s *pollService) DeletePoll(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (s *pollService) PurgeDeletedPolls(ctx context.Context, retention time.Duration) (int64, error) {
+	purged, err := s.pollRepo.PurgeDeleted(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted polls: %w", err)
+	}
+	return purged, nil
+}
+
+// ExpireLegacyVoterHashes drops the unkeyed voter identifiers of votes older
+// than the retention period. Those voters are no longer recognised as
+// having voted.
+func (s *pollService) ExpireLegacyVoterHashes(ctx context.Context, retention time.Duration) (int64, error) {
+	expired, err := s.voteRepo.ExpireLegacyIdentifiers(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire legacy voter hashes: %w", err)
+	}
+	return expired, nil
+}
+
 func (s *pollService) UpdatePoll(ctx context.Context, id uuid.UUID, question string, isActive bool, expiresAt *time.Time) error {
 	tx, err := s.txManager.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
+	ctx = tx.Context()
 
 	poll, err := s.pollRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get poll: %w", err)
 	}
 
+	// Archived polls are read-only
+	if poll.IsArchived() {
+		return entity.ErrPollArchived
+	}
+
+	before := *poll
 	poll.Question = question
 	poll.IsActive = isActive
 	poll.ExpiresAt = expiresAt
@@ -177,6 +359,14 @@ func (s *pollService) UpdatePoll(ctx context.Context, id uuid.UUID, question str
 		return fmt.Errorf("failed to update poll: %w", err)
 	}
 
+	action := entity.AuditPollUpdated
+	if before.IsActive && !poll.IsActive {
+		action = entity.AuditPollClosed
+	}
+	if err := s.audit(ctx, action, &before, poll); err != nil {
+		return err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -186,15 +376,161 @@ func (s *pollService) UpdatePoll(ctx context.Context, id uuid.UUID, question str
 
 func (s *pollService) GetPollStats(ctx context.Context, id uuid.UUID) (*entity.PollStats, error) {
 	// First check if poll exists
-	_, err := s.pollRepo.GetByID(ctx, id)
+	poll, err := s.pollRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get poll: %w", err)
 	}
 
+	if err := s.gate.Authorize(ctx, poll); err != nil {
+		return nil, err
+	}
+
 	stats, err := s.voteRepo.GetPollStats(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get poll stats: %w", err)
 	}
 
+	visible, err := resultsVisible(ctx, s.voteRepo, poll)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		stats.Redact()
+	}
+
 	return stats, nil
 }
+
+func (s *pollService) CreateInvite(ctx context.Context, pollID uuid.UUID, maxUses int, expiresAt *time.Time) (*entity.Invite, error) {
+	poll, err := s.pollRepo.GetByID(ctx, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+	if !poll.CanManage(entity.ActorFromContext(ctx)) {
+		return nil, entity.ErrNotPollOwner
+	}
+
+	invite, err := entity.NewInvite(pollID, maxUses, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create invite: %w", err)
+	}
+
+	if err := s.accessRepo.CreateInvite(ctx, invite); err != nil {
+		return nil, fmt.Errorf("failed to save invite: %w", err)
+	}
+
+	return invite, nil
+}
+
+func (s *pollService) RevokeInvite(ctx context.Context, pollID, inviteID uuid.UUID) error {
+	poll, err := s.pollRepo.GetByID(ctx, pollID)
+	if err != nil {
+		return fmt.Errorf("failed to get poll: %w", err)
+	}
+	if !poll.CanManage(entity.ActorFromContext(ctx)) {
+		return entity.ErrNotPollOwner
+	}
+
+	if err := s.accessRepo.RevokeInvite(ctx, pollID, inviteID); err != nil {
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pollService) UnlockPoll(ctx context.Context, pollID uuid.UUID, password string) (string, time.Time, error) {
+	poll, err := s.pollRepo.GetByID(ctx, pollID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	token, expiresAt, err := s.gate.Unlock(ctx, poll, password)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to unlock poll: %w", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// RecentVotes counts the votes cast since the given time in a poll that
+// requires proof of work, so its challenges can be sized to the load
+func (s *pollService) RecentVotes(ctx context.Context, pollID uuid.UUID, since time.Time) (int, error) {
+	poll, err := s.pollRepo.GetByID(ctx, pollID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get poll: %w", err)
+	}
+
+	if err := s.gate.Authorize(ctx, poll); err != nil {
+		return 0, err
+	}
+
+	if !poll.RequiresProofOfWork() {
+		return 0, entity.ErrChallengeNotRequired
+	}
+
+	count, err := s.voteRepo.CountSince(ctx, pollID, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recent votes: %w", err)
+	}
+
+	return count, nil
+}
+
+// AuditLog returns the changes made to a poll, newest first. Only the
+// poll's owner and admins may read it, and it stays readable after the
+// poll is deleted.
+func (s *pollService) AuditLog(ctx context.Context, pollID uuid.UUID) ([]entity.AuditEvent, error) {
+	poll, err := s.pollRepo.GetByIDIncludingDeleted(ctx, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+	if !poll.CanManage(entity.ActorFromContext(ctx)) {
+		return nil, entity.ErrNotPollOwner
+	}
+
+	events, err := s.auditRepo.ListByPoll(ctx, pollID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	return events, nil
+}
+
+// audit records action on a poll by the actor on ctx; before is nil for a
+// new poll
+func (s *pollService) audit(ctx context.Context, action entity.AuditAction, before, after *entity.Poll) error {
+	event := entity.NewAuditEvent(action, before, after, entity.ActorFromContext(ctx))
+	if err := s.auditRepo.Record(ctx, event); err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// authorizeVote admits a voter to a private poll through the allowlist or by
+// redeeming the invite code they presented. It runs inside the vote's
+// transaction, so the redemption is undone if the vote is not saved.
+func (s *pollService) authorizeVote(ctx context.Context, poll *entity.Poll) error {
+	if !poll.IsPrivate() {
+		return nil
+	}
+
+	actor := entity.ActorFromContext(ctx)
+	if actor.UserID != "" || actor.Email != "" {
+		allowed, err := s.accessRepo.IsAllowlisted(ctx, poll.ID, actor.UserID, actor.Email)
+		if err != nil {
+			return fmt.Errorf("failed to check allowlist: %w", err)
+		}
+		if allowed {
+			return nil
+		}
+	}
+
+	if actor.InviteCode == "" {
+		return entity.ErrPollPrivate
+	}
+
+	if err := s.accessRepo.RedeemInvite(ctx, poll.ID, entity.HashInviteCode(actor.InviteCode)); err != nil {
+		return fmt.Errorf("failed to redeem invite: %w", err)
+	}
+
+	return nil
+}
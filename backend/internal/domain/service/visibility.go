@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+)
+
+// resultsVisible applies the poll's results visibility to the actor on ctx.
+// When voteRepo is nil, after-vote polls are treated as hidden so listings
+// do not need a vote lookup per poll.
+func resultsVisible(ctx context.Context, voteRepo repository.VoteRepository, poll *entity.Poll) (bool, error) {
+	actor := entity.ActorFromContext(ctx)
+
+	// Polls without dedup still need some notion of "this voter voted"
+	policy := poll.DedupPolicy
+	if policy == entity.DedupNone {
+		policy = entity.DedupBoth
+	}
+
+	hasVoted := false
+	if poll.ResultsVisibility == entity.ResultsAfterVote && voteRepo != nil && policy.Validate(actor.Identifier) == nil {
+		voted, err := voteRepo.HasVoted(ctx, poll.ID, policy, actor.Identifier)
+		if err != nil {
+			return false, fmt.Errorf("failed to check vote status: %w", err)
+		}
+		hasVoted = voted
+	}
+
+	return poll.ResultsVisibleTo(actor, hasVoted, time.Now()), nil
+}
+
+// redactPoll hides the poll's vote counts if the actor on ctx may not see them
+func redactPoll(ctx context.Context, voteRepo repository.VoteRepository, poll *entity.Poll) error {
+	visible, err := resultsVisible(ctx, voteRepo, poll)
+	if err != nil {
+		return err
+	}
+	if !visible {
+		poll.RedactResults()
+	}
+	return nil
+}
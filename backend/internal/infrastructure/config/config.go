@@ -14,6 +14,15 @@ type Config struct {
 	Cors       CorsConfig
 	Logger     LoggerConfig
 	Monitoring MonitoringConfig
+	Admin      AdminConfig
+	Auth       AuthConfig
+	Security   SecurityConfig
+	Retention  RetentionConfig
+	Analytics  AnalyticsConfig
+	Challenge  ChallengeConfig
+	Anomaly    AnomalyConfig
+	Tracing    TracingConfig
+	Health     HealthConfig
 }
 
 type ServerConfig struct {
@@ -24,6 +33,15 @@ type ServerConfig struct {
 	TimeoutWrite    time.Duration `envconfig:"SERVER_TIMEOUT_WRITE" default:"10s"`
 	TimeoutIdle     time.Duration `envconfig:"SERVER_TIMEOUT_IDLE" default:"120s"`
 	ShutdownTimeout time.Duration `envconfig:"SERVER_SHUTDOWN_TIMEOUT" default:"20s"`
+	// DrainDelay is how long /readyz fails before the server stops
+	// accepting connections, giving load balancers time to notice
+	DrainDelay time.Duration `envconfig:"SERVER_DRAIN_DELAY" default:"5s"`
+}
+
+// DevMode reports whether the server runs in debug or test mode, the only
+// modes allowed to start without the secrets every instance must share
+func (c *ServerConfig) DevMode() bool {
+	return c.Mode == "debug" || c.Mode == "test"
 }
 
 type DatabaseConfig struct {
@@ -35,6 +53,8 @@ type DatabaseConfig struct {
 	SSLMode  string `envconfig:"DB_SSLMODE" default:"disable"`
 	MaxConns int32  `envconfig:"DB_MAX_CONNS" default:"25"`
 	MinConns int32  `envconfig:"DB_MIN_CONNS" default:"5"`
+
+	AutoMigrate bool `envconfig:"DB_AUTO_MIGRATE" default:"false"`
 }
 
 type RateLimitConfig struct {
@@ -47,14 +67,37 @@ type RateLimitConfig struct {
 type CorsConfig struct {
 	AllowedOrigins []string `envconfig:"CORS_ALLOWED_ORIGINS" default:"*"`
 	AllowedMethods []string `envconfig:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,DELETE,OPTIONS"`
-	AllowedHeaders []string `envconfig:"CORS_ALLOWED_HEADERS" default:"Origin,Content-Type,Accept,Authorization"`
+	AllowedHeaders []string `envconfig:"CORS_ALLOWED_HEADERS" default:"Origin,Content-Type,Accept,Authorization,X-Fingerprint-Hash,X-Poll-Owner-Token,X-Poll-Invite-Code,X-Poll-Password,X-Poll-Access-Token"`
 	MaxAge         int      `envconfig:"CORS_MAX_AGE" default:"300"`
 }
 
+// LoggerConfig sets where logs go. Output is stdout, stderr or file; files
+// are rotated once they reach MaxSizeMB, and rotated files older than
+// MaxAgeDays or beyond MaxBackups are removed.
 type LoggerConfig struct {
 	Level  string `envconfig:"LOG_LEVEL" default:"info"`
 	Format string `envconfig:"LOG_FORMAT" default:"json"`
 	Output string `envconfig:"LOG_OUTPUT" default:"stdout"`
+
+	FilePath   string `envconfig:"LOG_FILE_PATH" default:"logs/app.log"`
+	MaxSizeMB  int    `envconfig:"LOG_MAX_SIZE_MB" default:"100"`
+	MaxAgeDays int    `envconfig:"LOG_MAX_AGE_DAYS" default:"7"`
+	MaxBackups int    `envconfig:"LOG_MAX_BACKUPS" default:"5"`
+	Compress   bool   `envconfig:"LOG_COMPRESS" default:"false"`
+
+	// Request logs keep the first SampleInitial identical messages each
+	// SampleTick, then every SampleThereafter-th. Zero disables sampling.
+	SampleTick       time.Duration `envconfig:"LOG_SAMPLE_TICK" default:"1s"`
+	SampleInitial    int           `envconfig:"LOG_SAMPLE_INITIAL" default:"100"`
+	SampleThereafter int           `envconfig:"LOG_SAMPLE_THEREAFTER" default:"100"`
+
+	// BodyRoutes opts routes, written as "METHOD /route/:param", into
+	// logging request bodies and, for error statuses, response bodies.
+	// Bodies must be JSON no larger than BodyMaxBytes and have the fields
+	// at BodyRedact paths masked; anything else is left out.
+	BodyRoutes   []string `envconfig:"LOG_BODY_ROUTES"`
+	BodyMaxBytes int      `envconfig:"LOG_BODY_MAX_BYTES" default:"4096"`
+	BodyRedact   []string `envconfig:"LOG_BODY_REDACT" default:"fingerprint_hash,password,access_token,owner_token,invite_code,token,solution"`
 }
 
 type MonitoringConfig struct {
@@ -62,6 +105,105 @@ type MonitoringConfig struct {
 	MetricsPort string `envconfig:"METRICS_PORT" default:"9090"`
 }
 
+type AdminConfig struct {
+	Token string `envconfig:"ADMIN_TOKEN"`
+}
+
+// SecurityConfig holds secrets and limits for protecting individual polls
+type SecurityConfig struct {
+	AccessTokenSecret string        `envconfig:"POLL_ACCESS_TOKEN_SECRET"`
+	AccessTokenTTL    time.Duration `envconfig:"POLL_ACCESS_TOKEN_TTL" default:"15m"`
+
+	// Failed password attempts are capped per poll and client, and per poll
+	// across all clients
+	PasswordAttemptsPerMinute     int `envconfig:"POLL_PASSWORD_ATTEMPTS_PER_MINUTE" default:"5"`
+	PollPasswordAttemptsPerMinute int `envconfig:"POLL_PASSWORD_POLL_ATTEMPTS_PER_MINUTE" default:"30"`
+
+	// VoterHashKeys are id:secret pairs for hashing voter IPs and
+	// fingerprints. New votes use VoterHashKeyID; the other keys are only
+	// kept to recognise votes cast before a rotation.
+	VoterHashKeyID        string            `envconfig:"VOTER_HASH_KEY_ID"`
+	VoterHashKeys         map[string]string `envconfig:"VOTER_HASH_KEYS"`
+	VoterHashAcceptLegacy bool              `envconfig:"VOTER_HASH_ACCEPT_LEGACY" default:"true"`
+}
+
+// AuthConfig controls where voter identities for allowlists come from. Only
+// enable TrustProxyHeaders behind a proxy that sets and strips them.
+type AuthConfig struct {
+	TrustProxyHeaders bool   `envconfig:"AUTH_TRUST_PROXY_HEADERS" default:"false"`
+	UserHeader        string `envconfig:"AUTH_USER_HEADER" default:"X-Forwarded-User"`
+	EmailHeader       string `envconfig:"AUTH_EMAIL_HEADER" default:"X-Forwarded-Email"`
+}
+
+type RetentionConfig struct {
+	PurgeEnabled         bool          `envconfig:"POLL_PURGE_ENABLED" default:"true"`
+	PurgeInterval        time.Duration `envconfig:"POLL_PURGE_INTERVAL" default:"1h"`
+	DeletedPollRetention time.Duration `envconfig:"POLL_DELETED_RETENTION" default:"720h"`
+	// LegacyVoterHashRetention is how long votes keep the unkeyed IP and
+	// fingerprint hashes recorded before keyed hashing was introduced
+	LegacyVoterHashRetention time.Duration `envconfig:"VOTER_HASH_LEGACY_RETENTION" default:"720h"`
+}
+
+type AnalyticsConfig struct {
+	TrendingWindow     time.Duration `envconfig:"TRENDING_WINDOW" default:"24h"`
+	TrendingHalfLife   time.Duration `envconfig:"TRENDING_HALF_LIFE" default:"6h"`
+	TrendingCacheTTL   time.Duration `envconfig:"TRENDING_CACHE_TTL" default:"30s"`
+	TrendingMaxResults int           `envconfig:"TRENDING_MAX_RESULTS" default:"50"`
+	CrossTabMinCell    int           `envconfig:"CROSSTAB_MIN_CELL_SIZE" default:"5"`
+}
+
+// ChallengeConfig tunes the proof-of-work challenges for polls that require them
+type ChallengeConfig struct {
+	Secret         string        `envconfig:"CHALLENGE_SECRET"`
+	TTL            time.Duration `envconfig:"CHALLENGE_TTL" default:"2m"`
+	BaseDifficulty int           `envconfig:"CHALLENGE_BASE_DIFFICULTY" default:"16"`
+	MaxDifficulty  int           `envconfig:"CHALLENGE_MAX_DIFFICULTY" default:"24"`
+	RateWindow     time.Duration `envconfig:"CHALLENGE_RATE_WINDOW" default:"1m"`
+	VotesPerStep   int           `envconfig:"CHALLENGE_VOTES_PER_STEP" default:"20"`
+}
+
+// AnomalyConfig sets the rules votes are scored against. A rule whose
+// threshold is zero is disabled; a vote scoring QuarantineScore or more is
+// held back from results until an admin reviews it. By default two rules
+// must agree, since any one of them also fires for voters behind a shared
+// NAT or on a common browser.
+type AnomalyConfig struct {
+	Enabled         bool `envconfig:"ANOMALY_DETECTION_ENABLED" default:"true"`
+	QuarantineScore int  `envconfig:"ANOMALY_QUARANTINE_SCORE" default:"2"`
+
+	SubnetBurst       int           `envconfig:"ANOMALY_SUBNET_BURST" default:"20"`
+	SubnetBurstWindow time.Duration `envconfig:"ANOMALY_SUBNET_BURST_WINDOW" default:"1m"`
+	SubnetBurstWeight int           `envconfig:"ANOMALY_SUBNET_BURST_WEIGHT" default:"1"`
+
+	FingerprintsPerIP       int           `envconfig:"ANOMALY_FINGERPRINTS_PER_IP" default:"5"`
+	FingerprintsPerIPWindow time.Duration `envconfig:"ANOMALY_FINGERPRINTS_PER_IP_WINDOW" default:"10m"`
+	FingerprintsPerIPWeight int           `envconfig:"ANOMALY_FINGERPRINTS_PER_IP_WEIGHT" default:"1"`
+
+	UserAgentBurst       int           `envconfig:"ANOMALY_USER_AGENT_BURST" default:"10"`
+	UserAgentBurstWindow time.Duration `envconfig:"ANOMALY_USER_AGENT_BURST_WINDOW" default:"10s"`
+	UserAgentBurstWeight int           `envconfig:"ANOMALY_USER_AGENT_BURST_WEIGHT" default:"1"`
+}
+
+// TracingConfig controls OpenTelemetry tracing. Exporter is otlp to send
+// spans to a collector over HTTP, or stdout or file for local work.
+type TracingConfig struct {
+	Enabled      bool    `envconfig:"TRACING_ENABLED" default:"false"`
+	ServiceName  string  `envconfig:"TRACING_SERVICE_NAME" default:"cactro-polls"`
+	Exporter     string  `envconfig:"TRACING_EXPORTER" default:"otlp"`
+	OTLPEndpoint string  `envconfig:"TRACING_OTLP_ENDPOINT" default:"localhost:4318"`
+	OTLPInsecure bool    `envconfig:"TRACING_OTLP_INSECURE" default:"true"`
+	FilePath     string  `envconfig:"TRACING_FILE" default:"traces.json"`
+	SampleRatio  float64 `envconfig:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+// HealthConfig sets the thresholds /readyz checks against
+type HealthConfig struct {
+	CheckTimeout     time.Duration `envconfig:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+	MaxPoolUsage     float64       `envconfig:"HEALTH_MAX_POOL_USAGE" default:"0.95"`
+	MaxPendingEvents int           `envconfig:"HEALTH_MAX_PENDING_EVENTS" default:"1000"`
+	CheckMigrations  bool          `envconfig:"HEALTH_CHECK_MIGRATIONS" default:"true"`
+}
+
 func Load() (*Config, error) {
 	var config Config
 	if err := envconfig.Process("", &config); err != nil {
@@ -1,17 +1,30 @@
 package container
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"sync"
+	"time"
 
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
 	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
 	"github.com/Sparker0i/cactro-polls/internal/domain/service"
 	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
 	"github.com/Sparker0i/cactro-polls/internal/infrastructure/database"
 	"github.com/Sparker0i/cactro-polls/internal/infrastructure/event"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/health"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/job"
 	"github.com/Sparker0i/cactro-polls/internal/infrastructure/logger"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/metrics"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/ratelimit"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/security"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/tracing"
 	"github.com/Sparker0i/cactro-polls/internal/interface/api/handler"
 	"github.com/Sparker0i/cactro-polls/internal/interface/api/middleware"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/router"
 	"github.com/Sparker0i/cactro-polls/internal/interface/repository/postgres"
+	"github.com/Sparker0i/cactro-polls/migrations"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,18 +33,32 @@ type Container struct {
 	mu         sync.Mutex
 	logger     logger.Logger
 	db         *database.Database
+	tracing    *tracing.Tracing
 	engine     *gin.Engine
 	components componentContainer
 }
 
 type componentContainer struct {
-	eventBus    event.EventBus
-	pollRepo    repository.PollRepository
-	voteRepo    repository.VoteRepository
-	txManager   repository.TransactionManager
-	pollService service.PollService
-	middleware  *middleware.Middleware
-	pollHandler *handler.PollHandler
+	eventBus            event.EventBus
+	pollRepo            repository.PollRepository
+	voteRepo            repository.VoteRepository
+	accessRepo          repository.AccessRepository
+	auditRepo           repository.AuditRepository
+	analyticsRepo       repository.AnalyticsRepository
+	txManager           repository.TransactionManager
+	passwordLimiter     ratelimit.RateLimiter
+	pollPasswordLimiter ratelimit.RateLimiter
+	pollService         service.PollService
+	analyticsService    service.AnalyticsService
+	anomalyService      service.AnomalyService
+	middleware          *middleware.Middleware
+	pollHandler         *handler.PollHandler
+	analyticsHandler    *handler.AnalyticsHandler
+	moderationHandler   *handler.ModerationHandler
+	adminHandler        *handler.AdminHandler
+	purgeJob            *job.PurgeJob
+	metrics             *metrics.Metrics
+	health              *health.Registry
 }
 
 func NewContainer(cfg *config.Config) (*Container, error) {
@@ -60,13 +87,41 @@ func (c *Container) initializeCore() error {
 	}
 	c.logger = log
 
+	// Initialize tracing before the database so queries are traced
+	var dbOpts []database.Option
+	if c.cfg.Tracing.Enabled {
+		exporter, closer, err := tracing.NewExporter(context.Background(), &c.cfg.Tracing)
+		if err != nil {
+			return err
+		}
+		c.tracing = tracing.NewTracing(&c.cfg.Tracing, exporter, closer)
+		c.tracing.Install()
+		dbOpts = append(dbOpts, database.WithQueryLogger(c.tracing.QueryLogger()))
+	}
+
 	// Initialize database
-	db, err := database.NewDatabase(&c.cfg.Database)
+	db, err := database.NewDatabase(&c.cfg.Database, dbOpts...)
 	if err != nil {
 		return err
 	}
 	c.db = db
 
+	// Apply pending migrations before anything touches the schema
+	if c.cfg.Database.AutoMigrate {
+		migrator, err := database.NewMigrator(db.Pool(), migrations.FS)
+		if err != nil {
+			return err
+		}
+
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			return err
+		}
+		c.logger.Info("database migrated",
+			logger.Int("applied", applied),
+		)
+	}
+
 	return nil
 }
 
@@ -77,56 +132,232 @@ func (c *Container) initializeComponents() error {
 	// Initialize event bus
 	c.components.eventBus = event.NewEventBus()
 
+	// Initialize metrics
+	if c.cfg.Monitoring.Enabled {
+		c.components.metrics = metrics.NewMetrics()
+		c.components.metrics.RegisterPool(c.db.Stats)
+		c.components.metrics.RegisterEventBus(c.components.eventBus.Pending)
+	}
+
+	// Initialize readiness checks
+	c.components.health = health.NewRegistry(c.cfg.Health.CheckTimeout)
+	c.components.health.Register("database", health.DatabasePing(c.db.Pool()))
+	c.components.health.Register("database_pool", health.PoolSaturation(c.db.Stats, c.cfg.Health.MaxPoolUsage))
+	c.components.health.Register("event_bus", health.EventBus(c.components.eventBus, c.cfg.Health.MaxPendingEvents))
+	if c.cfg.Health.CheckMigrations {
+		migrator, err := database.NewMigrator(c.db.Pool(), migrations.FS)
+		if err != nil {
+			return err
+		}
+		c.components.health.Register("migrations", health.MigrationVersion(migrator))
+	}
+
 	// Initialize repositories
 	c.components.pollRepo = postgres.NewPollRepository(c.db.Pool())
 	c.components.voteRepo = postgres.NewVoteRepository(c.db.Pool())
+	c.components.accessRepo = postgres.NewAccessRepository(c.db.Pool())
+	c.components.auditRepo = postgres.NewAuditRepository(c.db.Pool())
+	c.components.analyticsRepo = postgres.NewAnalyticsRepository(c.db.Pool())
 	c.components.txManager = postgres.NewTransactionManager(c.db.Pool())
+	quarantineRepo := postgres.NewQuarantineRepository(c.db.Pool())
+
+	// Initialize poll password checks
+	accessTokens, err := security.NewAccessTokens(c.cfg.Security.AccessTokenSecret, c.cfg.Security.AccessTokenTTL)
+	if err != nil {
+		return err
+	}
+	if err := c.requireSecret("POLL_ACCESS_TOKEN_SECRET", c.cfg.Security.AccessTokenSecret != "",
+		"poll access tokens will not survive a restart"); err != nil {
+		return err
+	}
+	c.components.passwordLimiter = ratelimit.NewRateLimiter(&config.RateLimitConfig{
+		Enabled:           true,
+		RequestsPerMinute: c.cfg.Security.PasswordAttemptsPerMinute,
+		TTL:               10 * time.Minute,
+	})
+	c.components.pollPasswordLimiter = ratelimit.NewRateLimiter(&config.RateLimitConfig{
+		Enabled:           true,
+		RequestsPerMinute: c.cfg.Security.PollPasswordAttemptsPerMinute,
+		TTL:               10 * time.Minute,
+	})
+	gate := service.NewPasswordGate(accessTokens, c.components.pollPasswordLimiter, c.components.passwordLimiter)
 
 	// Initialize service
 	c.components.pollService = service.NewPollService(
 		c.components.pollRepo,
 		c.components.voteRepo,
+		c.components.accessRepo,
+		c.components.auditRepo,
 		c.components.txManager,
 		c.components.eventBus,
+		gate,
+	)
+	if c.tracing != nil {
+		c.components.pollService = tracing.TracePollService(c.components.pollService, c.tracing)
+	}
+	if c.components.metrics != nil {
+		c.components.metrics.RegisterRateLimiter("poll_password", c.components.passwordLimiter.Rejected)
+		c.components.metrics.RegisterRateLimiter("poll_password_per_poll", c.components.pollPasswordLimiter.Rejected)
+		c.components.pollService = metrics.InstrumentPollService(c.components.pollService, c.components.metrics)
+	}
+	c.components.analyticsService = service.NewAnalyticsService(
+		c.components.pollRepo,
+		c.components.voteRepo,
+		c.components.analyticsRepo,
+		service.TrendingOptions{
+			Window:     c.cfg.Analytics.TrendingWindow,
+			HalfLife:   c.cfg.Analytics.TrendingHalfLife,
+			CacheTTL:   c.cfg.Analytics.TrendingCacheTTL,
+			MaxResults: c.cfg.Analytics.TrendingMaxResults,
+		},
+		service.CrossTabOptions{
+			MinCellSize: c.cfg.Analytics.CrossTabMinCell,
+		},
+		gate,
+	)
+
+	c.components.anomalyService = service.NewAnomalyService(quarantineRepo, service.AnomalyOptions{
+		Rules: []entity.AnomalyRule{
+			{
+				Signal:    entity.SignalSubnetBurst,
+				Window:    c.cfg.Anomaly.SubnetBurstWindow,
+				Threshold: c.cfg.Anomaly.SubnetBurst,
+				Weight:    c.cfg.Anomaly.SubnetBurstWeight,
+			},
+			{
+				Signal:    entity.SignalFingerprintsPerIP,
+				Window:    c.cfg.Anomaly.FingerprintsPerIPWindow,
+				Threshold: c.cfg.Anomaly.FingerprintsPerIP,
+				Weight:    c.cfg.Anomaly.FingerprintsPerIPWeight,
+			},
+			{
+				Signal:    entity.SignalUserAgentBurst,
+				Window:    c.cfg.Anomaly.UserAgentBurstWindow,
+				Threshold: c.cfg.Anomaly.UserAgentBurst,
+				Weight:    c.cfg.Anomaly.UserAgentBurstWeight,
+			},
+		},
+		QuarantineScore: c.cfg.Anomaly.QuarantineScore,
+	})
+	if c.cfg.Anomaly.Enabled {
+		c.components.eventBus.Subscribe(service.VoteRecordedEvent{}, service.HandleVoteRecorded(
+			c.components.anomalyService,
+			func(err error) {
+				c.logger.Error("failed to inspect vote for anomalies", logger.Error(err))
+			},
+		))
+	}
+
+	// Initialize voter identifier hashing
+	voterHasher, err := security.NewVoterHasher(
+		c.cfg.Security.VoterHashKeyID,
+		c.cfg.Security.VoterHashKeys,
+		c.cfg.Security.VoterHashAcceptLegacy,
 	)
+	if err != nil {
+		return err
+	}
+	if err := c.requireSecret("VOTER_HASH_KEYS", len(c.cfg.Security.VoterHashKeys) > 0,
+		"duplicate vote detection will not survive a restart"); err != nil {
+		return err
+	}
+
+	// Initialize proof-of-work challenges
+	if err := c.requireSecret("CHALLENGE_SECRET", c.cfg.Challenge.Secret != "",
+		"challenges only verify on the instance that issued them"); err != nil {
+		return err
+	}
+	var challenges handler.ChallengeIssuer
+	challenges, err = security.NewChallenges(security.ChallengeOptions{
+		Secret:         c.cfg.Challenge.Secret,
+		TTL:            c.cfg.Challenge.TTL,
+		BaseDifficulty: c.cfg.Challenge.BaseDifficulty,
+		MaxDifficulty:  c.cfg.Challenge.MaxDifficulty,
+		Window:         c.cfg.Challenge.RateWindow,
+		VotesPerStep:   c.cfg.Challenge.VotesPerStep,
+	}, postgres.NewChallengeRepository(c.db.Pool()))
+	if err != nil {
+		return err
+	}
+	if c.components.metrics != nil {
+		challenges = metrics.InstrumentChallenges(challenges, c.components.metrics)
+	}
 
 	// Initialize API components
-	c.components.middleware = middleware.NewMiddleware(c.logger)
-	c.components.pollHandler = handler.NewPollHandler(c.components.pollService)
+	c.components.middleware = middleware.NewMiddleware(c.logger, &c.cfg.Admin, &c.cfg.Auth, &c.cfg.Logger)
+	c.components.pollHandler = handler.NewPollHandler(c.components.pollService, voterHasher, challenges)
+	c.components.analyticsHandler = handler.NewAnalyticsHandler(c.components.analyticsService, voterHasher)
+	c.components.moderationHandler = handler.NewModerationHandler(c.components.anomalyService)
+	c.components.adminHandler = handler.NewAdminHandler(c.logger.Level())
+
+	// Initialize background jobs
+	if c.cfg.Retention.PurgeEnabled {
+		c.components.purgeJob = job.NewPurgeJob(c.components.pollService, c.logger, &c.cfg.Retention)
+		c.components.purgeJob.Start()
+	}
 
 	return nil
 }
 
+// requireSecret fails startup when a secret every instance must share is
+// not configured. In dev mode a random per-process value is used instead,
+// and the consequence is logged.
+func (c *Container) requireSecret(name string, set bool, consequence string) error {
+	if set {
+		return nil
+	}
+	if !c.cfg.Server.DevMode() {
+		return fmt.Errorf("%s must be set unless SERVER_MODE is debug or test", name)
+	}
+	c.logger.Warn(name + " is not set; " + consequence)
+	return nil
+}
+
 func (c *Container) InitializeHTTP() *gin.Engine {
 	gin.SetMode(c.cfg.Server.Mode)
-	engine := gin.New()
-
-	// Setup middleware
-	engine.Use(c.components.middleware.RequestID())
-	engine.Use(c.components.middleware.Logger())
-	engine.Use(c.components.middleware.Recovery())
-
-	// Setup routes
-	api := engine.Group("/api")
-	{
-		polls := api.Group("/polls")
-		{
-			polls.POST("", c.components.pollHandler.CreatePoll)
-			polls.GET("", c.components.pollHandler.ListPolls)
-			polls.GET("/:id", c.components.pollHandler.GetPoll)
-			polls.POST("/:id/vote", c.components.pollHandler.Vote)
-		}
+
+	// Setup middleware and routes
+	r := router.NewRouter(
+		c.components.pollHandler,
+		c.components.analyticsHandler,
+		c.components.moderationHandler,
+		c.components.adminHandler,
+		c.components.middleware,
+		c.components.health,
+	)
+	if c.tracing != nil {
+		r.Use(c.tracing.Middleware())
+	}
+	if c.components.metrics != nil {
+		r.Use(c.components.metrics.Middleware())
 	}
+	r.Setup()
 
-	// Health check
-	engine.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "healthy",
-		})
-	})
+	c.engine = r.Engine()
+	return c.engine
+}
+
+// MetricsServer returns the listener for the metrics port, or nil when
+// monitoring is disabled
+func (c *Container) MetricsServer() *http.Server {
+	if c.components.metrics == nil {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", c.components.metrics.Handler())
 
-	c.engine = engine
-	return engine
+	return &http.Server{
+		Addr:              fmt.Sprintf("%s:%s", c.cfg.Server.Host, c.cfg.Monitoring.MetricsPort),
+		Handler:           mux,
+		ReadHeaderTimeout: c.cfg.Server.TimeoutRead,
+		WriteTimeout:      c.cfg.Server.TimeoutWrite,
+	}
+}
+
+// Health returns the readiness check registry
+func (c *Container) Health() *health.Registry {
+	return c.components.health
 }
 
 func (c *Container) Logger() logger.Logger {
@@ -137,11 +368,32 @@ func (c *Container) Cleanup() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.components.purgeJob != nil {
+		c.components.purgeJob.Stop()
+	}
+
 	if c.components.eventBus != nil {
 		c.components.eventBus.Stop()
 	}
 
+	if c.components.passwordLimiter != nil {
+		c.components.passwordLimiter.Stop()
+	}
+
+	if c.components.pollPasswordLimiter != nil {
+		c.components.pollPasswordLimiter.Stop()
+	}
+
 	if c.db != nil {
 		c.db.Close()
 	}
+
+	// Flush spans last so those from the shutdown itself are exported
+	if c.tracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := c.tracing.Shutdown(ctx); err != nil {
+			c.logger.Error("failed to flush traces", logger.Error(err))
+		}
+	}
 }
@@ -14,7 +14,18 @@ type Database struct {
 	cfg  *config.DatabaseConfig
 }
 
-func NewDatabase(cfg *config.DatabaseConfig) (*Database, error) {
+// Option adjusts the pool configuration before connecting
+type Option func(*pgxpool.Config)
+
+// WithQueryLogger sends every query pgx runs to logger
+func WithQueryLogger(logger pgx.Logger) Option {
+	return func(c *pgxpool.Config) {
+		c.ConnConfig.Logger = logger
+		c.ConnConfig.LogLevel = pgx.LogLevelInfo
+	}
+}
+
+func NewDatabase(cfg *config.DatabaseConfig, opts ...Option) (*Database, error) {
 	poolConfig, err := pgxpool.ParseConfig(cfg.ConnectionString())
 	if err != nil {
 		return nil, err
@@ -26,6 +37,9 @@ func NewDatabase(cfg *config.DatabaseConfig) (*Database, error) {
 	poolConfig.MaxConnLifetime = time.Hour
 	poolConfig.MaxConnIdleTime = 30 * time.Minute
 	poolConfig.HealthCheckPeriod = time.Minute
+	for _, opt := range opts {
+		opt(poolConfig)
+	}
 
 	// Create pool
 	pool, err := pgxpool.ConnectConfig(context.Background(), poolConfig)
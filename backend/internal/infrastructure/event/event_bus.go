@@ -3,12 +3,17 @@ package event
 import (
 	"reflect"
 	"sync"
+	"sync/atomic"
 )
 
 type EventBus interface {
 	Publish(event interface{})
 	Subscribe(eventType interface{}, handler func(event interface{}))
 	Unsubscribe(eventType interface{}, handler func(event interface{}))
+	// Pending reports how many dispatched handlers have not finished
+	Pending() int
+	// Stopped reports whether Stop has been called
+	Stopped() bool
 	Stop()
 }
 
@@ -17,6 +22,7 @@ type eventBus struct {
 	mu       sync.RWMutex
 	stopChan chan struct{}
 	stopOnce sync.Once
+	pending  atomic.Int64
 }
 
 func NewEventBus() EventBus {
@@ -33,7 +39,11 @@ func (b *eventBus) Publish(event interface{}) {
 	eventType := reflect.TypeOf(event).String()
 	if handlers, exists := b.handlers[eventType]; exists {
 		for _, handler := range handlers {
-			go handler(event) // Non-blocking event handling
+			b.pending.Add(1)
+			go func(handler func(event interface{})) { // Non-blocking event handling
+				defer b.pending.Add(-1)
+				handler(event)
+			}(handler)
 		}
 	}
 }
@@ -61,6 +71,19 @@ func (b *eventBus) Unsubscribe(eventType interface{}, handler func(event interfa
 	}
 }
 
+func (b *eventBus) Pending() int {
+	return int(b.pending.Load())
+}
+
+func (b *eventBus) Stopped() bool {
+	select {
+	case <-b.stopChan:
+		return true
+	default:
+		return false
+	}
+}
+
 // Event definitions
 type Event interface {
 	EventType() string
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Pinger is satisfied by *pgxpool.Pool
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// DatabasePing fails when the database does not answer a ping before the
+// check times out
+func DatabasePing(db Pinger) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		if err := db.Ping(ctx); err != nil {
+			return nil, fmt.Errorf("failed to ping database: %w", err)
+		}
+		return nil, nil
+	}
+}
+
+// PoolSaturation fails once at least maxUtilisation of the pool's
+// connections are checked out, so new requests would queue for one
+func PoolSaturation(stats func() *pgxpool.Stat, maxUtilisation float64) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		stat := stats()
+		utilisation := 0.0
+		if stat.MaxConns() > 0 {
+			utilisation = float64(stat.AcquiredConns()) / float64(stat.MaxConns())
+		}
+
+		detail := map[string]interface{}{
+			"acquired":    stat.AcquiredConns(),
+			"idle":        stat.IdleConns(),
+			"total":       stat.TotalConns(),
+			"max":         stat.MaxConns(),
+			"utilisation": utilisation,
+		}
+		if utilisation >= maxUtilisation {
+			return detail, fmt.Errorf("connection pool is %.0f%% utilised", utilisation*100)
+		}
+		return detail, nil
+	}
+}
+
+// SchemaVersioner is satisfied by *database.Migrator
+type SchemaVersioner interface {
+	Version(ctx context.Context) (int64, error)
+	Latest() int64
+}
+
+// MigrationVersion fails when the schema is behind the migrations this
+// build ships with
+func MigrationVersion(migrator SchemaVersioner) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema version: %w", err)
+		}
+
+		detail := map[string]interface{}{
+			"version":  version,
+			"expected": migrator.Latest(),
+		}
+		if version < migrator.Latest() {
+			return detail, fmt.Errorf("schema is at version %d, expected %d", version, migrator.Latest())
+		}
+		return detail, nil
+	}
+}
+
+// EventBusStatus is the part of event.EventBus this package reads
+type EventBusStatus interface {
+	Pending() int
+	Stopped() bool
+}
+
+// EventBus fails when the bus has stopped or maxPending handlers are
+// still running, meaning subscribers are not keeping up
+func EventBus(bus EventBusStatus, maxPending int) Check {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		pending := bus.Pending()
+		detail := map[string]interface{}{
+			"pending": pending,
+		}
+		if bus.Stopped() {
+			return detail, errors.New("event bus is stopped")
+		}
+		if pending >= maxPending {
+			return detail, fmt.Errorf("%d event handlers pending", pending)
+		}
+		return detail, nil
+	}
+}
@@ -0,0 +1,210 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check reports whether one dependency is usable. Detail is included in
+// the detailed report whether or not the check fails.
+type Check func(ctx context.Context) (detail map[string]interface{}, err error)
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Status   string                 `json:"status"`
+	Error    string                 `json:"error,omitempty"`
+	Duration string                 `json:"duration"`
+	Detail   map[string]interface{} `json:"detail,omitempty"`
+}
+
+// Report is the outcome of every registered check
+type Report struct {
+	Status       string                 `json:"status"`
+	ShuttingDown bool                   `json:"shutting_down,omitempty"`
+	Checks       map[string]CheckResult `json:"checks"`
+}
+
+// Ready reports whether the instance should receive traffic
+func (r Report) Ready() bool {
+	return r.Status == StatusUp
+}
+
+// Summary returns the report without check errors and details, which can
+// name internal hosts and resources
+func (r Report) Summary() Report {
+	checks := make(map[string]CheckResult, len(r.Checks))
+	for name, result := range r.Checks {
+		checks[name] = CheckResult{Status: result.Status, Duration: result.Duration}
+	}
+	r.Checks = checks
+	return r
+}
+
+// Registry runs named readiness checks concurrently, each bounded by the
+// same timeout
+type Registry struct {
+	mu           sync.RWMutex
+	checks       map[string]Check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{
+		checks:  make(map[string]Check),
+		timeout: timeout,
+	}
+}
+
+// Register adds check under name, replacing any check already registered
+// with that name
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+// MarkShuttingDown fails readiness from now on so load balancers stop
+// sending traffic before the server closes its listeners
+func (r *Registry) MarkShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Run executes every check and reports the instance ready only if all pass
+// and it is not shutting down
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make(map[string]Check, len(r.checks))
+	for name, check := range r.checks {
+		checks[name] = check
+	}
+	r.mu.RUnlock()
+
+	report := Report{
+		Status:       StatusUp,
+		ShuttingDown: r.shuttingDown.Load(),
+		Checks:       make(map[string]CheckResult, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			result := r.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+		}(name, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	if report.ShuttingDown {
+		report.Status = StatusDown
+	}
+
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check(ctx)
+	result := CheckResult{
+		Status:   StatusUp,
+		Duration: time.Since(start).String(),
+		Detail:   detail,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Livez reports that the process is serving requests. It runs no checks so
+// a struggling dependency does not get the instance restarted.
+//
+// @Summary Liveness probe
+// @Description Answers while the process is serving requests
+// @Tags health
+// @Produce json
+// @Success 200 {object} object
+// @Router /livez [get]
+func (r *Registry) Livez() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status": StatusUp,
+		})
+	}
+}
+
+// Health is the original health check, kept for existing clients. Like
+// Livez it runs no checks.
+//
+// @Summary Health check
+// @Description Legacy liveness check; prefer /livez and /readyz
+// @Tags health
+// @Produce json
+// @Success 200 {object} object
+// @Router /health [get]
+func (r *Registry) Health() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status": "healthy",
+		})
+	}
+}
+
+// Readyz runs the registered checks, answering 503 if any fail or the
+// instance is shutting down. Errors and details are only shown to callers
+// for whom detailed returns true; failures are always added to the
+// request's errors so they reach the request log.
+//
+// @Summary Readiness probe
+// @Description Runs the dependency checks. Check errors and details are only included for admins.
+// @Tags health
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} Report
+// @Failure 503 {object} Report
+// @Router /readyz [get]
+func (r *Registry) Readyz(detailed func(c *gin.Context) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := r.Run(c.Request.Context())
+
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+			for name, result := range report.Checks {
+				if result.Status != StatusUp {
+					c.Error(fmt.Errorf("readiness check %s failed: %s", name, result.Error))
+				}
+			}
+		}
+		if !detailed(c) {
+			report = report.Summary()
+		}
+		c.JSON(status, report)
+	}
+}
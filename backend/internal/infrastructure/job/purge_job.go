@@ -0,0 +1,86 @@
+package job
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/logger"
+)
+
+// PurgeJob periodically hard-deletes polls that have been soft-deleted for
+// longer than the configured retention period, and drops legacy voter
+// identifiers once theirs has passed
+type PurgeJob struct {
+	pollService service.PollService
+	logger      logger.Logger
+	cfg         *config.RetentionConfig
+	stopCh      chan struct{}
+	stopOnce    sync.Once
+}
+
+func NewPurgeJob(
+	pollService service.PollService,
+	logger logger.Logger,
+	cfg *config.RetentionConfig,
+) *PurgeJob {
+	return &PurgeJob{
+		pollService: pollService,
+		logger:      logger,
+		cfg:         cfg,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+func (j *PurgeJob) Start() {
+	go j.loop()
+}
+
+func (j *PurgeJob) loop() {
+	ticker := time.NewTicker(j.cfg.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.run()
+		case <-j.stopCh:
+			return
+		}
+	}
+}
+
+func (j *PurgeJob) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	purged, err := j.pollService.PurgeDeletedPolls(ctx, j.cfg.DeletedPollRetention)
+	if err != nil {
+		j.logger.Error("failed to purge deleted polls",
+			logger.Error(err),
+		)
+	} else if purged > 0 {
+		j.logger.Info("purged deleted polls",
+			logger.Int("count", int(purged)),
+		)
+	}
+
+	expired, err := j.pollService.ExpireLegacyVoterHashes(ctx, j.cfg.LegacyVoterHashRetention)
+	if err != nil {
+		j.logger.Error("failed to expire legacy voter hashes",
+			logger.Error(err),
+		)
+	} else if expired > 0 {
+		j.logger.Info("expired legacy voter hashes",
+			logger.Int("count", int(expired)),
+		)
+	}
+}
+
+func (j *PurgeJob) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stopCh)
+	})
+}
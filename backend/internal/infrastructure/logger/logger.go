@@ -1,11 +1,13 @@
 package logger
 
 import (
+	"errors"
 	"os"
 
 	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 type Logger interface {
@@ -15,31 +17,40 @@ type Logger interface {
 	Error(msg string, fields ...Field)
 	Fatal(msg string, fields ...Field)
 	With(fields ...Field) Logger
+	// Sampled returns a logger that drops repeats of the same message once
+	// more than the configured number are logged per tick, for
+	// high-volume logs such as one line per request
+	Sampled() Logger
+	// Level is shared by every logger derived from this one, so changing
+	// it takes effect everywhere at once
+	Level() zap.AtomicLevel
 }
 
 type Field = zapcore.Field
 
 type zapLogger struct {
-	logger *zap.Logger
+	logger  *zap.Logger
+	sampled *zap.Logger
+	level   zap.AtomicLevel
 }
 
 func NewLogger(cfg *config.LoggerConfig) (Logger, error) {
-	// Configure logger
-	config := zap.NewProductionConfig()
-
 	// Set level
 	level, err := zapcore.ParseLevel(cfg.Level)
 	if err != nil {
 		return nil, err
 	}
-	config.Level = zap.NewAtomicLevelAt(level)
+	atomicLevel := zap.NewAtomicLevelAt(level)
 
-	// Configure output
+	// Configure encoding
+	var encoder zapcore.Encoder
 	switch cfg.Format {
 	case "console":
-		config.Encoding = "console"
+		encoderConfig := zap.NewDevelopmentEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
 	default:
-		config.Encoding = "json"
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
 	}
 
 	// Configure output destination
@@ -47,20 +58,35 @@ func NewLogger(cfg *config.LoggerConfig) (Logger, error) {
 	switch cfg.Output {
 	case "stderr":
 		output = zapcore.AddSync(os.Stderr)
+	case "file":
+		if cfg.FilePath == "" {
+			return nil, errors.New("LOG_FILE_PATH is required when logging to a file")
+		}
+		output = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   cfg.FilePath,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxAge:     cfg.MaxAgeDays,
+			MaxBackups: cfg.MaxBackups,
+			Compress:   cfg.Compress,
+		})
 	default:
 		output = zapcore.AddSync(os.Stdout)
 	}
 
 	// Create core
-	core := zapcore.NewCore(
-		zapcore.NewJSONEncoder(config.EncoderConfig),
-		output,
-		config.Level,
-	)
+	core := zapcore.NewCore(encoder, output, atomicLevel)
+
+	sampledCore := core
+	if cfg.SampleThereafter > 0 {
+		sampledCore = zapcore.NewSamplerWithOptions(core, cfg.SampleTick, cfg.SampleInitial, cfg.SampleThereafter)
+	}
 
 	// Create logger
-	logger := zap.New(core)
-	return &zapLogger{logger: logger}, nil
+	return &zapLogger{
+		logger:  zap.New(core),
+		sampled: zap.New(sampledCore),
+		level:   atomicLevel,
+	}, nil
 }
 
 func (l *zapLogger) Debug(msg string, fields ...Field) {
@@ -84,7 +110,23 @@ func (l *zapLogger) Fatal(msg string, fields ...Field) {
 }
 
 func (l *zapLogger) With(fields ...Field) Logger {
-	return &zapLogger{logger: l.logger.With(fields...)}
+	return &zapLogger{
+		logger:  l.logger.With(fields...),
+		sampled: l.sampled.With(fields...),
+		level:   l.level,
+	}
+}
+
+func (l *zapLogger) Sampled() Logger {
+	return &zapLogger{
+		logger:  l.sampled,
+		sampled: l.sampled,
+		level:   l.level,
+	}
+}
+
+func (l *zapLogger) Level() zap.AtomicLevel {
+	return l.level
 }
 
 // Helper functions for creating fields
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// ChallengeIssuer issues and verifies proof-of-work challenges
+type ChallengeIssuer interface {
+	Window() time.Duration
+	Issue(pollID uuid.UUID, recentVotes int) (*entity.Challenge, error)
+	Verify(ctx context.Context, token, solution string, pollID uuid.UUID) error
+}
+
+// instrumentedChallenges counts votes refused for a bad challenge solution
+type instrumentedChallenges struct {
+	ChallengeIssuer
+	metrics *Metrics
+}
+
+// InstrumentChallenges wraps challenges so failed verifications are counted
+// as rejected votes. They are refused before the vote reaches the poll
+// service, so InstrumentPollService never sees them.
+func InstrumentChallenges(challenges ChallengeIssuer, m *Metrics) ChallengeIssuer {
+	return &instrumentedChallenges{
+		ChallengeIssuer: challenges,
+		metrics:         m,
+	}
+}
+
+func (c *instrumentedChallenges) Verify(ctx context.Context, token, solution string, pollID uuid.UUID) error {
+	err := c.ChallengeIssuer.Verify(ctx, token, solution, pollID)
+	if err != nil {
+		c.metrics.votesRejected.WithLabelValues(rejectionReason(err)).Inc()
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "polls"
+
+// Metrics owns the Prometheus registry served on the metrics port. Request
+// and domain metrics are recorded directly; pool, event bus and rate limiter
+// figures are read from their sources at scrape time.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+
+	pollsCreated  prometheus.Counter
+	votesCast     prometheus.Counter
+	votesRejected *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	m := &Metrics{
+		registry: registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		pollsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "polls_created_total",
+			Help:      "Polls created.",
+		}),
+		votesCast: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "votes_cast_total",
+			Help:      "Votes recorded.",
+		}),
+		votesRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "votes_rejected_total",
+			Help:      "Votes refused, by reason.",
+		}, []string{"reason"}),
+	}
+	registry.MustRegister(m.requests, m.latency, m.pollsCreated, m.votesCast, m.votesRejected)
+
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the count and latency of each request. Routes are
+// labelled by their pattern so poll IDs do not blow up the label set.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		m.requests.WithLabelValues(route, c.Request.Method, status).Inc()
+		m.latency.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterEventBus exposes the number of event handlers still running
+func (m *Metrics) RegisterEventBus(pending func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "event_bus_pending_handlers",
+		Help:      "Event handlers dispatched but not yet finished.",
+	}, func() float64 {
+		return float64(pending())
+	}))
+}
+
+// RegisterRateLimiter exposes the requests a named rate limiter has refused
+func (m *Metrics) RegisterRateLimiter(name string, rejected func() uint64) {
+	m.registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace:   namespace,
+		Name:        "rate_limiter_rejections_total",
+		Help:        "Requests refused by a rate limiter.",
+		ConstLabels: prometheus.Labels{"limiter": name},
+	}, func() float64 {
+		return float64(rejected())
+	}))
+}
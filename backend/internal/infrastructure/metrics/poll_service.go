@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/google/uuid"
+)
+
+// instrumentedPollService counts poll and vote outcomes around a PollService
+type instrumentedPollService struct {
+	service.PollService
+	metrics *Metrics
+}
+
+// InstrumentPollService wraps svc so polls created and votes cast or
+// rejected are counted
+func InstrumentPollService(svc service.PollService, m *Metrics) service.PollService {
+	return &instrumentedPollService{
+		PollService: svc,
+		metrics:     m,
+	}
+}
+
+func (s *instrumentedPollService) CreatePoll(ctx context.Context, question string, options []string, expiresAt *time.Time, settings entity.PollSettings) (*entity.Poll, error) {
+	poll, err := s.PollService.CreatePoll(ctx, question, options, expiresAt, settings)
+	if err == nil {
+		s.metrics.pollsCreated.Inc()
+	}
+	return poll, err
+}
+
+func (s *instrumentedPollService) Vote(ctx context.Context, pollID, optionID uuid.UUID, identifier entity.VoteIdentifier) error {
+	err := s.PollService.Vote(ctx, pollID, optionID, identifier)
+	if err != nil {
+		s.metrics.votesRejected.WithLabelValues(rejectionReason(err)).Inc()
+		return err
+	}
+	s.metrics.votesCast.Inc()
+	return nil
+}
+
+// rejectionReasons label vote failures; anything else is an error
+var rejectionReasons = []struct {
+	err    error
+	reason string
+}{
+	{entity.ErrDuplicateVote, "duplicate"},
+	{entity.ErrPollNotFound, "not_found"},
+	{entity.ErrPollInactive, "inactive"},
+	{entity.ErrPollExpired, "expired"},
+	{entity.ErrPollArchived, "archived"},
+	{entity.ErrPollNotStarted, "not_started"},
+	{entity.ErrInvalidOption, "invalid_option"},
+	{entity.ErrInvalidVoteIdentifier, "invalid_identifier"},
+	{entity.ErrVoterNotAuthenticated, "not_authenticated"},
+	{entity.ErrPollPrivate, "private"},
+	{entity.ErrInvalidInvite, "invalid_invite"},
+	{entity.ErrPasswordRequired, "password"},
+	{entity.ErrIncorrectPassword, "password"},
+	{entity.ErrTooManyPasswordAttempts, "password"},
+	{entity.ErrProofOfWorkRequired, "challenge"},
+	{entity.ErrInvalidProofOfWork, "challenge"},
+}
+
+func rejectionReason(err error) string {
+	for _, r := range rejectionReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	return "error"
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reports pgxpool statistics at scrape time
+type poolCollector struct {
+	stats func() *pgxpool.Stat
+
+	acquired     *prometheus.Desc
+	idle         *prometheus.Desc
+	constructing *prometheus.Desc
+	total        *prometheus.Desc
+	max          *prometheus.Desc
+	acquires     *prometheus.Desc
+	emptyWaits   *prometheus.Desc
+	canceled     *prometheus.Desc
+	waitSeconds  *prometheus.Desc
+}
+
+// RegisterPool exposes connection pool statistics, typically from
+// Database.Stats
+func (m *Metrics) RegisterPool(stats func() *pgxpool.Stat) {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	m.registry.MustRegister(&poolCollector{
+		stats:        stats,
+		acquired:     desc("acquired_conns", "Connections currently checked out."),
+		idle:         desc("idle_conns", "Idle connections in the pool."),
+		constructing: desc("constructing_conns", "Connections being opened."),
+		total:        desc("total_conns", "All connections in the pool."),
+		max:          desc("max_conns", "Maximum size of the pool."),
+		acquires:     desc("acquires_total", "Successful connection acquires."),
+		emptyWaits:   desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		canceled:     desc("canceled_acquires_total", "Acquires canceled by their context."),
+		waitSeconds:  desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+	})
+}
+
+func (p *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.acquired
+	ch <- p.idle
+	ch <- p.constructing
+	ch <- p.total
+	ch <- p.max
+	ch <- p.acquires
+	ch <- p.emptyWaits
+	ch <- p.canceled
+	ch <- p.waitSeconds
+}
+
+func (p *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := p.stats()
+	if stat == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(p.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(p.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(p.constructing, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(p.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(p.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(p.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(p.emptyWaits, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(p.canceled, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(p.waitSeconds, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
@@ -2,6 +2,7 @@ package ratelimit
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
@@ -9,11 +10,17 @@ import (
 
 type RateLimiter interface {
 	Allow(key string) bool
+	// Refund returns a token taken by Allow, up to the bucket's capacity
+	Refund(key string)
 	RemainingTokens(key string) int
 	Reset(key string) time.Time
+	// Rejected reports how many calls to Allow have been refused
+	Rejected() uint64
+	Stop()
 }
 
 type tokenBucket struct {
+	mu         sync.Mutex
 	tokens     int
 	capacity   int
 	lastRefill time.Time
@@ -25,6 +32,7 @@ type rateLimiter struct {
 	config    *config.RateLimitConfig
 	cleanupCh chan struct{}
 	stopOnce  sync.Once
+	rejected  atomic.Uint64
 }
 
 func NewRateLimiter(cfg *config.RateLimitConfig) RateLimiter {
@@ -43,7 +51,26 @@ func (rl *rateLimiter) Allow(key string) bool {
 	}
 
 	bucket := rl.getBucket(key)
-	return bucket.tryConsume()
+	if !bucket.tryConsume() {
+		rl.rejected.Add(1)
+		return false
+	}
+	return true
+}
+
+func (rl *rateLimiter) Refund(key string) {
+	if !rl.config.Enabled {
+		return
+	}
+
+	bucket := rl.getBucket(key)
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	bucket.tokens = min(bucket.capacity, bucket.tokens+1)
+}
+
+func (rl *rateLimiter) Rejected() uint64 {
+	return rl.rejected.Load()
 }
 
 func (rl *rateLimiter) RemainingTokens(key string) int {
@@ -52,11 +79,16 @@ func (rl *rateLimiter) RemainingTokens(key string) int {
 	}
 
 	bucket := rl.getBucket(key)
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	bucket.refill()
 	return bucket.tokens
 }
 
 func (rl *rateLimiter) Reset(key string) time.Time {
 	bucket := rl.getBucket(key)
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
 	return bucket.lastRefill.Add(time.Minute)
 }
 
@@ -71,6 +103,8 @@ func (rl *rateLimiter) getBucket(key string) *tokenBucket {
 }
 
 func (b *tokenBucket) tryConsume() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	b.refill()
 	if b.tokens > 0 {
 		b.tokens--
@@ -79,6 +113,7 @@ func (b *tokenBucket) tryConsume() bool {
 	return false
 }
 
+// refill adds the tokens earned since the last refill; callers hold b.mu
 func (b *tokenBucket) refill() {
 	now := time.Now()
 	elapsed := now.Sub(b.lastRefill).Seconds()
@@ -108,7 +143,10 @@ func (rl *rateLimiter) cleanup() {
 	now := time.Now()
 	rl.buckets.Range(func(key, value interface{}) bool {
 		bucket := value.(*tokenBucket)
-		if now.Sub(bucket.lastRefill) > rl.config.TTL {
+		bucket.mu.Lock()
+		idle := now.Sub(bucket.lastRefill)
+		bucket.mu.Unlock()
+		if idle > rl.config.TTL {
 			rl.buckets.Delete(key)
 		}
 		return true
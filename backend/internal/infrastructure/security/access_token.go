@@ -0,0 +1,82 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessTokens issues and verifies short-lived bearer tokens that grant
+// access to a single password-protected poll. Tokens are stateless: the poll
+// ID and expiry are signed with HMAC-SHA256.
+type AccessTokens struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewAccessTokens creates a token issuer. With an empty secret a random one
+// is generated, so tokens do not survive a restart or work across instances;
+// the container only allows that in dev mode.
+func NewAccessTokens(secret string, ttl time.Duration) (*AccessTokens, error) {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate access token secret: %w", err)
+		}
+	}
+
+	return &AccessTokens{
+		secret: key,
+		ttl:    ttl,
+	}, nil
+}
+
+// Issue returns a token for the poll and the time it expires
+func (t *AccessTokens) Issue(pollID uuid.UUID) (string, time.Time, error) {
+	expiresAt := time.Now().Add(t.ttl).Truncate(time.Second)
+
+	payload := make([]byte, 24)
+	copy(payload, pollID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expiresAt.Unix()))
+
+	token := base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(t.sign(payload))
+	return token, expiresAt, nil
+}
+
+// Verify reports whether the token was issued for the poll and is unexpired
+func (t *AccessTokens) Verify(token string, pollID uuid.UUID) bool {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) != 24 {
+		return false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, t.sign(payload)) {
+		return false
+	}
+
+	if !hmac.Equal(payload[:16], pollID[:]) {
+		return false
+	}
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:])), 0)
+	return time.Now().Before(expiresAt)
+}
+
+func (t *AccessTokens) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
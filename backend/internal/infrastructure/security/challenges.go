@@ -0,0 +1,219 @@
+package security
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+// ChallengeOptions tunes proof-of-work challenges. Difficulty starts at
+// BaseDifficulty leading zero bits and gains a bit each time the poll's vote
+// rate over Window doubles past VotesPerStep, up to MaxDifficulty.
+type ChallengeOptions struct {
+	Secret         string
+	TTL            time.Duration
+	BaseDifficulty int
+	MaxDifficulty  int
+	Window         time.Duration
+	VotesPerStep   int
+}
+
+// Challenges issues and verifies signed proof-of-work challenges. A
+// challenge carries its poll, difficulty and expiry, so nothing is stored
+// until it is solved; solved challenges are remembered until they expire so
+// each one buys a single vote.
+type Challenges struct {
+	secret []byte
+	opts   ChallengeOptions
+	used   repository.ChallengeRepository
+
+	mu     sync.Mutex
+	pruned time.Time
+}
+
+// NewChallenges creates a challenge issuer that remembers solved challenges
+// in used. With an empty secret a random one is generated, so challenges
+// only verify on the instance that issued them; the container only allows
+// that in dev mode. With a nil used they are remembered in memory, which
+// only keeps them single use on one instance.
+func NewChallenges(opts ChallengeOptions, used repository.ChallengeRepository) (*Challenges, error) {
+	key := []byte(opts.Secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate challenge secret: %w", err)
+		}
+	}
+	if opts.BaseDifficulty < 0 || opts.MaxDifficulty < opts.BaseDifficulty || opts.MaxDifficulty > 255 {
+		return nil, fmt.Errorf("invalid challenge difficulty range %d-%d", opts.BaseDifficulty, opts.MaxDifficulty)
+	}
+	if opts.VotesPerStep < 1 {
+		opts.VotesPerStep = 1
+	}
+
+	if used == nil {
+		used = &memoryChallenges{used: make(map[string]time.Time)}
+	}
+
+	return &Challenges{
+		secret: key,
+		opts:   opts,
+		used:   used,
+	}, nil
+}
+
+// Window is how far back votes are counted when sizing a challenge
+func (c *Challenges) Window() time.Duration {
+	return c.opts.Window
+}
+
+// Difficulty returns the number of leading zero bits required given the
+// poll's recent vote count
+func (c *Challenges) Difficulty(recentVotes int) int {
+	steps := bits.Len(uint(recentVotes / c.opts.VotesPerStep))
+	return min(c.opts.BaseDifficulty+steps, c.opts.MaxDifficulty)
+}
+
+// Issue creates a challenge for the poll sized to its recent vote count
+func (c *Challenges) Issue(pollID uuid.UUID, recentVotes int) (*entity.Challenge, error) {
+	difficulty := c.Difficulty(recentVotes)
+	expiresAt := time.Now().Add(c.opts.TTL).Truncate(time.Second)
+
+	payload := make([]byte, 41)
+	copy(payload, pollID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expiresAt.Unix()))
+	payload[24] = byte(difficulty)
+	if _, err := rand.Read(payload[25:]); err != nil {
+		return nil, fmt.Errorf("failed to generate challenge nonce: %w", err)
+	}
+
+	return &entity.Challenge{
+		Token: base64.RawURLEncoding.EncodeToString(payload) + "." +
+			base64.RawURLEncoding.EncodeToString(c.sign(payload)),
+		Difficulty: difficulty,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// Verify checks that the solution solves an unexpired, unused challenge
+// issued for the poll, and marks the challenge as used
+func (c *Challenges) Verify(ctx context.Context, token, solution string, pollID uuid.UUID) error {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return entity.ErrInvalidProofOfWork
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil || len(payload) != 41 {
+		return entity.ErrInvalidProofOfWork
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return entity.ErrInvalidProofOfWork
+	}
+
+	if uuid.UUID(payload[:16]) != pollID {
+		return entity.ErrInvalidProofOfWork
+	}
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(payload[16:24])), 0)
+	if !time.Now().Before(expiresAt) {
+		return entity.ErrInvalidProofOfWork
+	}
+	if !Solves(token, solution, int(payload[24])) {
+		return entity.ErrInvalidProofOfWork
+	}
+
+	return c.consume(ctx, token, expiresAt)
+}
+
+// Solves reports whether SHA-256(token + ":" + solution) starts with at
+// least difficulty zero bits
+func Solves(token, solution string, difficulty int) bool {
+	hash := sha256.Sum256([]byte(token + ":" + solution))
+	zeros := 0
+	for _, b := range hash {
+		if b != 0 {
+			zeros += bits.LeadingZeros8(b)
+			break
+		}
+		zeros += 8
+	}
+	return zeros >= difficulty
+}
+
+// consume records the challenge as used, forgetting expired ones at most
+// once per TTL. Only a hash of the token is stored.
+func (c *Challenges) consume(ctx context.Context, token string, expiresAt time.Time) error {
+	now := time.Now()
+	c.mu.Lock()
+	prune := now.Sub(c.pruned) >= c.opts.TTL
+	if prune {
+		c.pruned = now
+	}
+	c.mu.Unlock()
+
+	if prune {
+		if _, err := c.used.PurgeExpired(ctx, now); err != nil {
+			return err
+		}
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	fresh, err := c.used.MarkUsed(ctx, hex.EncodeToString(hash[:]), expiresAt)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return entity.ErrInvalidProofOfWork
+	}
+	return nil
+}
+
+func (c *Challenges) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// memoryChallenges remembers used challenges in process memory
+type memoryChallenges struct {
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+func (m *memoryChallenges) MarkUsed(ctx context.Context, tokenHash string, expiresAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.used[tokenHash]; ok {
+		return false, nil
+	}
+	m.used[tokenHash] = expiresAt
+	return true, nil
+}
+
+func (m *memoryChallenges) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var purged int64
+	for t, exp := range m.used {
+		if !now.Before(exp) {
+			delete(m.used, t)
+			purged++
+		}
+	}
+	return purged, nil
+}
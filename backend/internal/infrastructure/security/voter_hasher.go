@@ -0,0 +1,136 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+)
+
+// LegacyKeyID marks votes hashed before keyed hashing was introduced: the IP
+// as a plain SHA-256 and the fingerprint exactly as the client sent it
+const LegacyKeyID = "legacy"
+
+// VoterHasher turns raw voter IPs and fingerprints into HMAC-SHA256 hashes
+// under the current key. Identifiers also carry the hashes under every
+// previous key, so a voter is still recognised while a rotation is rolling
+// out; a key can be dropped once no open poll has votes hashed with it.
+type VoterHasher struct {
+	currentID    string
+	keys         map[string][]byte
+	previousIDs  []string
+	acceptLegacy bool
+}
+
+// NewVoterHasher creates a hasher from id:secret pairs. currentID picks the
+// key new votes are hashed with and may be left empty when there is only one
+// key. With no keys a random one is generated, so duplicate detection does
+// not survive a restart; the container only allows that in dev mode.
+func NewVoterHasher(currentID string, secrets map[string]string, acceptLegacy bool) (*VoterHasher, error) {
+	keys := make(map[string][]byte, len(secrets))
+	for id, secret := range secrets {
+		if id == LegacyKeyID || secret == "" {
+			return nil, fmt.Errorf("invalid voter hash key %q", id)
+		}
+		keys[id] = []byte(secret)
+	}
+
+	if len(keys) == 0 {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate voter hash key: %w", err)
+		}
+		currentID = "ephemeral"
+		keys[currentID] = key
+	}
+
+	if currentID == "" {
+		if len(keys) > 1 {
+			return nil, fmt.Errorf("voter hash key ID is required when several keys are configured")
+		}
+		for id := range keys {
+			currentID = id
+		}
+	}
+	if _, ok := keys[currentID]; !ok {
+		return nil, fmt.Errorf("voter hash key %q is not configured", currentID)
+	}
+
+	previousIDs := make([]string, 0, len(keys)-1)
+	for id := range keys {
+		if id != currentID {
+			previousIDs = append(previousIDs, id)
+		}
+	}
+	sort.Strings(previousIDs)
+
+	return &VoterHasher{
+		currentID:    currentID,
+		keys:         keys,
+		previousIDs:  previousIDs,
+		acceptLegacy: acceptLegacy,
+	}, nil
+}
+
+// Identify hashes the voter under the current key. An empty fingerprint
+// stays empty so the identifier still fails validation. The subnet and user
+// agent are only hashed under the current key since anomaly detection looks
+// at recent votes alone.
+func (h *VoterHasher) Identify(ip, fingerprint, userAgent string) entity.VoteIdentifier {
+	identifier := h.identify(h.currentID, ip, fingerprint)
+	if subnet := subnetOf(ip); subnet != "" {
+		identifier.SubnetHash = h.hash(h.currentID, "subnet", subnet)
+	}
+	if userAgent != "" {
+		identifier.UserAgentHash = h.hash(h.currentID, "user_agent", userAgent)
+	}
+
+	for _, id := range h.previousIDs {
+		identifier.Rotated = append(identifier.Rotated, h.identify(id, ip, fingerprint))
+	}
+	if h.acceptLegacy {
+		legacy := sha256.Sum256([]byte(ip))
+		identifier.Rotated = append(identifier.Rotated, entity.VoteIdentifier{
+			KeyID:           LegacyKeyID,
+			IPHash:          hex.EncodeToString(legacy[:]),
+			FingerprintHash: fingerprint,
+		})
+	}
+
+	return identifier
+}
+
+func (h *VoterHasher) identify(keyID, ip, fingerprint string) entity.VoteIdentifier {
+	identifier := entity.NewVoteIdentifier(h.hash(keyID, "ip", ip), "")
+	if fingerprint != "" {
+		identifier.FingerprintHash = h.hash(keyID, "fingerprint", fingerprint)
+	}
+	identifier.KeyID = keyID
+	return identifier
+}
+
+// subnetOf returns the /24 of an IPv4 address or the /48 of an IPv6 one,
+// the blocks usually handed to a single customer
+func subnetOf(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String() + "/48"
+}
+
+// hash labels the value with its kind so an IP and a fingerprint with the
+// same text never share a hash
+func (h *VoterHasher) hash(keyID, kind, value string) string {
+	mac := hmac.New(sha256.New, h.keys[keyID])
+	mac.Write([]byte(kind + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
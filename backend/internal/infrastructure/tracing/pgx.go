@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryLogger returns a pgx logger that records a client span for each
+// query, exec and batch. pgx v4 only reports a query once it finishes, so
+// the span is back-dated by the duration pgx measured. Arguments are left
+// out because they carry voter hashes.
+func (t *Tracing) QueryLogger() pgx.Logger {
+	return pgx.LoggerFunc(t.logQuery)
+}
+
+func (t *Tracing) logQuery(ctx context.Context, _ pgx.LogLevel, msg string, data map[string]interface{}) {
+	elapsed, ok := data["time"].(time.Duration)
+	if !ok {
+		// Connection lifecycle messages have no duration to trace
+		return
+	}
+
+	end := time.Now()
+	_, span := t.tracer.Start(ctx, "db."+msg,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(end.Add(-elapsed)),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperation(msg),
+		),
+	)
+	if sql, ok := data["sql"].(string); ok {
+		span.SetAttributes(semconv.DBStatement(sql))
+	}
+	if err, ok := data["err"].(error); ok {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+}
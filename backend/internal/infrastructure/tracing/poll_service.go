@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PollIDKey is the span attribute carrying the poll a call acts on
+const PollIDKey = attribute.Key("poll.id")
+
+// tracedPollService records a span around every PollService call
+type tracedPollService struct {
+	next   service.PollService
+	tracer trace.Tracer
+}
+
+// TracePollService wraps svc so each call becomes a child span of the
+// request that made it
+func TracePollService(svc service.PollService, t *Tracing) service.PollService {
+	return &tracedPollService{
+		next:   svc,
+		tracer: t.tracer,
+	}
+}
+
+func (s *tracedPollService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "PollService."+method, trace.WithAttributes(attrs...))
+}
+
+// finish ends span, marking it failed if err is set
+func finish(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (s *tracedPollService) CreatePoll(ctx context.Context, question string, options []string, expiresAt *time.Time, settings entity.PollSettings) (*entity.Poll, error) {
+	ctx, span := s.start(ctx, "CreatePoll", attribute.Int("poll.options", len(options)))
+	poll, err := s.next.CreatePoll(ctx, question, options, expiresAt, settings)
+	if err == nil {
+		span.SetAttributes(PollIDKey.String(poll.ID.String()))
+	}
+	finish(span, err)
+	return poll, err
+}
+
+func (s *tracedPollService) GetPoll(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Poll, error) {
+	ctx, span := s.start(ctx, "GetPoll", PollIDKey.String(id.String()))
+	poll, err := s.next.GetPoll(ctx, id, includeDeleted)
+	finish(span, err)
+	return poll, err
+}
+
+func (s *tracedPollService) Vote(ctx context.Context, pollID, optionID uuid.UUID, identifier entity.VoteIdentifier) error {
+	ctx, span := s.start(ctx, "Vote",
+		PollIDKey.String(pollID.String()),
+		attribute.String("poll.option_id", optionID.String()),
+	)
+	err := s.next.Vote(ctx, pollID, optionID, identifier)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedPollService) ListPolls(ctx context.Context, filter repository.PollFilter, page repository.PageRequest) (*repository.PollPage, error) {
+	ctx, span := s.start(ctx, "ListPolls")
+	result, err := s.next.ListPolls(ctx, filter, page)
+	finish(span, err)
+	return result, err
+}
+
+func (s *tracedPollService) DeletePoll(ctx context.Context, id uuid.UUID) error {
+	ctx, span := s.start(ctx, "DeletePoll", PollIDKey.String(id.String()))
+	err := s.next.DeletePoll(ctx, id)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedPollService) ArchivePoll(ctx context.Context, id uuid.UUID) error {
+	ctx, span := s.start(ctx, "ArchivePoll", PollIDKey.String(id.String()))
+	err := s.next.ArchivePoll(ctx, id)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedPollService) RestorePoll(ctx context.Context, id uuid.UUID) error {
+	ctx, span := s.start(ctx, "RestorePoll", PollIDKey.String(id.String()))
+	err := s.next.RestorePoll(ctx, id)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedPollService) PurgeDeletedPolls(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := s.start(ctx, "PurgeDeletedPolls")
+	purged, err := s.next.PurgeDeletedPolls(ctx, retention)
+	span.SetAttributes(attribute.Int64("poll.purged", purged))
+	finish(span, err)
+	return purged, err
+}
+
+func (s *tracedPollService) ExpireLegacyVoterHashes(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := s.start(ctx, "ExpireLegacyVoterHashes")
+	expired, err := s.next.ExpireLegacyVoterHashes(ctx, retention)
+	span.SetAttributes(attribute.Int64("vote.expired_hashes", expired))
+	finish(span, err)
+	return expired, err
+}
+
+func (s *tracedPollService) UpdatePoll(ctx context.Context, id uuid.UUID, question string, isActive bool, expiresAt *time.Time) error {
+	ctx, span := s.start(ctx, "UpdatePoll", PollIDKey.String(id.String()))
+	err := s.next.UpdatePoll(ctx, id, question, isActive, expiresAt)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedPollService) GetPollStats(ctx context.Context, id uuid.UUID) (*entity.PollStats, error) {
+	ctx, span := s.start(ctx, "GetPollStats", PollIDKey.String(id.String()))
+	stats, err := s.next.GetPollStats(ctx, id)
+	finish(span, err)
+	return stats, err
+}
+
+func (s *tracedPollService) CreateInvite(ctx context.Context, pollID uuid.UUID, maxUses int, expiresAt *time.Time) (*entity.Invite, error) {
+	ctx, span := s.start(ctx, "CreateInvite", PollIDKey.String(pollID.String()))
+	invite, err := s.next.CreateInvite(ctx, pollID, maxUses, expiresAt)
+	finish(span, err)
+	return invite, err
+}
+
+func (s *tracedPollService) RevokeInvite(ctx context.Context, pollID, inviteID uuid.UUID) error {
+	ctx, span := s.start(ctx, "RevokeInvite", PollIDKey.String(pollID.String()))
+	err := s.next.RevokeInvite(ctx, pollID, inviteID)
+	finish(span, err)
+	return err
+}
+
+func (s *tracedPollService) UnlockPoll(ctx context.Context, pollID uuid.UUID, password string) (string, time.Time, error) {
+	ctx, span := s.start(ctx, "UnlockPoll", PollIDKey.String(pollID.String()))
+	token, expiresAt, err := s.next.UnlockPoll(ctx, pollID, password)
+	finish(span, err)
+	return token, expiresAt, err
+}
+
+func (s *tracedPollService) RecentVotes(ctx context.Context, pollID uuid.UUID, since time.Time) (int, error) {
+	ctx, span := s.start(ctx, "RecentVotes", PollIDKey.String(pollID.String()))
+	count, err := s.next.RecentVotes(ctx, pollID, since)
+	finish(span, err)
+	return count, err
+}
+
+func (s *tracedPollService) AuditLog(ctx context.Context, pollID uuid.UUID) ([]entity.AuditEvent, error) {
+	ctx, span := s.start(ctx, "AuditLog", PollIDKey.String(pollID.String()))
+	events, err := s.next.AuditLog(ctx, pollID)
+	finish(span, err)
+	return events, err
+}
@@ -0,0 +1,61 @@
+// Package docs serves the API's OpenAPI document and a page for browsing it.
+//
+// @title Cactro Polls API
+// @version 1.0
+// @description Create polls, vote on them and follow the results. Routes are served under /api/v1, where success bodies are wrapped in the Response envelope and errors are RFC 7807 problem details. The unversioned /api serves the same routes with the original response shapes.
+package docs
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate go run ../../../../cmd/openapi -root ../../../.. -out openapi.json
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed redoc.html
+var page []byte
+
+// pageCSP only lets the docs page run the pinned Redoc bundle and fetch the
+// document from this server
+const pageCSP = "default-src 'none'; " +
+	"script-src https://cdn.redoc.ly/redoc/v2.1.5/bundles/redoc.standalone.js; " +
+	"style-src 'unsafe-inline'; " +
+	"img-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"worker-src blob:; " +
+	"base-uri 'none'; " +
+	"form-action 'none'; " +
+	"frame-ancestors 'none'"
+
+// JSON returns the OpenAPI document
+func JSON() []byte {
+	return spec
+}
+
+// Spec godoc
+// @Summary Get the OpenAPI document
+// @Description This document, describing every route and model of the API
+// @Tags docs
+// @Produce json
+// @Success 200 {object} object
+// @Router /openapi.json [get]
+func Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+}
+
+// UI godoc
+// @Summary Browse the API documentation
+// @Description A Redoc page rendering the OpenAPI document
+// @Tags docs
+// @Produce html
+// @Success 200
+// @Router /docs [get]
+func UI(c *gin.Context) {
+	c.Header("Content-Security-Policy", pageCSP)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelController is satisfied by zap.AtomicLevel
+type LevelController interface {
+	Level() zapcore.Level
+	SetLevel(level zapcore.Level)
+}
+
+type AdminHandler struct {
+	logLevel LevelController
+}
+
+func NewAdminHandler(logLevel LevelController) *AdminHandler {
+	return &AdminHandler{
+		logLevel: logLevel,
+	}
+}
+
+// GetLogLevel godoc
+// @Summary Get the log level
+// @Description The minimum level currently logged. Requires an admin token.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} LogLevelResponse
+// @Failure 401,403 {object} ErrorResponse
+// @Security AdminToken
+// @Router /admin/log-level [get]
+func (h *AdminHandler) GetLogLevel(c *gin.Context) {
+	respond(c, http.StatusOK, LogLevelResponse{
+		Level: h.logLevel.Level().String(),
+	})
+}
+
+// SetLogLevel godoc
+// @Summary Change the log level
+// @Description Takes effect immediately for every logger and lasts until the next restart. Requires an admin token.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body LogLevelRequest true "New level"
+// @Success 200 {object} LogLevelResponse
+// @Failure 400,401,403 {object} ErrorResponse
+// @Security AdminToken
+// @Router /admin/log-level [put]
+func (h *AdminHandler) SetLogLevel(c *gin.Context) {
+	var req LogLevelRequest
+	if err := validateRequest(c, &req); err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+	h.logLevel.SetLevel(level)
+
+	respond(c, http.StatusOK, LogLevelResponse{
+		Level: level.String(),
+	})
+}
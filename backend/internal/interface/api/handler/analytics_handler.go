@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	analyticsService service.AnalyticsService
+	voters           VoterHasher
+}
+
+func NewAnalyticsHandler(analyticsService service.AnalyticsService, voters VoterHasher) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: analyticsService,
+		voters:           voters,
+	}
+}
+
+// TrendingPolls godoc
+// @Summary List trending polls
+// @Description Active polls ranked by time-decayed vote velocity over a sliding window
+// @Tags analytics
+// @Produce json
+// @Param limit query integer false "Maximum number of polls (default 10, capped at TRENDING_MAX_RESULTS)"
+// @Success 200 {object} TrendingResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /polls/trending [get]
+func (h *AnalyticsHandler) TrendingPolls(c *gin.Context) {
+	query := newQueryParams(c)
+	limit := query.integer("limit", 10, 1, math.MaxInt32)
+	if err := query.err(); err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	trending, err := h.analyticsService.TrendingPolls(requestContext(c, h.voters), limit)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, toTrendingResponse(trending))
+}
+
+// PollTimeline godoc
+// @Summary Get a poll's vote timeline
+// @Description Votes per option per time bucket, with cumulative totals and lead changes
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Poll ID"
+// @Param bucket query string false "Bucket width: 1m, 1h, 1d or 1w (default 1h)"
+// @Success 200 {object} TimelineResponse
+// @Failure 400,404 {object} ErrorResponse
+// @Router /polls/{id}/timeline [get]
+func (h *AnalyticsHandler) PollTimeline(c *gin.Context) {
+	pollID, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	interval, err := entity.ParseTimelineInterval(c.Query("bucket"))
+	if err != nil {
+		respondWithBadRequest(c, entity.ErrValidation.WithFields(entity.FieldError{
+			Field:   "bucket",
+			Message: "Must be one of 1m 1h 1d 1w",
+		}))
+		return
+	}
+
+	timeline, err := h.analyticsService.PollTimeline(requestContext(c, h.voters), pollID, interval)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, toTimelineResponse(timeline))
+}
+
+// CrossTab godoc
+// @Summary Cross-tabulate two polls
+// @Description Contingency table of answers given by the same voters to two polls, with a chi-square test of independence. Small cells are suppressed, and the test is null when any cell is suppressed since it would reveal them.
+// @Description Voters are matched by their hashed IP and fingerprint under the same hashing key, so votes cast before a key rotation are not matched with later ones.
+// @Tags analytics
+// @Produce json
+// @Param id path string true "Row poll ID"
+// @Param with query string true "Column poll ID"
+// @Success 200 {object} CrossTabResponse
+// @Failure 400,404 {object} ErrorResponse
+// @Router /polls/{id}/crosstab [get]
+func (h *AnalyticsHandler) CrossTab(c *gin.Context) {
+	rowPollID, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	columnPollID, err := validateID("with", c.Query("with"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	crossTab, err := h.analyticsService.CrossTab(requestContext(c, h.voters), rowPollID, columnPollID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, toCrossTabResponse(crossTab))
+}
@@ -9,20 +9,53 @@ import (
 
 // Request models
 type CreatePollRequest struct {
-	Question  string    `json:"question" binding:"required,min=5,max=500"`
-	Options   []string  `json:"options" binding:"required,min=2,dive,required"`
-	ExpiresAt time.Time `json:"expires_at,omitempty"`
+	Question string   `json:"question" binding:"required,valid_question"`
+	Options  []string `json:"options" binding:"required,min=2,max=20,dive,valid_option"`
+	// ExpiresAt is optional; polls without it stay open until closed
+	ExpiresAt *time.Time `json:"expires_at,omitempty" binding:"omitempty,future_time"`
+
+	StartsAt          *time.Time        `json:"starts_at,omitempty"`
+	ResultsVisibility string            `json:"results_visibility,omitempty" binding:"omitempty,oneof=public after_vote after_close creator_only"`
+	Visibility        string            `json:"visibility,omitempty" binding:"omitempty,oneof=public unlisted private"`
+	Allowlist         *AllowlistRequest `json:"allowlist,omitempty"`
+	Password          string            `json:"password,omitempty" binding:"omitempty,min=4,max=72"`
+	DedupPolicy       string            `json:"dedup_policy,omitempty" binding:"omitempty,oneof=fingerprint ip either both user none"`
+	ProofOfWork       bool              `json:"proof_of_work,omitempty"`
+}
+
+type AllowlistRequest struct {
+	Emails  []string `json:"emails,omitempty" binding:"omitempty,dive,email"`
+	UserIDs []string `json:"user_ids,omitempty" binding:"omitempty,dive,required"`
+}
+
+type UnlockPollRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type CreateInviteRequest struct {
+	MaxUses   int        `json:"max_uses,omitempty" binding:"omitempty,min=1,max=100000"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" binding:"omitempty,future_time"`
 }
 
+// UpdatePollRequest replaces a poll's editable fields; leaving out
+// expires_at removes the expiry
 type UpdatePollRequest struct {
-	Question  string     `json:"question" binding:"omitempty,min=5,max=500"`
-	IsActive  *bool      `json:"is_active,omitempty"`
-	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Question  string     `json:"question" binding:"required,valid_question"`
+	IsActive  *bool      `json:"is_active" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" binding:"omitempty,future_time"`
 }
 
 type VoteRequest struct {
 	OptionID        uuid.UUID `json:"option_id" binding:"required"`
 	FingerprintHash string    `json:"fingerprint_hash" binding:"required,min=32"`
+	InviteCode      string    `json:"invite_code,omitempty"`
+	// Challenge and Solution are required by polls with proof of work
+	Challenge string `json:"challenge,omitempty"`
+	Solution  string `json:"solution,omitempty" binding:"required_with=Challenge,max=128"`
+}
+
+type LogLevelRequest struct {
+	Level string `json:"level" binding:"required,oneof=debug info warn error"`
 }
 
 // Response models
@@ -34,25 +67,178 @@ type PollResponse struct {
 	ExpiresAt *time.Time       `json:"expires_at,omitempty"`
 	IsActive  bool             `json:"is_active"`
 	UpdatedAt time.Time        `json:"updated_at"`
+
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	StartsAt   *time.Time `json:"starts_at,omitempty"`
+	Status     string     `json:"status"`
+
+	ResultsVisibility string `json:"results_visibility"`
+	ResultsHidden     bool   `json:"results_hidden,omitempty"`
+	Visibility        string `json:"visibility"`
+	PasswordProtected bool   `json:"password_protected"`
+	DedupPolicy       string `json:"dedup_policy"`
+	ProofOfWork       bool   `json:"proof_of_work"`
+	// OwnerToken is only returned when the poll is created
+	OwnerToken string `json:"owner_token,omitempty"`
 }
 
 type OptionResponse struct {
-	ID         uuid.UUID `json:"id"`
-	OptionText string    `json:"option_text"`
-	VoteCount  int       `json:"vote_count"`
-	Percentage float64   `json:"percentage"`
+	ID                 uuid.UUID                   `json:"id"`
+	OptionText         string                      `json:"option_text"`
+	VoteCount          int                         `json:"vote_count"`
+	Percentage         float64                     `json:"percentage"`
+	ConfidenceInterval *ConfidenceIntervalResponse `json:"confidence_interval,omitempty"`
+}
+
+type ConfidenceIntervalResponse struct {
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+type LeaderComparisonResponse struct {
+	LeaderID    uuid.UUID `json:"leader_id"`
+	RunnerUpID  uuid.UUID `json:"runner_up_id"`
+	Margin      float64   `json:"margin"`
+	ZScore      float64   `json:"z_score"`
+	PValue      float64   `json:"p_value"`
+	Significant bool      `json:"significant"`
 }
 
 type PollStatsResponse struct {
-	TotalVotes int              `json:"total_votes"`
-	Options    []OptionResponse `json:"options"`
+	TotalVotes      int                       `json:"total_votes"`
+	Options         []OptionResponse          `json:"options"`
+	ConfidenceLevel float64                   `json:"confidence_level"`
+	Leader          *LeaderComparisonResponse `json:"leader,omitempty"`
+	TooCloseToCall  bool                      `json:"too_close_to_call"`
+	ResultsHidden   bool                      `json:"results_hidden,omitempty"`
 }
 
 type PollListResponse struct {
 	Polls      []PollResponse `json:"polls"`
-	Page       int            `json:"page"`
+	Page       int            `json:"page,omitempty"`
 	PageSize   int            `json:"page_size"`
-	TotalPolls int            `json:"total_polls"`
+	TotalPolls *int           `json:"total_polls,omitempty"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	PrevCursor string         `json:"prev_cursor,omitempty"`
+}
+
+type TrendingPollResponse struct {
+	Poll         PollResponse `json:"poll"`
+	RecentVotes  int          `json:"recent_votes"`
+	VotesPerHour float64      `json:"votes_per_hour"`
+	Score        float64      `json:"score"`
+}
+
+type TrendingResponse struct {
+	Polls       []TrendingPollResponse `json:"polls"`
+	Window      string                 `json:"window"`
+	GeneratedAt time.Time              `json:"generated_at"`
+}
+
+type TimelineBucketResponse struct {
+	Start      time.Time         `json:"start"`
+	Votes      map[uuid.UUID]int `json:"votes"`
+	Cumulative map[uuid.UUID]int `json:"cumulative"`
+	Leader     *uuid.UUID        `json:"leader,omitempty"`
+}
+
+type LeadChangeResponse struct {
+	OptionID uuid.UUID `json:"option_id"`
+	At       time.Time `json:"at"`
+}
+
+type TimelineResponse struct {
+	PollID      uuid.UUID                `json:"poll_id"`
+	Bucket      string                   `json:"bucket"`
+	Buckets     []TimelineBucketResponse `json:"buckets"`
+	LeadChanges []LeadChangeResponse     `json:"lead_changes"`
+}
+
+type CrossTabCellResponse struct {
+	RowOptionID    uuid.UUID `json:"row_option_id"`
+	ColumnOptionID uuid.UUID `json:"column_option_id"`
+	Votes          *int      `json:"votes"`
+	Percentage     *float64  `json:"percentage"`
+	Suppressed     bool      `json:"suppressed"`
+}
+
+type CrossTabMarginResponse struct {
+	OptionID   uuid.UUID `json:"option_id"`
+	Votes      int       `json:"votes"`
+	Percentage float64   `json:"percentage"`
+}
+
+type CrossTabResponse struct {
+	RowPollID        uuid.UUID                `json:"row_poll_id"`
+	ColumnPollID     uuid.UUID                `json:"column_poll_id"`
+	TotalVoters      int                      `json:"total_voters"`
+	MinCellSize      int                      `json:"min_cell_size"`
+	Cells            []CrossTabCellResponse   `json:"cells"`
+	Rows             []CrossTabMarginResponse `json:"rows"`
+	Columns          []CrossTabMarginResponse `json:"columns"`
+	ChiSquare        *float64                 `json:"chi_square"`
+	DegreesOfFreedom *int                     `json:"degrees_of_freedom"`
+	PValue           *float64                 `json:"p_value"`
+}
+
+type AccessTokenResponse struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type ChallengeResponse struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// Algorithm describes what counts as a solution
+	Algorithm string `json:"algorithm"`
+}
+
+type QuarantinedVoteResponse struct {
+	VoteID        uuid.UUID  `json:"vote_id"`
+	OptionID      uuid.UUID  `json:"option_id"`
+	Status        string     `json:"status"`
+	Reasons       []string   `json:"reasons"`
+	Score         int        `json:"score"`
+	CreatedAt     time.Time  `json:"created_at"`
+	QuarantinedAt time.Time  `json:"quarantined_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+}
+
+type InviteResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	PollID    uuid.UUID  `json:"poll_id"`
+	Code      string     `json:"code,omitempty"`
+	MaxUses   int        `json:"max_uses"`
+	Uses      int        `json:"uses"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type AuditLogResponse struct {
+	PollID uuid.UUID            `json:"poll_id"`
+	Events []AuditEventResponse `json:"events"`
+}
+
+type AuditEventResponse struct {
+	ID           uuid.UUID             `json:"id"`
+	Action       string                `json:"action"`
+	Actor        string                `json:"actor"`
+	RequestID    string                `json:"request_id,omitempty"`
+	ClientIPHash string                `json:"client_ip_hash,omitempty"`
+	Changes      []FieldChangeResponse `json:"changes"`
+	CreatedAt    time.Time             `json:"created_at"`
+}
+
+type FieldChangeResponse struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
 }
 
 // Converters
@@ -75,5 +261,175 @@ func toPollResponse(poll *entity.Poll) PollResponse {
 		ExpiresAt: poll.ExpiresAt,
 		IsActive:  poll.IsActive,
 		UpdatedAt: poll.UpdatedAt,
+
+		ArchivedAt: poll.ArchivedAt,
+		DeletedAt:  poll.DeletedAt,
+		StartsAt:   poll.StartsAt,
+		Status:     string(poll.Status(time.Now())),
+
+		ResultsVisibility: string(poll.ResultsVisibility),
+		ResultsHidden:     poll.ResultsHidden,
+		Visibility:        string(poll.Visibility),
+		PasswordProtected: poll.IsPasswordProtected(),
+		DedupPolicy:       string(poll.DedupPolicy),
+		ProofOfWork:       poll.RequiresProofOfWork(),
+		OwnerToken:        poll.OwnerToken,
+	}
+}
+
+func toTrendingResponse(trending *entity.TrendingPolls) TrendingResponse {
+	polls := make([]TrendingPollResponse, len(trending.Polls))
+	for i, tp := range trending.Polls {
+		polls[i] = TrendingPollResponse{
+			Poll:         toPollResponse(tp.Poll),
+			RecentVotes:  tp.RecentVotes,
+			VotesPerHour: roundPercentage(tp.VotesPerHour),
+			Score:        roundPercentage(tp.Score),
+		}
+	}
+
+	return TrendingResponse{
+		Polls:       polls,
+		Window:      trending.Window.String(),
+		GeneratedAt: trending.GeneratedAt,
+	}
+}
+
+func toTimelineResponse(timeline *entity.Timeline) TimelineResponse {
+	buckets := make([]TimelineBucketResponse, len(timeline.Buckets))
+	for i, b := range timeline.Buckets {
+		buckets[i] = TimelineBucketResponse{
+			Start:      b.Start,
+			Votes:      b.Votes,
+			Cumulative: b.Cumulative,
+			Leader:     b.Leader,
+		}
+	}
+
+	changes := make([]LeadChangeResponse, len(timeline.LeadChanges))
+	for i, lc := range timeline.LeadChanges {
+		changes[i] = LeadChangeResponse{
+			OptionID: lc.OptionID,
+			At:       lc.At,
+		}
+	}
+
+	return TimelineResponse{
+		PollID:      timeline.PollID,
+		Bucket:      string(timeline.Interval),
+		Buckets:     buckets,
+		LeadChanges: changes,
+	}
+}
+
+func toCrossTabResponse(crossTab *entity.CrossTab) CrossTabResponse {
+	cells := make([]CrossTabCellResponse, len(crossTab.Cells))
+	for i, cell := range crossTab.Cells {
+		cells[i] = CrossTabCellResponse{
+			RowOptionID:    cell.RowOptionID,
+			ColumnOptionID: cell.ColumnOptionID,
+			Suppressed:     cell.Suppressed,
+		}
+		if !cell.Suppressed {
+			votes, percentage := cell.Votes, roundPercentage(cell.Percentage)
+			cells[i].Votes = &votes
+			cells[i].Percentage = &percentage
+		}
+	}
+
+	response := CrossTabResponse{
+		RowPollID:    crossTab.RowPollID,
+		ColumnPollID: crossTab.ColumnPollID,
+		TotalVoters:  crossTab.TotalVoters,
+		MinCellSize:  crossTab.MinCellSize,
+		Cells:        cells,
+		Rows:         toCrossTabMargins(crossTab.Rows),
+		Columns:      toCrossTabMargins(crossTab.Columns),
+	}
+	if !crossTab.TestSuppressed {
+		chiSquare, df, pValue := crossTab.ChiSquare, crossTab.DegreesOfFreedom, crossTab.PValue
+		response.ChiSquare = &chiSquare
+		response.DegreesOfFreedom = &df
+		response.PValue = &pValue
+	}
+	return response
+}
+
+func toCrossTabMargins(margins []entity.CrossTabMargin) []CrossTabMarginResponse {
+	result := make([]CrossTabMarginResponse, len(margins))
+	for i, m := range margins {
+		result[i] = CrossTabMarginResponse{
+			OptionID:   m.OptionID,
+			Votes:      m.Votes,
+			Percentage: roundPercentage(m.Percentage),
+		}
+	}
+	return result
+}
+
+func toChallengeResponse(challenge *entity.Challenge) ChallengeResponse {
+	return ChallengeResponse{
+		Challenge:  challenge.Token,
+		Difficulty: challenge.Difficulty,
+		ExpiresAt:  challenge.ExpiresAt,
+		Algorithm:  "sha256(challenge + \":\" + solution) has difficulty leading zero bits",
+	}
+}
+
+func toQuarantinedVoteResponse(vote *entity.QuarantinedVote) QuarantinedVoteResponse {
+	reasons := make([]string, len(vote.Reasons))
+	for i, reason := range vote.Reasons {
+		reasons[i] = string(reason)
+	}
+
+	return QuarantinedVoteResponse{
+		VoteID:        vote.VoteID,
+		OptionID:      vote.OptionID,
+		Status:        string(vote.Status),
+		Reasons:       reasons,
+		Score:         vote.Score,
+		CreatedAt:     vote.CreatedAt,
+		QuarantinedAt: vote.QuarantinedAt,
+		ReviewedAt:    vote.ReviewedAt,
+	}
+}
+
+func toInviteResponse(invite *entity.Invite) InviteResponse {
+	return InviteResponse{
+		ID:        invite.ID,
+		PollID:    invite.PollID,
+		Code:      invite.Code,
+		MaxUses:   invite.MaxUses,
+		Uses:      invite.Uses,
+		ExpiresAt: invite.ExpiresAt,
+		CreatedAt: invite.CreatedAt,
+	}
+}
+
+// toAuditLogResponse converts audit events, leaving out client IP hashes
+// unless the caller is an admin
+func toAuditLogResponse(pollID uuid.UUID, events []entity.AuditEvent, admin bool) AuditLogResponse {
+	response := AuditLogResponse{
+		PollID: pollID,
+		Events: make([]AuditEventResponse, len(events)),
+	}
+	for i, event := range events {
+		changes := make([]FieldChangeResponse, len(event.Changes))
+		for j, change := range event.Changes {
+			changes[j] = FieldChangeResponse{Field: change.Field, Before: change.Before, After: change.After}
+		}
+
+		response.Events[i] = AuditEventResponse{
+			ID:        event.ID,
+			Action:    string(event.Action),
+			Actor:     event.Actor,
+			RequestID: event.RequestID,
+			Changes:   changes,
+			CreatedAt: event.CreatedAt,
+		}
+		if admin {
+			response.Events[i].ClientIPHash = event.ClientIPHash
+		}
 	}
+	return response
 }
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationHandler struct {
+	anomalyService service.AnomalyService
+}
+
+func NewModerationHandler(anomalyService service.AnomalyService) *ModerationHandler {
+	return &ModerationHandler{
+		anomalyService: anomalyService,
+	}
+}
+
+// ListQuarantined godoc
+// @Summary List a poll's quarantined votes
+// @Description Votes flagged by anomaly detection, pending review first. Pending and rejected votes are left out of results. Requires an admin token.
+// @Tags moderation
+// @Produce json
+// @Param id path string true "Poll ID"
+// @Success 200 {array} QuarantinedVoteResponse
+// @Failure 400,401,403 {object} ErrorResponse
+// @Security AdminToken
+// @Router /polls/{id}/quarantine [get]
+func (h *ModerationHandler) ListQuarantined(c *gin.Context) {
+	pollID, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	votes, err := h.anomalyService.ListQuarantined(c.Request.Context(), pollID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	response := make([]QuarantinedVoteResponse, len(votes))
+	for i := range votes {
+		response[i] = toQuarantinedVoteResponse(&votes[i])
+	}
+
+	respond(c, http.StatusOK, response)
+}
+
+// ApproveVote godoc
+// @Summary Approve a quarantined vote
+// @Description Release a quarantined vote so it counts towards results. Requires an admin token.
+// @Tags moderation
+// @Param id path string true "Poll ID"
+// @Param vote_id path string true "Vote ID"
+// @Success 204
+// @Failure 400,401,403,404 {object} ErrorResponse
+// @Security AdminToken
+// @Router /polls/{id}/votes/{vote_id}/approve [post]
+func (h *ModerationHandler) ApproveVote(c *gin.Context) {
+	h.reviewVote(c, true)
+}
+
+// RejectVote godoc
+// @Summary Reject a quarantined vote
+// @Description Confirm a quarantined vote as bad so it never counts towards results. Requires an admin token.
+// @Tags moderation
+// @Param id path string true "Poll ID"
+// @Param vote_id path string true "Vote ID"
+// @Success 204
+// @Failure 400,401,403,404 {object} ErrorResponse
+// @Security AdminToken
+// @Router /polls/{id}/votes/{vote_id}/reject [post]
+func (h *ModerationHandler) RejectVote(c *gin.Context) {
+	h.reviewVote(c, false)
+}
+
+func (h *ModerationHandler) reviewVote(c *gin.Context, approve bool) {
+	pollID, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+	voteID, err := validateID("vote_id", c.Param("vote_id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	if err := h.anomalyService.ReviewVote(c.Request.Context(), pollID, voteID, approve); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
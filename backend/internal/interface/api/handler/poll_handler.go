@@ -1,23 +1,27 @@
 package handler
 
 import (
-	"errors"
+	"math"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
 	"github.com/Sparker0i/cactro-polls/internal/domain/service"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 type PollHandler struct {
 	pollService service.PollService
+	voters      VoterHasher
+	challenges  ChallengeIssuer
 }
 
-func NewPollHandler(pollService service.PollService) *PollHandler {
+func NewPollHandler(pollService service.PollService, voters VoterHasher, challenges ChallengeIssuer) *PollHandler {
 	return &PollHandler{
 		pollService: pollService,
+		voters:      voters,
+		challenges:  challenges,
 	}
 }
 
@@ -33,18 +37,36 @@ func NewPollHandler(pollService service.PollService) *PollHandler {
 // @Router /polls [post]
 func (h *PollHandler) CreatePoll(c *gin.Context) {
 	var req CreatePollRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondWithError(c, http.StatusBadRequest, err)
+	if err := validateRequest(c, &req); err != nil {
+		respondWithBadRequest(c, err)
 		return
 	}
 
-	poll, err := h.pollService.CreatePoll(c.Request.Context(), req.Question, req.Options, &req.ExpiresAt)
+	settings := entity.PollSettings{
+		StartsAt:          req.StartsAt,
+		ResultsVisibility: entity.ResultsVisibility(req.ResultsVisibility),
+		Visibility:        entity.PollVisibility(req.Visibility),
+		Password:          req.Password,
+		DedupPolicy:       entity.DedupPolicy(req.DedupPolicy),
+		ProofOfWork:       req.ProofOfWork,
+	}
+
+	if req.Allowlist != nil {
+		allowlist, err := toAllowlist(req.Allowlist)
+		if err != nil {
+			respondWithBadRequest(c, err)
+			return
+		}
+		settings.Allowlist = allowlist
+	}
+
+	poll, err := h.pollService.CreatePoll(requestContext(c, h.voters), req.Question, req.Options, req.ExpiresAt, settings)
 	if err != nil {
-		handleServiceError(c, err)
+		respondWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, toPollResponse(poll))
+	respond(c, http.StatusCreated, toPollResponse(poll))
 }
 
 // GetPoll godoc
@@ -53,23 +75,24 @@ func (h *PollHandler) CreatePoll(c *gin.Context) {
 // @Tags polls
 // @Produce json
 // @Param id path string true "Poll ID"
+// @Param include_deleted query boolean false "Include soft-deleted polls (admin only)"
 // @Success 200 {object} PollResponse
 // @Failure 404 {object} ErrorResponse
 // @Router /polls/{id} [get]
 func (h *PollHandler) GetPoll(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+	id, err := validateID("id", c.Param("id"))
 	if err != nil {
-		respondWithError(c, http.StatusBadRequest, err)
+		respondWithBadRequest(c, err)
 		return
 	}
 
-	poll, err := h.pollService.GetPoll(c.Request.Context(), id)
+	poll, err := h.pollService.GetPoll(requestContext(c, h.voters), id, includeDeleted(c))
 	if err != nil {
-		handleServiceError(c, err)
+		respondWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, toPollResponse(poll))
+	respond(c, http.StatusOK, toPollResponse(poll))
 }
 
 // Vote godoc
@@ -84,83 +107,392 @@ func (h *PollHandler) GetPoll(c *gin.Context) {
 // @Failure 400,404,409 {object} ErrorResponse
 // @Router /polls/{id}/vote [post]
 func (h *PollHandler) Vote(c *gin.Context) {
-	pollID, err := uuid.Parse(c.Param("id"))
+	pollID, err := validateID("id", c.Param("id"))
 	if err != nil {
-		respondWithError(c, http.StatusBadRequest, err)
+		respondWithBadRequest(c, err)
 		return
 	}
 
 	var req VoteRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		respondWithError(c, http.StatusBadRequest, err)
+	if err := validateRequest(c, &req); err != nil {
+		respondWithBadRequest(c, err)
 		return
 	}
 
-	identifier := entity.VoteIdentifier{
-		IPHash:          hashIP(c.ClientIP()),
-		FingerprintHash: req.FingerprintHash,
+	identifier := h.voters.Identify(c.ClientIP(), req.FingerprintHash, c.Request.UserAgent())
+	identifier.UserID = c.GetString("user_id")
+
+	// The voter is identified by the body rather than headers on this route
+	actor := entity.ActorFromContext(requestContext(c, h.voters))
+	actor.Identifier = identifier
+	if req.InviteCode != "" {
+		actor.InviteCode = req.InviteCode
 	}
+	if req.Challenge != "" {
+		if err := h.challenges.Verify(c.Request.Context(), req.Challenge, req.Solution, pollID); err != nil {
+			respondWithError(c, err)
+			return
+		}
+		actor.ProofOfWork = true
+	}
+	ctx := entity.ContextWithActor(c.Request.Context(), actor)
 
-	err = h.pollService.Vote(c.Request.Context(), pollID, req.OptionID, identifier)
+	err = h.pollService.Vote(ctx, pollID, req.OptionID, identifier)
 	if err != nil {
-		handleServiceError(c, err)
+		respondWithError(c, err)
 		return
 	}
 
 	// Get updated stats
-	stats, err := h.pollService.GetPollStats(c.Request.Context(), pollID)
+	stats, err := h.pollService.GetPollStats(ctx, pollID)
 	if err != nil {
-		handleServiceError(c, err)
+		respondWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, toPollStatsResponse(stats))
+	respond(c, http.StatusOK, toPollStatsResponse(stats))
 }
 
 // ListPolls godoc
 // @Summary List all polls
-// @Description Get a paginated list of polls
+// @Description Get a filtered, sorted list of polls, newest first by default. Pass the
+// @Description returned next_cursor or prev_cursor as cursor to page; page/limit is kept
+// @Description for older clients.
 // @Tags polls
 // @Produce json
-// @Param page query integer false "Page number"
-// @Param limit query integer false "Items per page"
+// @Param status query string false "active, expired or scheduled"
+// @Param created_after query string false "RFC3339 lower bound on creation time"
+// @Param created_before query string false "RFC3339 upper bound on creation time"
+// @Param expires_after query string false "RFC3339 lower bound on expiry time"
+// @Param expires_before query string false "RFC3339 upper bound on expiry time"
+// @Param q query string false "Full-text search over questions and options"
+// @Param sort query string false "created, votes or expiry; polls whose results are hidden sort as if they had no votes"
+// @Param order query string false "asc or desc (default desc)"
+// @Param cursor query string false "Opaque cursor from a previous response"
+// @Param page query integer false "Page number (ignored when cursor is set)"
+// @Param limit query integer false "Items per page, 1 to 100 (default 10)"
+// @Param include_total query boolean false "Include the total number of polls"
+// @Param include_deleted query boolean false "Include soft-deleted polls (admin only)"
 // @Success 200 {object} PollListResponse
+// @Failure 400 {object} ErrorResponse
 // @Router /polls [get]
 func (h *PollHandler) ListPolls(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	query := newQueryParams(c)
+	page := query.integer("page", 1, 1, math.MaxInt32)
+	limit := query.integer("limit", 10, 1, 100)
+
+	filter, err := parsePollFilter(query)
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
 
-	polls, err := h.pollService.ListPolls(c.Request.Context(), page, limit)
+	pageReq := repository.PageRequest{
+		Limit:        limit,
+		Page:         page,
+		IncludeTotal: c.Query("include_total") == "true",
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		cursor, err := repository.DecodeCursor(raw)
+		if err != nil {
+			respondWithBadRequest(c, err)
+			return
+		}
+		pageReq.Cursor = cursor
+	} else {
+		// Page-number clients have always been promised a total
+		pageReq.IncludeTotal = true
+	}
+
+	result, err := h.pollService.ListPolls(requestContext(c, h.voters), filter, pageReq)
 	if err != nil {
-		handleServiceError(c, err)
+		respondWithError(c, err)
 		return
 	}
 
 	response := PollListResponse{
-		Polls:    make([]PollResponse, len(polls)),
-		Page:     page,
-		PageSize: limit,
+		Polls:      make([]PollResponse, len(result.Polls)),
+		PageSize:   limit,
+		TotalPolls: result.Total,
+	}
+	if pageReq.Cursor == nil {
+		response.Page = page
+	}
+	if result.NextCursor != nil {
+		response.NextCursor = result.NextCursor.Encode()
+	}
+	if result.PrevCursor != nil {
+		response.PrevCursor = result.PrevCursor.Encode()
 	}
 
-	for i, poll := range polls {
+	for i, poll := range result.Polls {
 		response.Polls[i] = toPollResponse(poll)
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond(c, http.StatusOK, response)
+}
+
+// DeletePoll godoc
+// @Summary Delete a poll
+// @Description Soft-delete a poll; it is purged after the retention period
+// @Tags admin
+// @Param id path string true "Poll ID"
+// @Success 204
+// @Failure 401,403,404 {object} ErrorResponse
+// @Security AdminToken
+// @Router /polls/{id} [delete]
+func (h *PollHandler) DeletePoll(c *gin.Context) {
+	id, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	if err := h.pollService.DeletePoll(requestContext(c, h.voters), id); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UpdatePoll godoc
+// @Summary Update a poll
+// @Description Replace a poll's question, active flag and expiry
+// @Tags admin
+// @Accept json
+// @Param id path string true "Poll ID"
+// @Param poll body UpdatePollRequest true "New poll fields"
+// @Success 204
+// @Failure 400,401,403,404 {object} ErrorResponse
+// @Security AdminToken
+// @Router /polls/{id} [put]
+func (h *PollHandler) UpdatePoll(c *gin.Context) {
+	id, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	var req UpdatePollRequest
+	if err := validateRequest(c, &req); err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	if err := h.pollService.UpdatePoll(requestContext(c, h.voters), id, req.Question, *req.IsActive, req.ExpiresAt); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ArchivePoll godoc
+// @Summary Archive a poll
+// @Description Make a poll read-only while keeping it and its votes visible
+// @Tags admin
+// @Param id path string true "Poll ID"
+// @Success 204
+// @Failure 401,403,404 {object} ErrorResponse
+// @Security AdminToken
+// @Router /polls/{id}/archive [post]
+func (h *PollHandler) ArchivePoll(c *gin.Context) {
+	id, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	if err := h.pollService.ArchivePoll(requestContext(c, h.voters), id); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestorePoll godoc
+// @Summary Restore a poll
+// @Description Undo a soft delete or archive
+// @Tags admin
+// @Param id path string true "Poll ID"
+// @Success 204
+// @Failure 401,403,404 {object} ErrorResponse
+// @Security AdminToken
+// @Router /polls/{id}/restore [post]
+func (h *PollHandler) RestorePoll(c *gin.Context) {
+	id, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	if err := h.pollService.RestorePoll(requestContext(c, h.voters), id); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UnlockPoll godoc
+// @Summary Unlock a password-protected poll
+// @Description Verify a poll's password and issue a short-lived access token to send as X-Poll-Access-Token
+// @Tags polls
+// @Accept json
+// @Produce json
+// @Param id path string true "Poll ID"
+// @Param body body UnlockPollRequest true "Poll password"
+// @Success 200 {object} AccessTokenResponse
+// @Failure 400,401,404,429 {object} ErrorResponse
+// @Router /polls/{id}/unlock [post]
+func (h *PollHandler) UnlockPoll(c *gin.Context) {
+	pollID, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	var req UnlockPollRequest
+	if err := validateRequest(c, &req); err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	token, expiresAt, err := h.pollService.UnlockPoll(requestContext(c, h.voters), pollID, req.Password)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, AccessTokenResponse{
+		AccessToken: token,
+		ExpiresAt:   expiresAt,
+	})
 }
 
-// Error handling helpers
-func handleServiceError(c *gin.Context, err error) {
-	switch {
-	case errors.Is(err, entity.ErrPollNotFound):
-		respondWithError(c, http.StatusNotFound, err)
-	case errors.Is(err, entity.ErrDuplicateVote):
-		respondWithError(c, http.StatusConflict, err)
-	case errors.Is(err, entity.ErrPollInactive):
-		respondWithError(c, http.StatusForbidden, err)
-	case errors.Is(err, entity.ErrPollExpired):
-		respondWithError(c, http.StatusForbidden, err)
-	default:
-		respondWithError(c, http.StatusInternalServerError, err)
+// GetChallenge godoc
+// @Summary Get a proof-of-work challenge
+// @Description Issue a signed, single-use challenge for a poll that requires proof of work. Its difficulty
+// @Description rises with the poll's recent vote rate. Send the token and a solution with the vote.
+// @Tags polls
+// @Produce json
+// @Param id path string true "Poll ID"
+// @Success 200 {object} ChallengeResponse
+// @Failure 400,401,404 {object} ErrorResponse
+// @Router /polls/{id}/challenge [get]
+func (h *PollHandler) GetChallenge(c *gin.Context) {
+	pollID, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
 	}
+
+	since := time.Now().Add(-h.challenges.Window())
+	recentVotes, err := h.pollService.RecentVotes(requestContext(c, h.voters), pollID, since)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	challenge, err := h.challenges.Issue(pollID, recentVotes)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, toChallengeResponse(challenge))
+}
+
+// CreateInvite godoc
+// @Summary Create an invite code for a poll
+// @Description Generate an invite code admitting voters to a private poll. The code is only returned once.
+// @Tags polls
+// @Accept json
+// @Produce json
+// @Param id path string true "Poll ID"
+// @Param X-Poll-Owner-Token header string false "Owner token returned when the poll was created"
+// @Param invite body CreateInviteRequest false "Invite limits (single-use by default)"
+// @Success 201 {object} InviteResponse
+// @Failure 400,403,404 {object} ErrorResponse
+// @Router /polls/{id}/invites [post]
+func (h *PollHandler) CreateInvite(c *gin.Context) {
+	pollID, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	var req CreateInviteRequest
+	if err := validateRequest(c, &req); err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	invite, err := h.pollService.CreateInvite(requestContext(c, h.voters), pollID, req.MaxUses, req.ExpiresAt)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	respond(c, http.StatusCreated, toInviteResponse(invite))
+}
+
+// RevokeInvite godoc
+// @Summary Revoke an invite code
+// @Description Stop an invite code from admitting further voters
+// @Tags polls
+// @Param id path string true "Poll ID"
+// @Param invite_id path string true "Invite ID"
+// @Param X-Poll-Owner-Token header string false "Owner token returned when the poll was created"
+// @Success 204
+// @Failure 400,403,404 {object} ErrorResponse
+// @Router /polls/{id}/invites/{invite_id} [delete]
+func (h *PollHandler) RevokeInvite(c *gin.Context) {
+	pollID, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	inviteID, err := validateID("invite_id", c.Param("invite_id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	if err := h.pollService.RevokeInvite(requestContext(c, h.voters), pollID, inviteID); err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetAuditLog godoc
+// @Summary Get a poll's audit log
+// @Description List who created, changed, closed or deleted a poll and what changed, newest first. Client IP hashes are only shown to admins.
+// @Tags polls
+// @Produce json
+// @Param id path string true "Poll ID"
+// @Param X-Poll-Owner-Token header string false "Owner token returned when the poll was created"
+// @Success 200 {object} AuditLogResponse
+// @Failure 400,403,404 {object} ErrorResponse
+// @Router /polls/{id}/audit [get]
+func (h *PollHandler) GetAuditLog(c *gin.Context) {
+	pollID, err := validateID("id", c.Param("id"))
+	if err != nil {
+		respondWithBadRequest(c, err)
+		return
+	}
+
+	events, err := h.pollService.AuditLog(requestContext(c, h.voters), pollID)
+	if err != nil {
+		respondWithError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, toAuditLogResponse(pollID, events, isAdmin(c)))
 }
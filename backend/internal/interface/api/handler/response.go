@@ -1,14 +1,16 @@
 package handler
 
 import (
-	"net/http"
+	"errors"
 	"time"
 
 	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/negotiate"
 	"github.com/gin-gonic/gin"
 )
 
-// Response wrappers
+// Response is the envelope wrapping every body served by the versioned API.
+// The legacy API uses it for errors only.
 type Response struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
@@ -25,77 +27,89 @@ type ErrorData struct {
 }
 
 type MetaData struct {
-	Page       int       `json:"page,omitempty"`
-	PageSize   int       `json:"page_size,omitempty"`
-	TotalItems int       `json:"total_items,omitempty"`
-	Timestamp  time.Time `json:"timestamp"`
+	RequestID string    `json:"request_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
 }
 
 // Response helpers
-func respondWithSuccess(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusOK, Response{
-		Success: true,
-		Data:    data,
-		Meta: &MetaData{
-			Timestamp: time.Now(),
-		},
-	})
-}
 
-func respondWithCreated(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusCreated, Response{
+// respond writes a success body, wrapped in the envelope unless the request
+// came in through the legacy API
+func respond(c *gin.Context, code int, data interface{}) {
+	if !negotiate.Enveloped(c) {
+		c.JSON(code, data)
+		return
+	}
+
+	c.JSON(code, Response{
 		Success: true,
 		Data:    data,
 		Meta: &MetaData{
+			RequestID: c.GetString("request_id"),
 			Timestamp: time.Now(),
 		},
 	})
 }
 
-func respondWithError(c *gin.Context, code int, err error) {
-	requestID, _ := c.Get("request_id")
-	var errorCode string
-	var message string
+// respondWithError writes err in the format the client negotiated. Domain
+// errors carry their own code, status and message; anything else is
+// reported as an internal error, and its text is only kept for the request
+// log.
+func respondWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	domainErr := toDomainError(err)
 
-	switch {
-	case err == entity.ErrPollNotFound:
-		errorCode = "POLL_NOT_FOUND"
-		message = "Poll not found"
-	case err == entity.ErrDuplicateVote:
-		errorCode = "DUPLICATE_VOTE"
-		message = "You have already voted in this poll"
-	case err == entity.ErrPollInactive:
-		errorCode = "POLL_INACTIVE"
-		message = "This poll is no longer active"
-	case err == entity.ErrPollExpired:
-		errorCode = "POLL_EXPIRED"
-		message = "This poll has expired"
-	default:
-		errorCode = "INTERNAL_ERROR"
-		message = "An internal error occurred"
+	var details ValidationErrors
+	for _, field := range domainErr.Fields {
+		details = append(details, ValidationError{Field: field.Field, Message: field.Message})
 	}
 
-	c.JSON(code, Response{
+	if negotiate.WantsProblem(c) {
+		problem := negotiate.Problem{
+			Type:   negotiate.ProblemType(domainErr.Code),
+			Title:  domainErr.Message,
+			Status: domainErr.Status,
+			Code:   domainErr.Code,
+		}
+		if len(details) > 0 {
+			problem.Detail = details.Error()
+			problem.Errors = details
+		}
+		negotiate.AbortWithProblem(c, problem)
+		return
+	}
+
+	errorData := &ErrorData{
+		Code:      domainErr.Code,
+		Message:   domainErr.Message,
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now(),
+	}
+	if len(details) > 0 {
+		errorData.Details = details
+	}
+	c.AbortWithStatusJSON(domainErr.Status, Response{
 		Success: false,
-		Error: &ErrorData{
-			Code:      errorCode,
-			Message:   message,
-			Details:   err.Error(),
-			RequestID: requestID.(string),
-			Timestamp: time.Now(),
-		},
+		Error:   errorData,
 	})
 }
 
-func respondWithPagination(c *gin.Context, data interface{}, page, pageSize, totalItems int) {
-	c.JSON(http.StatusOK, Response{
-		Success: true,
-		Data:    data,
-		Meta: &MetaData{
-			Page:       page,
-			PageSize:   pageSize,
-			TotalItems: totalItems,
-			Timestamp:  time.Now(),
-		},
-	})
+// respondWithBadRequest reports a request that could not be bound or
+// parsed. Failed validation rules are listed field by field.
+func respondWithBadRequest(c *gin.Context, err error) {
+	var domainErr *entity.Error
+	if !errors.As(err, &domainErr) {
+		err = formatValidationErrors(err)
+	}
+	respondWithError(c, err)
+}
+
+// toDomainError finds the domain error in err's chain, or ErrInternal when
+// there is none
+func toDomainError(err error) *entity.Error {
+	var domainErr *entity.Error
+	if errors.As(err, &domainErr) {
+		return domainErr
+	}
+	return entity.ErrInternal
 }
@@ -1,25 +1,72 @@
 package handler
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
-// Hashing utilities
-func hashIP(ip string) string {
-	hash := sha256.Sum256([]byte(ip))
-	return hex.EncodeToString(hash[:])
+// VoterHasher turns a client's raw IP, fingerprint and user agent into the
+// keyed hashes stored with votes
+type VoterHasher interface {
+	Identify(ip, fingerprint, userAgent string) entity.VoteIdentifier
 }
 
-func hashFingerprint(fingerprint string) string {
-	hash := sha256.Sum256([]byte(fingerprint))
-	return hex.EncodeToString(hash[:])
+// ChallengeIssuer issues and verifies the proof-of-work challenges for polls
+// that require them
+type ChallengeIssuer interface {
+	Window() time.Duration
+	Issue(pollID uuid.UUID, recentVotes int) (*entity.Challenge, error)
+	Verify(ctx context.Context, token, solution string, pollID uuid.UUID) error
+}
+
+// Request utilities
+const (
+	fingerprintHeader = "X-Fingerprint-Hash"
+	ownerTokenHeader  = "X-Poll-Owner-Token"
+	inviteCodeHeader  = "X-Poll-Invite-Code"
+	passwordHeader    = "X-Poll-Password"
+	accessTokenHeader = "X-Poll-Access-Token"
+)
+
+func isAdmin(c *gin.Context) bool {
+	return c.GetBool("is_admin")
+}
+
+// requestContext returns the request context carrying the calling actor
+func requestContext(c *gin.Context, voters VoterHasher) context.Context {
+	identifier := voters.Identify(c.ClientIP(), c.GetHeader(fingerprintHeader), c.Request.UserAgent())
+	identifier.UserID = c.GetString("user_id")
+
+	actor := entity.Actor{
+		Identifier:  identifier,
+		OwnerToken:  c.GetHeader(ownerTokenHeader),
+		InviteCode:  c.GetHeader(inviteCodeHeader),
+		Password:    c.GetHeader(passwordHeader),
+		AccessToken: c.GetHeader(accessTokenHeader),
+		IsAdmin:     isAdmin(c),
+		UserID:      c.GetString("user_id"),
+		Email:       c.GetString("user_email"),
+		RequestID:   c.GetString("request_id"),
+	}
+	return entity.ContextWithActor(c.Request.Context(), actor)
+}
+
+// includeDeleted honours ?include_deleted=true for administrators only
+func includeDeleted(c *gin.Context) bool {
+	return isAdmin(c) && c.Query("include_deleted") == "true"
+}
+
+// includeUnlisted honours ?include_unlisted=true for administrators only
+func includeUnlisted(c *gin.Context) bool {
+	return isAdmin(c) && c.Query("include_unlisted") == "true"
 }
 
 // Time utilities
@@ -78,12 +125,33 @@ func toPollStatsResponse(stats *entity.PollStats) PollStatsResponse {
 			VoteCount:  opt.VoteCount,
 			Percentage: roundPercentage(opt.Percentage),
 		}
+		if !stats.ResultsHidden {
+			options[i].ConfidenceInterval = &ConfidenceIntervalResponse{
+				Low:  roundPercentage(opt.ConfidenceLow),
+				High: roundPercentage(opt.ConfidenceHigh),
+			}
+		}
 	}
 
-	return PollStatsResponse{
-		TotalVotes: stats.TotalVotes,
-		Options:    options,
+	response := PollStatsResponse{
+		TotalVotes:      stats.TotalVotes,
+		Options:         options,
+		ConfidenceLevel: entity.ConfidenceLevel,
+		TooCloseToCall:  stats.TooCloseToCall,
+		ResultsHidden:   stats.ResultsHidden,
 	}
+	if stats.Leader != nil {
+		response.Leader = &LeaderComparisonResponse{
+			LeaderID:    stats.Leader.LeaderID,
+			RunnerUpID:  stats.Leader.RunnerUpID,
+			Margin:      roundPercentage(stats.Leader.Margin),
+			ZScore:      stats.Leader.ZScore,
+			PValue:      stats.Leader.PValue,
+			Significant: stats.Leader.Significant,
+		}
+	}
+
+	return response
 }
 
 // Math utilities
@@ -115,8 +183,7 @@ func uniqueStrings(slice []string) []string {
 
 // Error utilities
 func isValidationError(err error) bool {
-	_, ok := err.(ValidationErrors)
-	return ok
+	return errors.Is(err, entity.ErrValidation)
 }
 
 func combineErrors(errs ...error) error {
@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
@@ -20,11 +26,13 @@ type ValidationError struct {
 // ValidationErrors is a collection of validation errors
 type ValidationErrors []ValidationError
 
-// Validator instance
+// Validator instance. Request models declare their rules in binding tags.
 var validate *validator.Validate
 
 func init() {
 	validate = validator.New()
+	validate.SetTagName("binding")
+	validate.RegisterTagNameFunc(jsonTagName)
 
 	// Register custom validators
 	validate.RegisterValidation("future_time", validateFutureTime)
@@ -32,6 +40,29 @@ func init() {
 	validate.RegisterValidation("valid_question", validateQuestion)
 }
 
+// jsonTagName names a field by its JSON key so validation errors match the
+// request body
+func jsonTagName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// jsonFieldName is the dotted path to a rejected field, without the name of
+// the request struct
+func jsonFieldName(e validator.FieldError) string {
+	namespace := e.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
 // Custom validators
 func validateFutureTime(fl validator.FieldLevel) bool {
 	timeVal, ok := fl.Field().Interface().(time.Time)
@@ -53,39 +84,134 @@ func validateQuestion(fl validator.FieldLevel) bool {
 	return len(trimmed) >= 5 && len(trimmed) <= 500
 }
 
+// normalizer is implemented by requests that tidy their fields before they
+// are validated
+type normalizer interface {
+	normalize()
+}
+
 // Validation helpers
+
+// validateRequest decodes the JSON body into req, normalizes it and checks
+// its binding rules. An empty body is validated as an empty object, so
+// requests without required fields may omit it. Anything after the JSON
+// value other than whitespace is rejected.
 func validateRequest(c *gin.Context, req interface{}) error {
-	if err := c.ShouldBindJSON(req); err != nil {
-		return fmt.Errorf("invalid request format: %w", err)
+	if c.Request.Body != nil {
+		dec := json.NewDecoder(c.Request.Body)
+		err := dec.Decode(req)
+		if err == nil {
+			if _, next := dec.Token(); !errors.Is(next, io.EOF) {
+				return fmt.Errorf("%w: unexpected data after the JSON body", entity.ErrInvalidRequest)
+			}
+		} else if !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: %v", entity.ErrInvalidRequest, err)
+		}
+	}
+
+	if n, ok := req.(normalizer); ok {
+		n.normalize()
 	}
 
 	if err := validate.Struct(req); err != nil {
 		return formatValidationErrors(err)
 	}
-
 	return nil
 }
 
-func validatePollID(id string) (uuid.UUID, error) {
-	pollID, err := uuid.Parse(id)
+// validateID parses a UUID from a path or query parameter
+func validateID(field, value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid poll ID format")
+		return uuid.Nil, entity.ErrValidation.WithFields(entity.FieldError{Field: field, Message: "Must be a valid UUID"})
 	}
-	return pollID, nil
+	return id, nil
+}
+
+// queryParams reads query parameters, collecting an error for each one
+// that does not parse so they can all be reported together
+type queryParams struct {
+	c      *gin.Context
+	fields []entity.FieldError
 }
 
-func validatePaginationParams(page, limit string) (int, int) {
-	pageNum := 1
-	if p, err := strconv.Atoi(page); err == nil && p > 0 {
-		pageNum = p
+func newQueryParams(c *gin.Context) *queryParams {
+	return &queryParams{c: c}
+}
+
+func (q *queryParams) reject(field, message string) {
+	q.fields = append(q.fields, entity.FieldError{Field: field, Message: message})
+}
+
+// integer reads an integer between min and max, or def when the parameter is
+// absent
+func (q *queryParams) integer(name string, def, min, max int) int {
+	raw := q.c.Query(name)
+	if raw == "" {
+		return def
+	}
+	n, err := strconv.Atoi(raw)
+	switch {
+	case err != nil:
+		q.reject(name, "Must be an integer")
+	case n < min:
+		q.reject(name, fmt.Sprintf("Minimum value is %d", min))
+	case n > max:
+		q.reject(name, fmt.Sprintf("Maximum value is %d", max))
+	default:
+		return n
 	}
+	return def
+}
 
-	limitNum := 10
-	if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 100 {
-		limitNum = l
+// oneOf reads a parameter that must be one of values, or "" when absent
+func (q *queryParams) oneOf(name string, values ...string) string {
+	raw := q.c.Query(name)
+	if raw == "" {
+		return ""
 	}
+	for _, v := range values {
+		if raw == v {
+			return raw
+		}
+	}
+	q.reject(name, fmt.Sprintf("Must be one of %s", strings.Join(values, " ")))
+	return ""
+}
+
+// timestamp reads an RFC3339 time, or nil when absent
+func (q *queryParams) timestamp(name string) *time.Time {
+	t, err := parseTime(q.c.Query(name))
+	if err != nil {
+		q.reject(name, "Must be an RFC3339 time")
+	}
+	return t
+}
+
+// err returns a validation error listing the rejected parameters, if any
+func (q *queryParams) err() error {
+	if len(q.fields) == 0 {
+		return nil
+	}
+	return entity.ErrValidation.WithFields(q.fields...)
+}
 
-	return pageNum, limitNum
+// parsePollFilter reads the list filter from query parameters
+func parsePollFilter(q *queryParams) (repository.PollFilter, error) {
+	filter := repository.PollFilter{
+		Status:         entity.PollStatus(q.c.Query("status")),
+		Search:         strings.TrimSpace(q.c.Query("q")),
+		Sort:           repository.PollSort(q.c.Query("sort")),
+		Ascending:      q.oneOf("order", "asc", "desc") == "asc",
+		CreatedAfter:   q.timestamp("created_after"),
+		CreatedBefore:  q.timestamp("created_before"),
+		ExpiresAfter:   q.timestamp("expires_after"),
+		ExpiresBefore:  q.timestamp("expires_before"),
+		IncludeDeleted: includeDeleted(q.c),
+
+		IncludeUnlisted: includeUnlisted(q.c),
+	}
+	return filter, q.err()
 }
 
 func (ve ValidationErrors) Error() string {
@@ -97,32 +223,40 @@ func (ve ValidationErrors) Error() string {
 }
 
 // Error formatting
-func formatValidationErrors(err error) error {
-	var errors ValidationErrors
 
-	validationErrors, ok := err.(validator.ValidationErrors)
-	if !ok {
-		return fmt.Errorf("invalid request format")
+// formatValidationErrors turns the validator's errors into a validation
+// error listing each rejected field. Anything else, such as malformed JSON,
+// is reported as an invalid request without repeating the parser's message.
+func formatValidationErrors(err error) error {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return fmt.Errorf("%w: %v", entity.ErrInvalidRequest, err)
 	}
 
-	for _, e := range validationErrors {
-		errors = append(errors, ValidationError{
-			Field:   e.Field(),
+	fields := make([]entity.FieldError, len(validationErrors))
+	for i, e := range validationErrors {
+		fields[i] = entity.FieldError{
+			Field:   jsonFieldName(e),
 			Message: getValidationErrorMessage(e),
-		})
+		}
 	}
-
-	return errors
+	return entity.ErrValidation.WithFields(fields...)
 }
 
 func getValidationErrorMessage(e validator.FieldError) string {
 	switch e.Tag() {
 	case "required":
 		return "This field is required"
+	case "required_with":
+		return fmt.Sprintf("This field is required with %s", e.Param())
 	case "min":
-		return fmt.Sprintf("Minimum length is %s", e.Param())
+		return boundMessage(e, "Minimum")
 	case "max":
-		return fmt.Sprintf("Maximum length is %s", e.Param())
+		return boundMessage(e, "Maximum")
+	case "email":
+		return "Must be a valid email address"
+	case "oneof":
+		return fmt.Sprintf("Must be one of %s", e.Param())
 	case "future_time":
 		return "Time must be in the future"
 	case "valid_option":
@@ -133,3 +267,78 @@ func getValidationErrorMessage(e validator.FieldError) string {
 		return fmt.Sprintf("Validation failed on condition: %s", e.Tag())
 	}
 }
+
+// boundMessage describes a min or max rule for the kind of field it failed on
+func boundMessage(e validator.FieldError, bound string) string {
+	switch e.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("%s number of items is %s", bound, e.Param())
+	case reflect.String:
+		return fmt.Sprintf("%s length is %s", bound, e.Param())
+	default:
+		return fmt.Sprintf("%s value is %s", bound, e.Param())
+	}
+}
+
+// Request normalization
+
+// normalize trims the question and options and drops blank and repeated
+// options, so the option count is checked against what will be stored
+func (r *CreatePollRequest) normalize() {
+	r.Question = strings.TrimSpace(r.Question)
+
+	if r.Options != nil {
+		options := make([]string, 0, len(r.Options))
+		for _, option := range r.Options {
+			if option = strings.TrimSpace(option); option != "" {
+				options = append(options, option)
+			}
+		}
+		r.Options = uniqueStrings(options)
+	}
+
+	if r.Allowlist != nil {
+		r.Allowlist.normalize()
+	}
+}
+
+func (r *AllowlistRequest) normalize() {
+	for i, email := range r.Emails {
+		r.Emails[i] = strings.TrimSpace(email)
+	}
+	for i, userID := range r.UserIDs {
+		r.UserIDs[i] = strings.TrimSpace(userID)
+	}
+}
+
+func (r *UpdatePollRequest) normalize() {
+	r.Question = strings.TrimSpace(r.Question)
+}
+
+func (r *VoteRequest) normalize() {
+	r.InviteCode = strings.TrimSpace(r.InviteCode)
+}
+
+func (r *LogLevelRequest) normalize() {
+	r.Level = strings.ToLower(strings.TrimSpace(r.Level))
+}
+
+// toAllowlist validates and normalizes the allowlist of a create request
+func toAllowlist(req *AllowlistRequest) ([]entity.AllowlistEntry, error) {
+	entries := make([]entity.AllowlistEntry, 0, len(req.Emails)+len(req.UserIDs))
+	for _, email := range req.Emails {
+		entry, err := entity.NewAllowlistEntry(entity.AllowlistEmail, email)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	for _, userID := range req.UserIDs {
+		entry, err := entity.NewAllowlistEntry(entity.AllowlistUserID, userID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
+	"github.com/gin-gonic/gin"
+)
+
+const redacted = "[REDACTED]"
+
+// bodyLogging decides which routes have their bodies logged and how
+type bodyLogging struct {
+	routes   map[string]bool
+	maxBytes int
+	redact   [][]string
+}
+
+func newBodyLogging(cfg *config.LoggerConfig) *bodyLogging {
+	b := &bodyLogging{
+		routes:   make(map[string]bool, len(cfg.BodyRoutes)),
+		maxBytes: cfg.BodyMaxBytes,
+	}
+	for _, route := range cfg.BodyRoutes {
+		if route = strings.Join(strings.Fields(route), " "); route != "" {
+			b.routes[route] = true
+		}
+	}
+	for _, path := range cfg.BodyRedact {
+		if path = strings.TrimPrefix(strings.TrimSpace(path), "$."); path != "" {
+			b.redact = append(b.redact, strings.Split(path, "."))
+		}
+	}
+	return b
+}
+
+// enabled reports whether the matched route opted into body logging
+func (b *bodyLogging) enabled(c *gin.Context) bool {
+	return len(b.routes) > 0 && b.routes[c.Request.Method+" "+c.FullPath()]
+}
+
+// captureRequest reads up to maxBytes+1 of the request body for logging and
+// puts everything back for the handler
+func (b *bodyLogging) captureRequest(c *gin.Context) []byte {
+	if c.Request.Body == nil {
+		return nil
+	}
+
+	prefix, _ := io.ReadAll(io.LimitReader(c.Request.Body, int64(b.maxBytes)+1))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(prefix), c.Request.Body), c.Request.Body}
+	return prefix
+}
+
+// format returns the body as redacted JSON. Bodies over the size cap or
+// that are not JSON cannot be redacted reliably, so only their size is
+// described.
+func (b *bodyLogging) format(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if len(body) > b.maxBytes {
+		return "[omitted: larger than " + strconv.Itoa(b.maxBytes) + " bytes]"
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "[omitted: not JSON]"
+	}
+	for _, path := range b.redact {
+		value = redactPath(value, path)
+	}
+
+	out, err := json.Marshal(value)
+	if err != nil {
+		return "[omitted: not JSON]"
+	}
+	return string(out)
+}
+
+// redactPath masks the value at path, where each element is an object key
+// or * for any key. Keys match case-insensitively, as encoding/json binds
+// them. Arrays are searched element by element.
+func redactPath(value interface{}, path []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if path[0] != "*" && !strings.EqualFold(path[0], key) {
+				continue
+			}
+			if len(path) == 1 {
+				v[key] = redacted
+			} else {
+				v[key] = redactPath(child, path[1:])
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactPath(child, path)
+		}
+	}
+	return value
+}
+
+// responseCapture copies the start of the response body as it is written
+type responseCapture struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *responseCapture) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseCapture) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// capture keeps one byte past the limit so oversized bodies are detectable
+func (w *responseCapture) capture(data []byte) {
+	if room := w.limit + 1 - w.body.Len(); room > 0 {
+		if len(data) > room {
+			data = data[:room]
+		}
+		w.body.Write(data)
+	}
+}
+
+// captureResponse starts recording the response body
+func (b *bodyLogging) captureResponse(c *gin.Context) *responseCapture {
+	w := &responseCapture{ResponseWriter: c.Writer, limit: b.maxBytes}
+	c.Writer = w
+	return w
+}
+
+// logResponse reports whether a response with status should have its body
+// logged. Only errors are, since successful bodies can be large and hold
+// data the client owns.
+func logResponse(status int) bool {
+	return status >= http.StatusBadRequest
+}
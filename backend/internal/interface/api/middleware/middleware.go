@@ -1,22 +1,31 @@
 package middleware
 
 import (
-	"bytes"
-	"io"
+	"crypto/subtle"
+	"net/http"
+	"strings"
 	"time"
 
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
 	"github.com/Sparker0i/cactro-polls/internal/infrastructure/logger"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/negotiate"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type Middleware struct {
-	logger logger.Logger
+	logger   logger.Logger
+	adminCfg *config.AdminConfig
+	authCfg  *config.AuthConfig
+	bodies   *bodyLogging
 }
 
-func NewMiddleware(logger logger.Logger) *Middleware {
+func NewMiddleware(logger logger.Logger, adminCfg *config.AdminConfig, authCfg *config.AuthConfig, logCfg *config.LoggerConfig) *Middleware {
 	return &Middleware{
-		logger: logger,
+		logger:   logger,
+		adminCfg: adminCfg,
+		authCfg:  authCfg,
+		bodies:   newBodyLogging(logCfg),
 	}
 }
 
@@ -33,18 +42,24 @@ func (m *Middleware) RequestID() gin.HandlerFunc {
 	}
 }
 
-// Logger logs request details
+// Logger logs request details. Successful requests go through the sampled
+// logger so traffic spikes do not flood the logs; server errors are always
+// logged.
 func (m *Middleware) Logger() gin.HandlerFunc {
+	sampled := m.logger.Sampled()
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
 		raw := c.Request.URL.RawQuery
 
-		// Read the request body
-		var bodyBytes []byte
-		if c.Request.Body != nil {
-			bodyBytes, _ = io.ReadAll(c.Request.Body)
-			c.Request.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		// Capture bodies only on routes that opted in
+		logBodies := m.bodies.enabled(c)
+		var requestBody []byte
+		var response *responseCapture
+		if logBodies {
+			requestBody = m.bodies.captureRequest(c)
+			response = m.bodies.captureResponse(c)
 		}
 
 		c.Next()
@@ -56,7 +71,11 @@ func (m *Middleware) Logger() gin.HandlerFunc {
 		requestID, _ := c.Get("request_id")
 
 		// Log request details
-		m.logger.Info("request completed",
+		log := sampled
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			log = m.logger
+		}
+		fields := []logger.Field{
 			logger.String("request_id", requestID.(string)),
 			logger.String("method", c.Request.Method),
 			logger.String("path", path),
@@ -65,7 +84,23 @@ func (m *Middleware) Logger() gin.HandlerFunc {
 			logger.String("duration", duration.String()),
 			logger.String("ip", c.ClientIP()),
 			logger.String("user_agent", c.Request.UserAgent()),
-		)
+		}
+		// Error details are kept out of responses, so the log is the only
+		// place they are recorded
+		if len(c.Errors) > 0 {
+			fields = append(fields, logger.String("error", strings.Join(c.Errors.Errors(), "; ")))
+		}
+		if logBodies {
+			if body := m.bodies.format(requestBody); body != "" {
+				fields = append(fields, logger.String("request_body", body))
+			}
+			if logResponse(c.Writer.Status()) {
+				if body := m.bodies.format(response.body.Bytes()); body != "" {
+					fields = append(fields, logger.String("response_body", body))
+				}
+			}
+		}
+		log.Info("request completed", fields...)
 	}
 }
 
@@ -79,11 +114,77 @@ func (m *Middleware) Recovery() gin.HandlerFunc {
 					logger.String("request_id", requestID.(string)),
 					logger.String("error", err.(string)),
 				)
-				c.AbortWithStatusJSON(500, gin.H{
-					"error": "Internal server error",
-				})
+				abort(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error")
 			}
 		}()
 		c.Next()
 	}
 }
+
+// Admin marks the request as coming from an administrator when it carries
+// the configured admin token as a bearer token
+func (m *Middleware) Admin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c)
+		isAdmin := ok && m.adminCfg.Token != "" &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(m.adminCfg.Token)) == 1
+		c.Set("is_admin", isAdmin)
+		c.Next()
+	}
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header
+func bearerToken(c *gin.Context) (string, bool) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	return token, ok && token != ""
+}
+
+// IsAdmin reports whether Admin marked the request as administrative
+func IsAdmin(c *gin.Context) bool {
+	return c.GetBool("is_admin")
+}
+
+// Identity records the user ID and email asserted by a trusted
+// authenticating proxy, if one is configured
+func (m *Middleware) Identity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if m.authCfg.TrustProxyHeaders {
+			c.Set("user_id", strings.TrimSpace(c.GetHeader(m.authCfg.UserHeader)))
+			c.Set("user_email", strings.TrimSpace(c.GetHeader(m.authCfg.EmailHeader)))
+		}
+		c.Next()
+	}
+}
+
+// RequireAdmin rejects requests that Admin did not mark as administrative:
+// those without a bearer token are unauthenticated, those with the wrong
+// one are forbidden
+func (m *Middleware) RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := bearerToken(c); !ok {
+			c.Header("WWW-Authenticate", "Bearer")
+			abort(c, http.StatusUnauthorized, "ADMIN_TOKEN_REQUIRED", "An admin bearer token is required")
+			return
+		}
+		if !IsAdmin(c) {
+			abort(c, http.StatusForbidden, "ADMIN_REQUIRED", "Admin access required")
+			return
+		}
+		c.Next()
+	}
+}
+
+// abort ends the request with an error in the format the client negotiated.
+// Legacy clients get the original {"error": message} body.
+func abort(c *gin.Context, status int, code, message string) {
+	if negotiate.WantsProblem(c) {
+		negotiate.AbortWithProblem(c, negotiate.Problem{
+			Type:   negotiate.ProblemType(code),
+			Title:  message,
+			Status: status,
+			Code:   code,
+		})
+		return
+	}
+	c.AbortWithStatusJSON(status, gin.H{"error": message})
+}
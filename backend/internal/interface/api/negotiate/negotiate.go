@@ -0,0 +1,105 @@
+// Package negotiate picks how a response is shaped from the API version a
+// request was routed to and the media types it accepts.
+//
+// The unversioned /api routes keep their original shapes so existing
+// clients don't break: bare success bodies and the Response error envelope.
+// Routes under /api/v1 wrap every success body in the Response envelope and
+// report errors as RFC 7807 problem details.
+package negotiate
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// Legacy is the unversioned API served under /api
+	Legacy = ""
+	// V1 is the API served under /api/v1
+	V1 = "v1"
+
+	// ProblemContentType is the media type of RFC 7807 problem details
+	ProblemContentType = "application/problem+json"
+
+	versionKey = "api_version"
+)
+
+// Version records the API version of the routes it is attached to
+func Version(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(versionKey, version)
+		c.Next()
+	}
+}
+
+// APIVersion returns the API version the request was routed to
+func APIVersion(c *gin.Context) string {
+	return c.GetString(versionKey)
+}
+
+// Enveloped reports whether success bodies are wrapped in the response
+// envelope
+func Enveloped(c *gin.Context) bool {
+	return APIVersion(c) != Legacy
+}
+
+// WantsProblem reports whether errors are returned as problem details.
+// Versioned routes always use them; legacy routes only when the client
+// names application/problem+json in its Accept header.
+func WantsProblem(c *gin.Context) bool {
+	return APIVersion(c) != Legacy || accepts(c.GetHeader("Accept"), ProblemContentType)
+}
+
+// accepts reports whether the Accept header explicitly lists mediaType with
+// a non-zero quality
+func accepts(header, mediaType string) bool {
+	for _, part := range strings.Split(header, ",") {
+		accepted, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || accepted != mediaType {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if weight, err := strconv.ParseFloat(q, 64); err != nil || weight <= 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Problem is an RFC 7807 problem details object. Code, RequestID and Errors
+// are extension members.
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
+}
+
+// ProblemType returns the URI identifying problems with the given error code
+func ProblemType(code string) string {
+	return "urn:cactro-polls:problem:" + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+}
+
+// AbortWithProblem ends the request with problem as application/problem+json.
+// The instance defaults to the request path and the request ID is filled in
+// when one was assigned.
+func AbortWithProblem(c *gin.Context, problem Problem) {
+	if problem.Instance == "" {
+		problem.Instance = c.Request.URL.Path
+	}
+	if problem.RequestID == "" {
+		problem.RequestID = c.GetString("request_id")
+	}
+
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(problem.Status, problem)
+}
@@ -0,0 +1,569 @@
+// Package openapi builds the API's OpenAPI 3 document from the swag-style
+// annotations on its handlers and the structs they exchange, so the
+// document stays next to the code it describes.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Version is the OpenAPI version of the generated document
+const Version = "3.0.3"
+
+// source is a package scanned for annotated handlers and models
+type source struct {
+	dir string
+	// servers overrides the document's servers for this package's paths
+	servers []string
+	// enveloped wraps success bodies in the Response envelope
+	enveloped bool
+}
+
+// sources lists the packages making up the API, relative to the module root.
+// The general API annotations are read from the first.
+var sources = []source{
+	{dir: "internal/interface/api/docs", servers: []string{"/api"}},
+	{dir: "internal/interface/api/handler", enveloped: true},
+	{dir: "internal/interface/api/negotiate"},
+	{dir: "internal/infrastructure/health", servers: []string{"/"}},
+}
+
+// servers are where the handler routes are served. The legacy /api keeps
+// bare success bodies.
+var servers = []map[string]interface{}{
+	{"url": "/api/v1", "description": "Current API"},
+	{"url": "/api", "description": "Legacy API: success bodies are not enveloped and errors use the Response envelope unless application/problem+json is accepted"},
+}
+
+var (
+	paramPattern    = regexp.MustCompile(`^(\S+)\s+(path|query|header|body)\s+(\S+)\s+(true|false)\s+"(.*)"$`)
+	responsePattern = regexp.MustCompile(`^(\d+(?:,\d+)*)(?:\s+\{(object|array)\}\s+(\S+))?(?:\s+"(.*)")?$`)
+	routerPattern   = regexp.MustCompile(`^(\S+)\s+\[(\w+)\]$`)
+)
+
+type generator struct {
+	info    map[string]interface{}
+	paths   map[string]map[string]interface{}
+	schemas map[string]interface{}
+	ops     map[string]bool
+}
+
+// Generate reads the API sources under root, the module directory, and
+// returns the indented JSON document
+func Generate(root string) ([]byte, error) {
+	g := &generator{
+		info:    map[string]interface{}{},
+		paths:   map[string]map[string]interface{}{},
+		schemas: map[string]interface{}{},
+		ops:     map[string]bool{},
+	}
+
+	parsed := make([][]*ast.File, len(sources))
+	for i, src := range sources {
+		files, err := parseDir(filepath.Join(root, src.dir))
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = files
+		for _, file := range files {
+			g.collectSchemas(file)
+		}
+	}
+
+	for _, file := range parsed[0] {
+		g.readInfo(file.Doc)
+	}
+	for i, src := range sources {
+		for _, file := range parsed[i] {
+			if err := g.collectOperations(file, src); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", src.dir, err)
+			}
+		}
+	}
+
+	doc := map[string]interface{}{
+		"openapi": Version,
+		"info":    g.info,
+		"servers": servers,
+		"paths":   g.paths,
+		"components": map[string]interface{}{
+			"schemas": g.schemas,
+			"responses": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"description": "Problem details on /api/v1 or when application/problem+json is accepted; otherwise the Response envelope with error set",
+					"content": map[string]interface{}{
+						"application/problem+json": map[string]interface{}{"schema": ref("Problem")},
+						"application/json":         map[string]interface{}{"schema": ref("Response")},
+					},
+				},
+			},
+			"securitySchemes": map[string]interface{}{
+				"AdminToken": map[string]interface{}{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "The configured admin token",
+				},
+			},
+		},
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode document: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// parseDir parses the package's non-test files in name order
+func parseDir(dir string) ([]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// annotations returns the @-prefixed lines of a doc comment
+func annotations(doc *ast.CommentGroup) [][2]string {
+	if doc == nil {
+		return nil
+	}
+	var result [][2]string
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		key, value, _ := strings.Cut(line[1:], " ")
+		result = append(result, [2]string{key, strings.TrimSpace(value)})
+	}
+	return result
+}
+
+// readInfo fills the info object from the general API annotations
+func (g *generator) readInfo(doc *ast.CommentGroup) {
+	for _, a := range annotations(doc) {
+		switch a[0] {
+		case "title":
+			g.info["title"] = a[1]
+		case "version":
+			g.info["version"] = a[1]
+		case "description":
+			if existing, ok := g.info["description"].(string); ok {
+				g.info["description"] = existing + " " + a[1]
+			} else {
+				g.info["description"] = a[1]
+			}
+		}
+	}
+}
+
+func (g *generator) collectOperations(file *ast.File, src source) error {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Doc == nil {
+			continue
+		}
+		if err := g.addOperations(fn, src); err != nil {
+			return fmt.Errorf("%s: %w", fn.Name.Name, err)
+		}
+	}
+	return nil
+}
+
+// addOperations documents fn at each of its @Router paths
+func (g *generator) addOperations(fn *ast.FuncDecl, src source) error {
+	op := map[string]interface{}{}
+	responses := map[string]interface{}{}
+	var params []interface{}
+	var routes [][2]string
+	produces := "application/json"
+
+	for _, a := range annotations(fn.Doc) {
+		key, value := a[0], a[1]
+		switch key {
+		case "Summary":
+			op["summary"] = value
+		case "Description":
+			if existing, ok := op["description"].(string); ok {
+				value = existing + " " + value
+			}
+			op["description"] = value
+		case "Tags":
+			op["tags"] = strings.Split(value, ",")
+		case "Produce":
+			if value == "html" {
+				produces = "text/html"
+			}
+		case "Security":
+			op["security"] = []interface{}{map[string]interface{}{value: []string{}}}
+		case "Param":
+			m := paramPattern.FindStringSubmatch(value)
+			if m == nil {
+				return fmt.Errorf("malformed @Param %q", value)
+			}
+			if m[2] == "body" {
+				op["requestBody"] = map[string]interface{}{
+					"description": m[5],
+					"required":    m[4] == "true",
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": g.typeSchema(m[3])},
+					},
+				}
+				continue
+			}
+			params = append(params, map[string]interface{}{
+				"name":        m[1],
+				"in":          m[2],
+				"required":    m[4] == "true" || m[2] == "path",
+				"description": m[5],
+				"schema":      g.typeSchema(m[3]),
+			})
+		case "Success", "Failure":
+			m := responsePattern.FindStringSubmatch(value)
+			if m == nil {
+				return fmt.Errorf("malformed @%s %q", key, value)
+			}
+			for _, code := range strings.Split(m[1], ",") {
+				responses[code] = g.response(key == "Success", code, m[2], m[3], m[4], produces, src.enveloped)
+			}
+		case "Router":
+			m := routerPattern.FindStringSubmatch(value)
+			if m == nil {
+				return fmt.Errorf("malformed @Router %q", value)
+			}
+			routes = append(routes, [2]string{m[1], strings.ToLower(m[2])})
+		}
+	}
+	if len(routes) == 0 {
+		return nil
+	}
+
+	if params != nil {
+		op["parameters"] = params
+	}
+	op["responses"] = responses
+
+	for i, route := range routes {
+		path, method := route[0], route[1]
+		operation := make(map[string]interface{}, len(op)+1)
+		for k, v := range op {
+			operation[k] = v
+		}
+		operation["operationId"] = g.operationID(fn.Name.Name, path, i)
+
+		item, ok := g.paths[path]
+		if !ok {
+			item = map[string]interface{}{}
+			if len(src.servers) > 0 {
+				var pathServers []interface{}
+				for _, url := range src.servers {
+					pathServers = append(pathServers, map[string]interface{}{"url": url})
+				}
+				item["servers"] = pathServers
+			}
+			g.paths[path] = item
+		}
+		if _, exists := item[method]; exists {
+			return fmt.Errorf("%s %s is documented twice", strings.ToUpper(method), path)
+		}
+		item[method] = operation
+	}
+	return nil
+}
+
+// operationID names the operation after its handler, adding the last path
+// segment for handlers served at more than one path
+func (g *generator) operationID(name, path string, index int) string {
+	id := lowerFirst(name)
+	if index > 0 {
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+		id += upperFirst(strings.Trim(segments[len(segments)-1], "{}"))
+	}
+	for base, n := id, 2; g.ops[id]; n++ {
+		id = base + strconv.Itoa(n)
+	}
+	g.ops[id] = true
+	return id
+}
+
+// response documents one status code. Failures without a documented body
+// type of their own share the Error response.
+func (g *generator) response(success bool, code, kind, typeName, description, produces string, enveloped bool) interface{} {
+	status, _ := strconv.Atoi(code)
+	if description == "" {
+		description = http.StatusText(status)
+	}
+
+	if !success {
+		if _, known := g.schemas[typeName]; !known {
+			return ref("Error", "responses")
+		}
+	}
+	resp := map[string]interface{}{"description": description}
+	if typeName == "" {
+		return resp
+	}
+
+	schema := g.typeSchema(typeName)
+	if kind == "array" {
+		schema = map[string]interface{}{"type": "array", "items": schema}
+	}
+	if success && enveloped {
+		schema = map[string]interface{}{
+			"allOf": []interface{}{
+				ref("Response"),
+				map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"data": schema},
+				},
+			},
+		}
+	}
+	resp["content"] = map[string]interface{}{produces: map[string]interface{}{"schema": schema}}
+	return resp
+}
+
+// typeSchema is the schema for a type named in an annotation
+func (g *generator) typeSchema(name string) map[string]interface{} {
+	switch name {
+	case "string", "integer", "boolean", "number":
+		return map[string]interface{}{"type": name}
+	case "object":
+		return map[string]interface{}{"type": "object"}
+	}
+	return ref(name)
+}
+
+func ref(name string, section ...string) map[string]interface{} {
+	kind := "schemas"
+	if len(section) > 0 {
+		kind = section[0]
+	}
+	return map[string]interface{}{"$ref": "#/components/" + kind + "/" + name}
+}
+
+// collectSchemas adds a schema for every exported struct with JSON fields
+func (g *generator) collectSchemas(file *ast.File) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok || !ts.Name.IsExported() {
+				continue
+			}
+			doc := ts.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if schema := structSchema(ts.Name.Name, st, doc); schema != nil {
+				g.schemas[ts.Name.Name] = schema
+			}
+		}
+	}
+}
+
+// structSchema describes a struct's JSON fields. Request fields are
+// required when their binding rules say so; response fields when they are
+// never omitted.
+func structSchema(name string, st *ast.StructType, doc *ast.CommentGroup) map[string]interface{} {
+	isRequest := strings.HasSuffix(name, "Request")
+	properties := map[string]interface{}{}
+	var required []string
+
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 || !field.Names[0].IsExported() || field.Tag == nil {
+			continue
+		}
+		literal, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			continue
+		}
+		tag := reflect.StructTag(literal)
+		jsonName, jsonOpts, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "" || jsonName == "-" {
+			continue
+		}
+		omitempty := strings.Contains(jsonOpts, "omitempty")
+
+		schema := exprSchema(field.Type, !omitempty)
+		rules := strings.Split(tag.Get("binding"), ",")
+		applyRules(schema, rules)
+		if text := fieldDoc(field); text != "" {
+			schema["description"] = text
+		}
+		properties[jsonName] = schema
+
+		if (isRequest && containsRule(rules, "required")) || (!isRequest && !omitempty) {
+			required = append(required, jsonName)
+		}
+	}
+	if len(properties) == 0 {
+		return nil
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	// Section comments such as "Response models" are not type docs
+	if text := strings.TrimSpace(doc.Text()); strings.HasPrefix(text, name+" ") {
+		schema["description"] = strings.Join(strings.Fields(text), " ")
+	}
+	return schema
+}
+
+// exprSchema maps a Go field type to a schema. Pointers that are never
+// omitted can be null.
+func exprSchema(expr ast.Expr, nullablePointer bool) map[string]interface{} {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		schema := exprSchema(t.X, false)
+		if nullablePointer {
+			if _, isRef := schema["$ref"]; isRef {
+				schema = map[string]interface{}{"allOf": []interface{}{schema}}
+			}
+			schema["nullable"] = true
+		}
+		return schema
+	case *ast.ArrayType:
+		return map[string]interface{}{"type": "array", "items": exprSchema(t.Elt, false)}
+	case *ast.MapType:
+		return map[string]interface{}{"type": "object", "additionalProperties": exprSchema(t.Value, false)}
+	case *ast.InterfaceType:
+		return map[string]interface{}{}
+	case *ast.SelectorExpr:
+		switch pkg := t.X.(*ast.Ident).Name + "." + t.Sel.Name; pkg {
+		case "time.Time":
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		case "uuid.UUID":
+			return map[string]interface{}{"type": "string", "format": "uuid"}
+		default:
+			return map[string]interface{}{}
+		}
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return map[string]interface{}{"type": "string"}
+		case "bool":
+			return map[string]interface{}{"type": "boolean"}
+		case "int", "int32":
+			return map[string]interface{}{"type": "integer"}
+		case "int64":
+			return map[string]interface{}{"type": "integer", "format": "int64"}
+		case "float32", "float64":
+			return map[string]interface{}{"type": "number", "format": "double"}
+		default:
+			return ref(t.Name)
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// applyRules carries the validator rules that clients can check themselves
+// into the schema. Rules after dive apply to the items.
+func applyRules(schema map[string]interface{}, rules []string) {
+	target := schema
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		isArray := target["type"] == "array"
+		switch name {
+		case "dive":
+			if items, ok := target["items"].(map[string]interface{}); ok {
+				target = items
+			}
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			key := map[string]map[bool]string{
+				"min": {true: "minItems", false: "minLength"},
+				"max": {true: "maxItems", false: "maxLength"},
+			}[name][isArray]
+			if target["type"] == "integer" || target["type"] == "number" {
+				key = map[string]string{"min": "minimum", "max": "maximum"}[name]
+			}
+			target[key] = n
+		case "oneof":
+			target["enum"] = strings.Fields(param)
+		case "email":
+			target["format"] = "email"
+		case "valid_question":
+			target["minLength"], target["maxLength"] = 5, 500
+		case "valid_option":
+			target["minLength"], target["maxLength"] = 1, 200
+		case "future_time":
+			target["description"] = "Must be in the future"
+		}
+	}
+}
+
+func containsRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if strings.TrimSpace(rule) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldDoc(field *ast.Field) string {
+	for _, group := range []*ast.CommentGroup{field.Doc, field.Comment} {
+		if group != nil {
+			return strings.Join(strings.Fields(group.Text()), " ")
+		}
+	}
+	return ""
+}
+
+func lowerFirst(s string) string {
+	for i, r := range s {
+		if !unicode.IsUpper(r) {
+			if i > 1 {
+				i--
+			}
+			return strings.ToLower(s[:i]) + s[i:]
+		}
+	}
+	return strings.ToLower(s)
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -1,52 +1,109 @@
 package router
 
 import (
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/health"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/docs"
 	"github.com/Sparker0i/cactro-polls/internal/interface/api/handler"
 	"github.com/Sparker0i/cactro-polls/internal/interface/api/middleware"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/negotiate"
 	"github.com/gin-gonic/gin"
 )
 
 type Router struct {
 	engine     *gin.Engine
 	handler    *handler.PollHandler
+	analytics  *handler.AnalyticsHandler
+	moderation *handler.ModerationHandler
+	admin      *handler.AdminHandler
 	middleware *middleware.Middleware
+	health     *health.Registry
+	extra      []gin.HandlerFunc
 }
 
 func NewRouter(
 	handler *handler.PollHandler,
+	analytics *handler.AnalyticsHandler,
+	moderation *handler.ModerationHandler,
+	admin *handler.AdminHandler,
 	middleware *middleware.Middleware,
+	health *health.Registry,
 ) *Router {
 	return &Router{
 		engine:     gin.New(),
 		handler:    handler,
+		analytics:  analytics,
+		moderation: moderation,
+		admin:      admin,
 		middleware: middleware,
+		health:     health,
 	}
 }
 
+// Use adds middleware that runs after the request ID is assigned. It must be
+// called before Setup.
+func (r *Router) Use(handlers ...gin.HandlerFunc) {
+	r.extra = append(r.extra, handlers...)
+}
+
 func (r *Router) Setup() {
 	// Middleware
 	r.engine.Use(r.middleware.RequestID())
+	r.engine.Use(r.extra...)
 	r.engine.Use(r.middleware.Logger())
 	r.engine.Use(r.middleware.Recovery())
+	r.engine.Use(r.middleware.Admin())
+	r.engine.Use(r.middleware.Identity())
+
+	// API routes. The unversioned /api keeps its original response shapes
+	// for existing clients; /api/v1 uses the response envelope and problem
+	// details.
+	r.apiRoutes(r.engine.Group("/api", negotiate.Version(negotiate.Legacy)))
+	r.apiRoutes(r.engine.Group("/api/v1", negotiate.Version(negotiate.V1)))
 
-	// API routes
-	api := r.engine.Group("/api")
+	// API documentation
+	r.engine.GET("/api/openapi.json", docs.Spec)
+	r.engine.GET("/api/docs", docs.UI)
+
+	// Health checks
+	r.engine.GET("/livez", r.health.Livez())
+	r.engine.GET("/readyz", r.health.Readyz(middleware.IsAdmin))
+	r.engine.GET("/health", r.health.Health())
+}
+
+// apiRoutes registers the API under api
+func (r *Router) apiRoutes(api *gin.RouterGroup) {
+	polls := api.Group("/polls")
 	{
-		polls := api.Group("/polls")
+		polls.POST("", r.handler.CreatePoll)
+		polls.GET("", r.handler.ListPolls)
+		polls.GET("/trending", r.analytics.TrendingPolls)
+		polls.GET("/:id", r.handler.GetPoll)
+		polls.POST("/:id/vote", r.handler.Vote)
+		polls.GET("/:id/timeline", r.analytics.PollTimeline)
+		polls.GET("/:id/crosstab", r.analytics.CrossTab)
+		polls.POST("/:id/unlock", r.handler.UnlockPoll)
+		polls.GET("/:id/challenge", r.handler.GetChallenge)
+		polls.POST("/:id/invites", r.handler.CreateInvite)
+		polls.DELETE("/:id/invites/:invite_id", r.handler.RevokeInvite)
+		polls.GET("/:id/audit", r.handler.GetAuditLog)
+
+		admin := polls.Group("", r.middleware.RequireAdmin())
 		{
-			polls.POST("", r.handler.CreatePoll)
-			polls.GET("", r.handler.ListPolls)
-			polls.GET("/:id", r.handler.GetPoll)
-			polls.POST("/:id/vote", r.handler.Vote)
+			admin.PUT("/:id", r.handler.UpdatePoll)
+			admin.DELETE("/:id", r.handler.DeletePoll)
+			admin.POST("/:id/archive", r.handler.ArchivePoll)
+			admin.POST("/:id/restore", r.handler.RestorePoll)
+			admin.GET("/:id/quarantine", r.moderation.ListQuarantined)
+			admin.POST("/:id/votes/:vote_id/approve", r.moderation.ApproveVote)
+			admin.POST("/:id/votes/:vote_id/reject", r.moderation.RejectVote)
 		}
 	}
 
-	// Health check
-	r.engine.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "healthy",
-		})
-	})
+	admin := api.Group("/admin", r.middleware.RequireAdmin())
+	{
+		admin.GET("/log-level", r.admin.GetLogLevel)
+		admin.PUT("/log-level", r.admin.SetLogLevel)
+	}
 }
 
 func (r *Router) Engine() *gin.Engine {
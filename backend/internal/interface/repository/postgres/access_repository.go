@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type accessRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAccessRepository(db *pgxpool.Pool) repository.AccessRepository {
+	return &accessRepository{db: db}
+}
+
+func (r *accessRepository) CreateInvite(ctx context.Context, invite *entity.Invite) error {
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO poll_invites (id, poll_id, code_hash, max_uses, uses, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		invite.ID, invite.PollID, invite.CodeHash, invite.MaxUses, invite.Uses, invite.ExpiresAt, invite.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert invite: %w", err)
+	}
+	return nil
+}
+
+func (r *accessRepository) RevokeInvite(ctx context.Context, pollID, inviteID uuid.UUID) error {
+	result, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE poll_invites SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND poll_id = $2`,
+		inviteID, pollID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return entity.ErrInviteNotFound
+	}
+
+	return nil
+}
+
+func (r *accessRepository) RedeemInvite(ctx context.Context, pollID uuid.UUID, codeHash string) error {
+	// The use count is checked and bumped in one statement so concurrent
+	// voters cannot overspend a limited invite
+	result, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE poll_invites SET uses = uses + 1
+		WHERE poll_id = $1 AND code_hash = $2
+			AND revoked_at IS NULL
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+			AND uses < max_uses`,
+		pollID, codeHash,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to redeem invite: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return entity.ErrInvalidInvite
+	}
+
+	return nil
+}
+
+func (r *accessRepository) IsAllowlisted(ctx context.Context, pollID uuid.UUID, userID, email string) (bool, error) {
+	var allowed bool
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM poll_allowlist
+			WHERE poll_id = $1
+				AND ((kind = 'user_id' AND value = $2) OR (kind = 'email' AND value = LOWER($3)))
+		)`,
+		pollID, userID, email,
+	).Scan(&allowed)
+	if err != nil {
+		return false, fmt.Errorf("failed to check allowlist: %w", err)
+	}
+
+	return allowed, nil
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type analyticsRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAnalyticsRepository(db *pgxpool.Pool) repository.AnalyticsRepository {
+	return &analyticsRepository{db: db}
+}
+
+func (r *analyticsRepository) TrendingScores(ctx context.Context, since time.Time, halfLife time.Duration, limit int) ([]entity.TrendingScore, error) {
+	// Each vote contributes 2^(-age/halfLife), so recent votes dominate the
+	// score. Polls with hidden results are left out, as their score and
+	// place in the ranking would reveal how many votes they have.
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT v.poll_id,
+			COUNT(*) AS recent_votes,
+			SUM(POWER(2, -EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - v.created_at)) / $2)) AS score
+		FROM votes v
+		JOIN polls p ON p.id = v.poll_id
+		WHERE v.created_at >= $1
+			AND `+countedVotes+`
+			AND p.deleted_at IS NULL
+			AND p.visibility = 'public'
+			AND p.password_hash IS NULL
+			AND `+resultsPublic+`
+			AND p.archived_at IS NULL
+			AND p.is_active
+			AND (p.starts_at IS NULL OR p.starts_at <= CURRENT_TIMESTAMP)
+			AND (p.expires_at IS NULL OR p.expires_at > CURRENT_TIMESTAMP)
+		GROUP BY v.poll_id
+		ORDER BY score DESC, recent_votes DESC
+		LIMIT $3`,
+		since, halfLife.Seconds(), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending scores: %w", err)
+	}
+	defer rows.Close()
+
+	scores := make([]entity.TrendingScore, 0)
+	for rows.Next() {
+		var score entity.TrendingScore
+		if err := rows.Scan(&score.PollID, &score.RecentVotes, &score.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan trending score: %w", err)
+		}
+		scores = append(scores, score)
+	}
+
+	return scores, rows.Err()
+}
+
+func (r *analyticsRepository) VoteTimeline(ctx context.Context, pollID uuid.UUID, interval entity.TimelineInterval) ([]entity.TimelineCount, error) {
+	// Buckets are truncated in UTC so they do not depend on the session time zone
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT date_trunc($2, v.created_at AT TIME ZONE 'UTC') AS bucket, v.option_id, COUNT(*)
+		FROM votes v
+		WHERE v.poll_id = $1 AND `+countedVotes+`
+		GROUP BY bucket, v.option_id
+		ORDER BY bucket`,
+		pollID, string(interval),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vote timeline: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]entity.TimelineCount, 0)
+	for rows.Next() {
+		var count entity.TimelineCount
+		if err := rows.Scan(&count.Bucket, &count.OptionID, &count.Votes); err != nil {
+			return nil, fmt.Errorf("failed to scan timeline count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *analyticsRepository) CrossTabCounts(ctx context.Context, rowPollID, columnPollID uuid.UUID) ([]entity.CrossTabCount, error) {
+	// A voter is the same person in both polls when both identifiers match.
+	// Hashes are only comparable under the same key, so voters are not
+	// matched across a key rotation or, without configured keys, a restart.
+	// Each poll is first reduced to one answer per voter so voters with
+	// several votes, allowed by some dedup policies, are counted once.
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`WITH a AS (`+voterAnswers("$1")+`), b AS (`+voterAnswers("$2")+`)
+		SELECT a.option_id, b.option_id, COUNT(*)
+		FROM a
+		JOIN b ON b.hash_key_id = a.hash_key_id
+			AND b.ip_hash = a.ip_hash
+			AND b.fingerprint_hash = a.fingerprint_hash
+		GROUP BY a.option_id, b.option_id`,
+		rowPollID, columnPollID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cross-tab counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make([]entity.CrossTabCount, 0)
+	for rows.Next() {
+		var count entity.CrossTabCount
+		if err := rows.Scan(&count.RowOptionID, &count.ColumnOptionID, &count.Votes); err != nil {
+			return nil, fmt.Errorf("failed to scan cross-tab count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// voterAnswers selects each voter's latest counted answer to the poll whose
+// ID is in the given placeholder
+func voterAnswers(pollID string) string {
+	return `SELECT DISTINCT ON (v.hash_key_id, v.ip_hash, v.fingerprint_hash)
+			v.hash_key_id, v.ip_hash, v.fingerprint_hash, v.option_id
+		FROM votes v
+		WHERE v.poll_id = ` + pollID + ` AND ` + countedVotes + `
+		ORDER BY v.hash_key_id, v.ip_hash, v.fingerprint_hash, v.created_at DESC, v.id`
+}
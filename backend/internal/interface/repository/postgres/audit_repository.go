@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// auditChange is how a field change is stored in audit_events.changes
+type auditChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+type auditRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAuditRepository(db *pgxpool.Pool) repository.AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Record(ctx context.Context, event *entity.AuditEvent) error {
+	changes := make([]auditChange, len(event.Changes))
+	for i, change := range event.Changes {
+		changes[i] = auditChange(change)
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit changes: %w", err)
+	}
+
+	_, err = conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO audit_events (id, poll_id, action, actor, request_id, client_ip_hash, changes, created_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8)`,
+		event.ID, event.PollID, string(event.Action), event.Actor,
+		event.RequestID, event.ClientIPHash, encoded, event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+func (r *auditRepository) ListByPoll(ctx context.Context, pollID uuid.UUID) ([]entity.AuditEvent, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT id, poll_id, action, actor, COALESCE(request_id, ''), COALESCE(client_ip_hash, ''),
+			changes, created_at
+		FROM audit_events
+		WHERE poll_id = $1
+		ORDER BY created_at DESC, id`,
+		pollID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]entity.AuditEvent, 0)
+	for rows.Next() {
+		var event entity.AuditEvent
+		var action string
+		var encoded []byte
+		if err := rows.Scan(
+			&event.ID, &event.PollID, &action, &event.Actor, &event.RequestID,
+			&event.ClientIPHash, &encoded, &event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		event.Action = entity.AuditAction(action)
+
+		var changes []auditChange
+		if err := json.Unmarshal(encoded, &changes); err != nil {
+			return nil, fmt.Errorf("failed to decode audit changes: %w", err)
+		}
+		event.Changes = make([]entity.FieldChange, len(changes))
+		for i, change := range changes {
+			event.Changes[i] = entity.FieldChange(change)
+		}
+
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return events, nil
+}
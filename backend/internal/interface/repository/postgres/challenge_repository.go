@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type challengeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewChallengeRepository(db *pgxpool.Pool) repository.ChallengeRepository {
+	return &challengeRepository{db: db}
+}
+
+func (r *challengeRepository) MarkUsed(ctx context.Context, tokenHash string, expiresAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO used_challenges (token_hash, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (token_hash) DO NOTHING`
+
+	tag, err := conn(ctx, r.db).Exec(ctx, query, tokenHash, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark challenge used: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *challengeRepository) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := conn(ctx, r.db).Exec(ctx, `DELETE FROM used_challenges WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge used challenges: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
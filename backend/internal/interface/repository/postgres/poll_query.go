@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+)
+
+// noExpiry stands in for a NULL expires_at so polls without an expiry sort last
+var noExpiry = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// resultsPublic holds for polls p whose vote counts every caller may see.
+// Only these are ranked by votes, so rankings do not reveal hidden results.
+const resultsPublic = `(p.results_visibility = 'public' OR (p.results_visibility = 'after_close'
+	AND (NOT p.is_active OR p.archived_at IS NOT NULL OR p.expires_at <= CURRENT_TIMESTAMP)))`
+
+// queryArgs collects positional arguments for a dynamically built query
+type queryArgs []interface{}
+
+// add appends v and returns its placeholder
+func (a *queryArgs) add(v interface{}) string {
+	*a = append(*a, v)
+	return fmt.Sprintf("$%d", len(*a))
+}
+
+// pollFilterConditions translates a filter into WHERE conditions on polls p
+func pollFilterConditions(filter repository.PollFilter, args *queryArgs) []string {
+	conditions := []string{"TRUE"}
+
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "p.deleted_at IS NULL")
+	}
+	if !filter.IncludeUnlisted {
+		conditions = append(conditions, "p.visibility = 'public'", "p.password_hash IS NULL")
+	}
+
+	switch filter.Status {
+	case entity.PollStatusActive:
+		conditions = append(conditions,
+			"p.is_active",
+			"p.archived_at IS NULL",
+			"(p.starts_at IS NULL OR p.starts_at <= CURRENT_TIMESTAMP)",
+			"(p.expires_at IS NULL OR p.expires_at > CURRENT_TIMESTAMP)",
+		)
+	case entity.PollStatusExpired:
+		conditions = append(conditions, "p.expires_at <= CURRENT_TIMESTAMP")
+	case entity.PollStatusScheduled:
+		conditions = append(conditions, "p.starts_at > CURRENT_TIMESTAMP")
+	}
+
+	if filter.CreatedAfter != nil {
+		conditions = append(conditions, "p.created_at >= "+args.add(*filter.CreatedAfter))
+	}
+	if filter.CreatedBefore != nil {
+		conditions = append(conditions, "p.created_at < "+args.add(*filter.CreatedBefore))
+	}
+	if filter.ExpiresAfter != nil {
+		conditions = append(conditions, "p.expires_at >= "+args.add(*filter.ExpiresAfter))
+	}
+	if filter.ExpiresBefore != nil {
+		conditions = append(conditions, "p.expires_at < "+args.add(*filter.ExpiresBefore))
+	}
+
+	if filter.Search != "" {
+		conditions = append(conditions,
+			"p.search_vector @@ websearch_to_tsquery('english', "+args.add(filter.Search)+")")
+	}
+
+	return conditions
+}
+
+// pollSortExpression returns the keyset sort key for the outer listing query
+func pollSortExpression(sort repository.PollSort) string {
+	switch sort {
+	case repository.SortVotes:
+		return "vote_count"
+	case repository.SortExpiry:
+		return "COALESCE(expires_at, '9999-12-31 00:00:00+00'::timestamptz)"
+	default:
+		return "created_at"
+	}
+}
+
+// cursorKey returns the sort key value a cursor resumes from
+func cursorKey(cursor *repository.Cursor) interface{} {
+	if cursor.Sort == repository.SortVotes {
+		return cursor.Votes
+	}
+	return cursor.Time
+}
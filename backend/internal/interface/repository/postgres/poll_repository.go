@@ -2,13 +2,13 @@ package postgres
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
 	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -21,7 +21,7 @@ func NewPollRepository(db *pgxpool.Pool) repository.PollRepository {
 }
 
 func (r *pollRepository) Create(ctx context.Context, poll *entity.Poll) error {
-	tx, err := r.db.Begin(ctx)
+	tx, err := conn(ctx, r.db).Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -29,9 +29,12 @@ func (r *pollRepository) Create(ctx context.Context, poll *entity.Poll) error {
 
 	// Insert poll
 	_, err = tx.Exec(ctx,
-		`INSERT INTO polls (id, question, expires_at, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`,
-		poll.ID, poll.Question, poll.ExpiresAt, poll.IsActive, poll.CreatedAt, poll.UpdatedAt,
+		`INSERT INTO polls (id, question, expires_at, is_active, created_at, updated_at, starts_at,
+			results_visibility, owner_token_hash, visibility, password_hash, dedup_policy, proof_of_work)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, NULLIF($11, ''), $12, $13)`,
+		poll.ID, poll.Question, poll.ExpiresAt, poll.IsActive, poll.CreatedAt, poll.UpdatedAt, poll.StartsAt,
+		string(poll.ResultsVisibility), poll.OwnerTokenHash, string(poll.Visibility), poll.PasswordHash,
+		string(poll.DedupPolicy), poll.ProofOfWork,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert poll: %w", err)
@@ -49,6 +52,19 @@ func (r *pollRepository) Create(ctx context.Context, poll *entity.Poll) error {
 		}
 	}
 
+	// Insert allowlist
+	for _, entry := range poll.Allowlist {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO poll_allowlist (poll_id, kind, value)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`,
+			poll.ID, string(entry.Kind), entry.Value,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert allowlist entry: %w", err)
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -57,49 +73,95 @@ func (r *pollRepository) Create(ctx context.Context, poll *entity.Poll) error {
 }
 
 func (r *pollRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Poll, error) {
-	var poll entity.Poll
+	return r.getByID(ctx, id, false)
+}
 
-	err := r.db.QueryRow(ctx,
-		`SELECT id, question, expires_at, is_active, created_at, updated_at
-		FROM polls WHERE id = $1`,
-		id,
-	).Scan(
-		&poll.ID,
-		&poll.Question,
-		&poll.ExpiresAt,
-		&poll.IsActive,
-		&poll.CreatedAt,
-		&poll.UpdatedAt,
-	)
+func (r *pollRepository) GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*entity.Poll, error) {
+	return r.getByID(ctx, id, true)
+}
+
+func (r *pollRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Poll, error) {
+	return r.getByIDs(ctx, ids, false)
+}
+
+func (r *pollRepository) getByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Poll, error) {
+	polls, err := r.getByIDs(ctx, []uuid.UUID{id}, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	if len(polls) == 0 {
+		return nil, entity.ErrPollNotFound
+	}
+	return polls[0], nil
+}
 
+// getByIDs loads the polls with their options and vote counts in two
+// queries, keeping the order of ids and skipping polls that do not exist
+func (r *pollRepository) getByIDs(ctx context.Context, ids []uuid.UUID, includeDeleted bool) ([]*entity.Poll, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT id, question, expires_at, is_active, created_at, updated_at, archived_at, deleted_at, starts_at,
+			results_visibility, COALESCE(owner_token_hash, ''), visibility, COALESCE(password_hash, ''), dedup_policy,
+			proof_of_work
+		FROM polls WHERE id = ANY($1) AND ($2 OR deleted_at IS NULL)`,
+		ids, includeDeleted,
+	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, entity.ErrPollNotFound
+		return nil, fmt.Errorf("failed to get poll: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[uuid.UUID]*entity.Poll, len(ids))
+	for rows.Next() {
+		var poll entity.Poll
+		err := rows.Scan(
+			&poll.ID,
+			&poll.Question,
+			&poll.ExpiresAt,
+			&poll.IsActive,
+			&poll.CreatedAt,
+			&poll.UpdatedAt,
+			&poll.ArchivedAt,
+			&poll.DeletedAt,
+			&poll.StartsAt,
+			&poll.ResultsVisibility,
+			&poll.OwnerTokenHash,
+			&poll.Visibility,
+			&poll.PasswordHash,
+			&poll.DedupPolicy,
+			&poll.ProofOfWork,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan poll: %w", err)
 		}
+		poll.Options = make([]entity.Option, 0)
+		found[poll.ID] = &poll
+	}
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("failed to get poll: %w", err)
 	}
+	if len(found) == 0 {
+		return nil, nil
+	}
 
 	// Get options with vote counts
-	rows, err := r.db.Query(ctx,
-		`SELECT o.id, o.option_text, o.created_at, COUNT(v.id) as vote_count
+	optionRows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT o.poll_id, o.id, o.option_text, o.created_at, COUNT(v.id) as vote_count
 		FROM options o
-		LEFT JOIN votes v ON o.id = v.option_id
-		WHERE o.poll_id = $1
-		GROUP BY o.id, o.option_text, o.created_at
+		LEFT JOIN votes v ON o.id = v.option_id AND `+countedVotes+`
+		WHERE o.poll_id = ANY($1)
+		GROUP BY o.poll_id, o.id, o.option_text, o.created_at
 		ORDER BY o.created_at`,
-		id,
+		ids,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get poll options: %w", err)
 	}
-	defer rows.Close()
+	defer optionRows.Close()
 
-	poll.Options = make([]entity.Option, 0)
-	var totalVotes int
-
-	for rows.Next() {
+	for optionRows.Next() {
 		var option entity.Option
-		err := rows.Scan(
+		err := optionRows.Scan(
+			&option.PollID,
 			&option.ID,
 			&option.OptionText,
 			&option.CreatedAt,
@@ -108,23 +170,40 @@ func (r *pollRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Pol
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan option: %w", err)
 		}
-		option.PollID = poll.ID
-		totalVotes += option.VoteCount
-		poll.Options = append(poll.Options, option)
+		if poll, ok := found[option.PollID]; ok {
+			poll.Options = append(poll.Options, option)
+		}
 	}
+	if err := optionRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get poll options: %w", err)
+	}
+
+	polls := make([]*entity.Poll, 0, len(found))
+	for _, id := range ids {
+		poll, ok := found[id]
+		if !ok {
+			continue
+		}
+		delete(found, id)
 
-	// Calculate percentages
-	if totalVotes > 0 {
-		for i := range poll.Options {
-			poll.Options[i].Percentage = float64(poll.Options[i].VoteCount) / float64(totalVotes) * 100
+		// Calculate percentages
+		var totalVotes int
+		for _, option := range poll.Options {
+			totalVotes += option.VoteCount
+		}
+		if totalVotes > 0 {
+			for i := range poll.Options {
+				poll.Options[i].Percentage = float64(poll.Options[i].VoteCount) / float64(totalVotes) * 100
+			}
 		}
+		polls = append(polls, poll)
 	}
 
-	return &poll, nil
+	return polls, nil
 }
 
 func (r *pollRepository) Update(ctx context.Context, poll *entity.Poll) error {
-	tx, err := r.db.Begin(ctx)
+	tx, err := conn(ctx, r.db).Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -148,7 +227,7 @@ func (r *pollRepository) Update(ctx context.Context, poll *entity.Poll) error {
 }
 
 func (r *pollRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result, err := r.db.Exec(ctx,
+	result, err := conn(ctx, r.db).Exec(ctx,
 		`DELETE FROM polls WHERE id = $1`,
 		id,
 	)
@@ -163,24 +242,129 @@ func (r *pollRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (r *pollRepository) List(ctx context.Context, page, limit int) ([]*entity.Poll, error) {
-	offset := (page - 1) * limit
+func (r *pollRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE polls SET deleted_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NULL`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to soft delete poll: %w", err)
+	}
 
-	rows, err := r.db.Query(ctx,
-		`SELECT id, question, expires_at, is_active, created_at, updated_at
-		FROM polls
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2`,
-		limit, offset,
+	if result.RowsAffected() == 0 {
+		return entity.ErrPollNotFound
+	}
+
+	return nil
+}
+
+func (r *pollRepository) Archive(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE polls SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND deleted_at IS NULL`,
+		id,
 	)
+	if err != nil {
+		return fmt.Errorf("failed to archive poll: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return entity.ErrPollNotFound
+	}
+
+	return nil
+}
+
+func (r *pollRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	result, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE polls SET deleted_at = NULL, archived_at = NULL
+		WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore poll: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return entity.ErrPollNotFound
+	}
+
+	return nil
+}
+
+func (r *pollRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result, err := conn(ctx, r.db).Exec(ctx,
+		`DELETE FROM polls WHERE deleted_at IS NOT NULL AND deleted_at < $1`,
+		deletedBefore,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted polls: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+func (r *pollRepository) List(ctx context.Context, filter repository.PollFilter, page repository.PageRequest) (*repository.PollPage, error) {
+	if page.Cursor != nil && !page.Cursor.Matches(filter) {
+		return nil, entity.ErrInvalidCursor
+	}
+
+	sortKey := pollSortExpression(filter.Sort)
+	backward := page.Cursor != nil && page.Cursor.Backward
+
+	// Backward pages are read in the opposite direction and reversed afterwards
+	descending := filter.Ascending == backward
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	args := &queryArgs{}
+	where := strings.Join(pollFilterConditions(filter, args), " AND ")
+
+	query := `SELECT id, question, expires_at, is_active, created_at, updated_at, archived_at, deleted_at, starts_at,
+			results_visibility, owner_token_hash, visibility, password_hash, dedup_policy, proof_of_work, vote_count
+		FROM (
+			SELECT p.id, p.question, p.expires_at, p.is_active, p.created_at, p.updated_at, p.archived_at, p.deleted_at, p.starts_at,
+				p.results_visibility, COALESCE(p.owner_token_hash, '') AS owner_token_hash, p.visibility,
+				COALESCE(p.password_hash, '') AS password_hash, p.dedup_policy, p.proof_of_work,
+				CASE WHEN ` + resultsPublic + ` THEN COALESCE(vc.votes, 0) ELSE 0 END AS vote_count
+			FROM polls p
+			LEFT JOIN (
+				-- Votes are only counted for the polls the filter matches
+				SELECT v.poll_id, COUNT(*) AS votes
+				FROM votes v
+				JOIN polls p ON p.id = v.poll_id
+				WHERE ` + where + ` AND ` + countedVotes + `
+				GROUP BY v.poll_id
+			) vc ON vc.poll_id = p.id
+			WHERE ` + where + `
+		) p`
+
+	if page.Cursor != nil {
+		query += fmt.Sprintf(` WHERE (%s, id) %s (%s, %s)`,
+			sortKey, comparison, args.add(cursorKey(page.Cursor)), args.add(page.Cursor.ID))
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	query += fmt.Sprintf(` ORDER BY %s %s, id %s LIMIT %s`,
+		sortKey, direction, direction, args.add(page.Limit+1))
+	if page.Cursor == nil && page.Page > 1 {
+		query += ` OFFSET ` + args.add((page.Page-1)*page.Limit)
+	}
+
+	rows, err := conn(ctx, r.db).Query(ctx, query, *args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list polls: %w", err)
 	}
 	defer rows.Close()
 
-	polls := make([]*entity.Poll, 0)
+	polls := make([]*entity.Poll, 0, page.Limit+1)
+	voteCounts := make(map[uuid.UUID]int)
 	for rows.Next() {
 		var poll entity.Poll
+		var voteCount int
 		err := rows.Scan(
 			&poll.ID,
 			&poll.Question,
@@ -188,12 +372,88 @@ func (r *pollRepository) List(ctx context.Context, page, limit int) ([]*entity.P
 			&poll.IsActive,
 			&poll.CreatedAt,
 			&poll.UpdatedAt,
+			&poll.ArchivedAt,
+			&poll.DeletedAt,
+			&poll.StartsAt,
+			&poll.ResultsVisibility,
+			&poll.OwnerTokenHash,
+			&poll.Visibility,
+			&poll.PasswordHash,
+			&poll.DedupPolicy,
+			&poll.ProofOfWork,
+			&voteCount,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan poll: %w", err)
 		}
+		voteCounts[poll.ID] = voteCount
 		polls = append(polls, &poll)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list polls: %w", err)
+	}
 
-	return polls, nil
+	hasMore := len(polls) > page.Limit
+	if hasMore {
+		polls = polls[:page.Limit]
+	}
+
+	result := &repository.PollPage{Polls: polls}
+	cursorFor := func(poll *entity.Poll, backward bool) *repository.Cursor {
+		cursor := &repository.Cursor{
+			Sort:      filter.Sort,
+			Ascending: filter.Ascending,
+			ID:        poll.ID,
+			Backward:  backward,
+		}
+		switch filter.Sort {
+		case repository.SortVotes:
+			cursor.Votes = voteCounts[poll.ID]
+		case repository.SortExpiry:
+			cursor.Time = noExpiry
+			if poll.ExpiresAt != nil {
+				cursor.Time = *poll.ExpiresAt
+			}
+		default:
+			cursor.Time = poll.CreatedAt
+		}
+		return cursor
+	}
+
+	if backward {
+		// Restore listing order
+		for i, j := 0, len(polls)-1; i < j; i, j = i+1, j-1 {
+			polls[i], polls[j] = polls[j], polls[i]
+		}
+		if len(polls) > 0 {
+			result.NextCursor = cursorFor(polls[len(polls)-1], false)
+			if hasMore {
+				result.PrevCursor = cursorFor(polls[0], true)
+			}
+		}
+	} else if len(polls) > 0 {
+		if hasMore {
+			result.NextCursor = cursorFor(polls[len(polls)-1], false)
+		}
+		if page.Cursor != nil || page.Page > 1 {
+			result.PrevCursor = cursorFor(polls[0], true)
+		}
+	}
+
+	if page.IncludeTotal {
+		countArgs := &queryArgs{}
+		countConditions := pollFilterConditions(filter, countArgs)
+
+		var total int
+		err := conn(ctx, r.db).QueryRow(ctx,
+			`SELECT COUNT(*) FROM polls p WHERE `+strings.Join(countConditions, " AND "),
+			*countArgs...,
+		).Scan(&total)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count polls: %w", err)
+		}
+		result.Total = &total
+	}
+
+	return result, nil
 }
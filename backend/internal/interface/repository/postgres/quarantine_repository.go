@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// countedVotes limits a query on votes aliased v to those that count towards
+// results: votes never flagged and flagged votes an admin approved
+const countedVotes = "(v.quarantine_status IS NULL OR v.quarantine_status = 'approved')"
+
+// signalQueries measure each anomaly signal for a poll; $2 is the vote's
+// identifier for that signal and $3 the start of the rule's window
+var signalQueries = map[entity.AnomalySignal]string{
+	entity.SignalSubnetBurst: `SELECT COUNT(*) FROM votes
+		WHERE poll_id = $1 AND subnet_hash = $2 AND created_at >= $3`,
+	entity.SignalFingerprintsPerIP: `SELECT COUNT(DISTINCT fingerprint_hash) FROM votes
+		WHERE poll_id = $1 AND ip_hash = $2 AND created_at >= $3`,
+	entity.SignalUserAgentBurst: `SELECT COUNT(*) FROM votes
+		WHERE poll_id = $1 AND user_agent_hash = $2 AND created_at >= $3`,
+}
+
+type quarantineRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewQuarantineRepository(db *pgxpool.Pool) repository.QuarantineRepository {
+	return &quarantineRepository{db: db}
+}
+
+func (r *quarantineRepository) CountSignal(ctx context.Context, vote *entity.Vote, signal entity.AnomalySignal, since time.Time) (int, error) {
+	query, ok := signalQueries[signal]
+	if !ok {
+		return 0, fmt.Errorf("unknown anomaly signal %q", signal)
+	}
+
+	var key string
+	switch signal {
+	case entity.SignalSubnetBurst:
+		key = vote.SubnetHash
+	case entity.SignalFingerprintsPerIP:
+		key = vote.IPHash
+	case entity.SignalUserAgentBurst:
+		key = vote.UserAgentHash
+	}
+
+	var count int
+	if err := conn(ctx, r.db).QueryRow(ctx, query, vote.PollID, key, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count anomaly signal: %w", err)
+	}
+	return count, nil
+}
+
+func (r *quarantineRepository) Quarantine(ctx context.Context, voteID uuid.UUID, reasons []entity.AnomalySignal, score int) error {
+	signals := make([]string, len(reasons))
+	for i, reason := range reasons {
+		signals[i] = string(reason)
+	}
+
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE votes
+		SET quarantine_status = 'quarantined', quarantine_reasons = $2, quarantine_score = $3,
+			quarantined_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND quarantine_status IS NULL`,
+		voteID, signals, score,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to quarantine vote: %w", err)
+	}
+	return nil
+}
+
+func (r *quarantineRepository) ListQuarantined(ctx context.Context, pollID uuid.UUID) ([]entity.QuarantinedVote, error) {
+	rows, err := conn(ctx, r.db).Query(ctx,
+		`SELECT id, poll_id, option_id, quarantine_status, quarantine_reasons, quarantine_score,
+			created_at, quarantined_at, reviewed_at
+		FROM votes
+		WHERE poll_id = $1 AND quarantine_status IS NOT NULL
+		ORDER BY quarantine_status = 'quarantined' DESC, quarantined_at DESC`,
+		pollID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quarantined votes: %w", err)
+	}
+	defer rows.Close()
+
+	votes := make([]entity.QuarantinedVote, 0)
+	for rows.Next() {
+		var vote entity.QuarantinedVote
+		var reasons []string
+		err := rows.Scan(
+			&vote.VoteID,
+			&vote.PollID,
+			&vote.OptionID,
+			&vote.Status,
+			&reasons,
+			&vote.Score,
+			&vote.CreatedAt,
+			&vote.QuarantinedAt,
+			&vote.ReviewedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quarantined vote: %w", err)
+		}
+		for _, reason := range reasons {
+			vote.Reasons = append(vote.Reasons, entity.AnomalySignal(reason))
+		}
+		votes = append(votes, vote)
+	}
+
+	return votes, rows.Err()
+}
+
+func (r *quarantineRepository) Review(ctx context.Context, pollID, voteID uuid.UUID, status entity.QuarantineStatus) error {
+	result, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE votes SET quarantine_status = $3, reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND poll_id = $2 AND quarantine_status = 'quarantined'`,
+		voteID, pollID, string(status),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to review vote: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return entity.ErrQuarantinedVoteNotFound
+	}
+	return nil
+}
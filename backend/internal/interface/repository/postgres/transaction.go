@@ -5,10 +5,31 @@ import (
 	"fmt"
 
 	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// querier runs statements for the repositories; both the pool and an open
+// transaction satisfy it
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+type txKey struct{}
+
+// conn returns the transaction carried by ctx, or db when there is none, so
+// repository calls made with a transaction's context take part in it
+func conn(ctx context.Context, db *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
 type transactionManager struct {
 	db *pgxpool.Pool
 }
@@ -18,15 +39,21 @@ func NewTransactionManager(db *pgxpool.Pool) repository.TransactionManager {
 }
 
 type transaction struct {
-	tx pgx.Tx
+	tx  pgx.Tx
+	ctx context.Context
 }
 
 func (tm *transactionManager) Begin(ctx context.Context) (repository.Transaction, error) {
-	tx, err := tm.db.Begin(ctx)
+	// Beginning inside a transaction opens a savepoint in it
+	tx, err := conn(ctx, tm.db).Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	return &transaction{tx: tx}, nil
+	return &transaction{tx: tx, ctx: context.WithValue(ctx, txKey{}, tx)}, nil
+}
+
+func (t *transaction) Context() context.Context {
+	return t.ctx
 }
 
 func (t *transaction) Commit() error {
@@ -2,14 +2,20 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
 	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// uniqueViolation is the SQLSTATE raised when a unique index rejects a row
+const uniqueViolation = "23505"
+
 type voteRepository struct {
 	db *pgxpool.Pool
 }
@@ -19,28 +25,59 @@ func NewVoteRepository(db *pgxpool.Pool) repository.VoteRepository {
 }
 
 func (r *voteRepository) Create(ctx context.Context, vote *entity.Vote) error {
-	_, err := r.db.Exec(ctx,
-		`INSERT INTO votes (id, poll_id, option_id, ip_hash, fingerprint_hash, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`,
-		vote.ID, vote.PollID, vote.OptionID, vote.IPHash, vote.FingerprintHash, vote.CreatedAt,
+	_, err := conn(ctx, r.db).Exec(ctx,
+		`INSERT INTO votes (id, poll_id, option_id, ip_hash, fingerprint_hash, user_id, dedup_policy,
+			hash_key_id, subnet_hash, user_agent_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, COALESCE(NULLIF($8, ''), 'legacy'),
+			NULLIF($9, ''), NULLIF($10, ''), $11)`,
+		vote.ID, vote.PollID, vote.OptionID, vote.IPHash, vote.FingerprintHash, vote.UserID,
+		string(vote.DedupPolicy), vote.HashKeyID, vote.SubnetHash, vote.UserAgentHash, vote.CreatedAt,
 	)
 	if err != nil {
+		// A concurrent vote that slipped past HasVoted trips the policy's unique index
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return entity.ErrDuplicateVote
+		}
 		return fmt.Errorf("failed to create vote: %w", err)
 	}
 
 	return nil
 }
 
-func (r *voteRepository) HasVoted(ctx context.Context, pollID uuid.UUID, identifier entity.VoteIdentifier) (bool, error) {
+// dedupConditions maps each policy to the identifiers that must match. The
+// hashes are matched against every key in the rotation window; a vote row is
+// hashed under a single key, so its IP and fingerprint still match as a pair.
+var dedupConditions = map[entity.DedupPolicy]string{
+	entity.DedupFingerprint: "fingerprint_hash = ANY($3)",
+	entity.DedupIP:          "ip_hash = ANY($2)",
+	entity.DedupEither:      "(ip_hash = ANY($2) OR fingerprint_hash = ANY($3))",
+	entity.DedupBoth:        "ip_hash = ANY($2) AND fingerprint_hash = ANY($3)",
+	entity.DedupUser:        "user_id = $4",
+}
+
+func (r *voteRepository) HasVoted(ctx context.Context, pollID uuid.UUID, policy entity.DedupPolicy, identifier entity.VoteIdentifier) (bool, error) {
+	condition, ok := dedupConditions[policy]
+	if !ok {
+		// DedupNone never treats a vote as a duplicate
+		return false, nil
+	}
+
+	ipHashes := []string{identifier.IPHash}
+	fingerprintHashes := []string{identifier.FingerprintHash}
+	for _, rotated := range identifier.Rotated {
+		ipHashes = append(ipHashes, rotated.IPHash)
+		fingerprintHashes = append(fingerprintHashes, rotated.FingerprintHash)
+	}
+
 	var exists bool
-	err := r.db.QueryRow(ctx,
+	err := conn(ctx, r.db).QueryRow(ctx,
 		`SELECT EXISTS(
 			SELECT 1 FROM votes 
 			WHERE poll_id = $1 
-			AND ip_hash = $2 
-			AND fingerprint_hash = $3
+			AND `+condition+`
 		)`,
-		pollID, identifier.IPHash, identifier.FingerprintHash,
+		pollID, ipHashes, fingerprintHashes, identifier.UserID,
 	).Scan(&exists)
 
 	if err != nil {
@@ -50,11 +87,40 @@ func (r *voteRepository) HasVoted(ctx context.Context, pollID uuid.UUID, identif
 	return exists, nil
 }
 
+func (r *voteRepository) ExpireLegacyIdentifiers(ctx context.Context, castBefore time.Time) (int64, error) {
+	// Legacy rows hold a plain SHA-256 of the IP and the raw fingerprint.
+	// Each is replaced by a value unique to the vote, so the dedup indexes
+	// still hold but the voter can no longer be recognised.
+	result, err := conn(ctx, r.db).Exec(ctx,
+		`UPDATE votes
+		SET ip_hash = 'expired:' || id, fingerprint_hash = 'expired:' || id, hash_key_id = 'expired'
+		WHERE hash_key_id = 'legacy' AND created_at < $1`,
+		castBefore,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire legacy voter identifiers: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *voteRepository) CountSince(ctx context.Context, pollID uuid.UUID, since time.Time) (int, error) {
+	var count int
+	err := conn(ctx, r.db).QueryRow(ctx,
+		`SELECT COUNT(*) FROM votes WHERE poll_id = $1 AND created_at >= $2`,
+		pollID, since,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recent votes: %w", err)
+	}
+
+	return count, nil
+}
+
 func (r *voteRepository) GetPollStats(ctx context.Context, pollID uuid.UUID) (*entity.PollStats, error) {
-	rows, err := r.db.Query(ctx,
+	rows, err := conn(ctx, r.db).Query(ctx,
 		`SELECT o.id, COUNT(v.id) as vote_count
 		FROM options o
-		LEFT JOIN votes v ON o.id = v.option_id
+		LEFT JOIN votes v ON o.id = v.option_id AND `+countedVotes+`
 		WHERE o.poll_id = $1
 		GROUP BY o.id
 		ORDER BY o.created_at`,
@@ -65,26 +131,19 @@ func (r *voteRepository) GetPollStats(ctx context.Context, pollID uuid.UUID) (*e
 	}
 	defer rows.Close()
 
-	stats := &entity.PollStats{
-		Options: make([]entity.OptionStats, 0),
-	}
-
+	options := make([]entity.OptionStats, 0)
 	for rows.Next() {
 		var optionStats entity.OptionStats
 		err := rows.Scan(&optionStats.OptionID, &optionStats.VoteCount)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan option stats: %w", err)
 		}
-		stats.TotalVotes += optionStats.VoteCount
-		stats.Options = append(stats.Options, optionStats)
+		options = append(options, optionStats)
 	}
-
-	// Calculate percentages
-	if stats.TotalVotes > 0 {
-		for i := range stats.Options {
-			stats.Options[i].Percentage = float64(stats.Options[i].VoteCount) / float64(stats.TotalVotes) * 100
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read option stats: %w", err)
 	}
 
-	return stats, nil
+	// Percentages, intervals and the leader comparison are derived in the domain
+	return entity.NewPollStats(options), nil
 }
@@ -47,11 +47,3 @@ CREATE TRIGGER polls_updated_at
     BEFORE UPDATE ON polls
     FOR EACH ROW
     EXECUTE FUNCTION update_updated_at();
-
--- migrations/000001_init_schema.down.sql
-DROP TRIGGER IF EXISTS polls_updated_at ON polls;
-DROP FUNCTION IF EXISTS update_updated_at();
-DROP TABLE IF EXISTS votes;
-DROP TABLE IF EXISTS options;
-DROP TABLE IF EXISTS polls;
-DROP EXTENSION IF EXISTS "uuid-ossp";
\ No newline at end of file
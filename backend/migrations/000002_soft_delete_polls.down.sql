@@ -0,0 +1,6 @@
+-- migrations/000002_soft_delete_polls.down.sql
+DROP INDEX IF EXISTS idx_polls_live_created_at;
+DROP INDEX IF EXISTS idx_polls_deleted_at;
+ALTER TABLE polls
+    DROP COLUMN IF EXISTS archived_at,
+    DROP COLUMN IF EXISTS deleted_at;
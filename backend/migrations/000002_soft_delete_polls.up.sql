@@ -0,0 +1,8 @@
+-- migrations/000002_soft_delete_polls.up.sql
+ALTER TABLE polls
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN archived_at TIMESTAMPTZ;
+
+-- Indexes
+CREATE INDEX idx_polls_deleted_at ON polls(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_polls_live_created_at ON polls(created_at DESC) WHERE deleted_at IS NULL;
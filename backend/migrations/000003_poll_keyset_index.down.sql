@@ -0,0 +1,3 @@
+-- migrations/000003_poll_keyset_index.down.sql
+DROP INDEX IF EXISTS idx_polls_live_created_at_id;
+CREATE INDEX idx_polls_live_created_at ON polls(created_at DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,4 @@
+-- migrations/000003_poll_keyset_index.up.sql
+-- Keyset pagination orders by (created_at, id), so the index must cover both
+DROP INDEX IF EXISTS idx_polls_live_created_at;
+CREATE INDEX idx_polls_live_created_at_id ON polls(created_at DESC, id DESC) WHERE deleted_at IS NULL;
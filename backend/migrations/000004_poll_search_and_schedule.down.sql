@@ -0,0 +1,11 @@
+-- migrations/000004_poll_search_and_schedule.down.sql
+DROP INDEX IF EXISTS idx_polls_starts_at;
+DROP INDEX IF EXISTS idx_polls_search_vector;
+DROP TRIGGER IF EXISTS options_search_vector ON options;
+DROP TRIGGER IF EXISTS polls_search_vector ON polls;
+DROP FUNCTION IF EXISTS options_search_vector_refresh();
+DROP FUNCTION IF EXISTS polls_search_vector_refresh();
+DROP FUNCTION IF EXISTS poll_search_document(UUID, TEXT);
+ALTER TABLE polls
+    DROP COLUMN IF EXISTS search_vector,
+    DROP COLUMN IF EXISTS starts_at;
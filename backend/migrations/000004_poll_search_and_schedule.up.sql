@@ -0,0 +1,47 @@
+-- migrations/000004_poll_search_and_schedule.up.sql
+ALTER TABLE polls
+    ADD COLUMN starts_at TIMESTAMPTZ,
+    ADD COLUMN search_vector tsvector;
+
+-- Search document: the question weighted above the option text
+CREATE OR REPLACE FUNCTION poll_search_document(p_poll_id UUID, p_question TEXT)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('english', coalesce(p_question, '')), 'A') ||
+           setweight(to_tsvector('english', coalesce(
+               (SELECT string_agg(option_text, ' ') FROM options WHERE poll_id = p_poll_id), ''
+           )), 'B');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION polls_search_vector_refresh()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector = poll_search_document(NEW.id, NEW.question);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Options are only ever removed together with their poll, so deletes need no refresh
+CREATE OR REPLACE FUNCTION options_search_vector_refresh()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE polls SET search_vector = poll_search_document(id, question) WHERE id = NEW.poll_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER polls_search_vector
+    BEFORE INSERT OR UPDATE OF question ON polls
+    FOR EACH ROW
+    EXECUTE FUNCTION polls_search_vector_refresh();
+
+CREATE TRIGGER options_search_vector
+    AFTER INSERT OR UPDATE OF option_text ON options
+    FOR EACH ROW
+    EXECUTE FUNCTION options_search_vector_refresh();
+
+-- Backfill existing polls
+UPDATE polls SET search_vector = poll_search_document(id, question);
+
+-- Indexes
+CREATE INDEX idx_polls_search_vector ON polls USING GIN(search_vector);
+CREATE INDEX idx_polls_starts_at ON polls(starts_at) WHERE starts_at IS NOT NULL;
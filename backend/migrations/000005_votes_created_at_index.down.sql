@@ -0,0 +1,2 @@
+-- migrations/000005_votes_created_at_index.down.sql
+DROP INDEX IF EXISTS idx_votes_created_at;
@@ -0,0 +1,3 @@
+-- migrations/000005_votes_created_at_index.up.sql
+-- Supports sliding-window scans over recent votes
+CREATE INDEX idx_votes_created_at ON votes(created_at);
@@ -0,0 +1,2 @@
+-- migrations/000006_votes_poll_created_at_index.down.sql
+DROP INDEX IF EXISTS idx_votes_poll_id_created_at;
@@ -0,0 +1,3 @@
+-- migrations/000006_votes_poll_created_at_index.up.sql
+-- Supports per-poll time-series aggregation over votes
+CREATE INDEX idx_votes_poll_id_created_at ON votes(poll_id, created_at);
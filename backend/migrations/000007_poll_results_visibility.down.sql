@@ -0,0 +1,4 @@
+-- migrations/000007_poll_results_visibility.down.sql
+ALTER TABLE polls
+    DROP COLUMN IF EXISTS owner_token_hash,
+    DROP COLUMN IF EXISTS results_visibility;
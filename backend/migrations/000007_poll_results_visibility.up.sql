@@ -0,0 +1,5 @@
+-- migrations/000007_poll_results_visibility.up.sql
+ALTER TABLE polls
+    ADD COLUMN results_visibility TEXT NOT NULL DEFAULT 'public'
+        CHECK (results_visibility IN ('public', 'after_vote', 'after_close', 'creator_only')),
+    ADD COLUMN owner_token_hash TEXT;
@@ -0,0 +1,5 @@
+-- migrations/000008_poll_access.down.sql
+DROP TABLE IF EXISTS poll_allowlist;
+DROP TABLE IF EXISTS poll_invites;
+
+ALTER TABLE polls DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,24 @@
+-- migrations/000008_poll_access.up.sql
+ALTER TABLE polls
+    ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+        CHECK (visibility IN ('public', 'unlisted', 'private'));
+
+CREATE TABLE poll_invites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    poll_id UUID NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL UNIQUE,
+    max_uses INTEGER NOT NULL CHECK (max_uses > 0),
+    uses INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_poll_invites_poll_id ON poll_invites(poll_id);
+
+CREATE TABLE poll_allowlist (
+    poll_id UUID NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('email', 'user_id')),
+    value TEXT NOT NULL,
+    PRIMARY KEY (poll_id, kind, value)
+);
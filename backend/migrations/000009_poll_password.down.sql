@@ -0,0 +1,2 @@
+-- migrations/000009_poll_password.down.sql
+ALTER TABLE polls DROP COLUMN IF EXISTS password_hash;
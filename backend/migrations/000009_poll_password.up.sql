@@ -0,0 +1,2 @@
+-- migrations/000009_poll_password.up.sql
+ALTER TABLE polls ADD COLUMN password_hash TEXT;
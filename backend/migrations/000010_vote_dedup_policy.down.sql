@@ -0,0 +1,19 @@
+-- migrations/000010_vote_dedup_policy.down.sql
+DROP INDEX IF EXISTS idx_votes_poll_id_user_id;
+DROP INDEX IF EXISTS idx_votes_poll_id_fingerprint_hash;
+DROP INDEX IF EXISTS idx_votes_poll_id_ip_hash;
+DROP INDEX IF EXISTS idx_votes_dedup_user;
+DROP INDEX IF EXISTS idx_votes_dedup_fingerprint;
+DROP INDEX IF EXISTS idx_votes_dedup_ip;
+DROP INDEX IF EXISTS idx_votes_dedup_both;
+
+-- Fails if polls without the default policy collected duplicates
+ALTER TABLE votes ADD CONSTRAINT votes_poll_id_ip_hash_fingerprint_hash_key
+    UNIQUE (poll_id, ip_hash, fingerprint_hash);
+
+ALTER TABLE votes
+    DROP CONSTRAINT IF EXISTS votes_user_policy_requires_user,
+    DROP COLUMN IF EXISTS dedup_policy,
+    DROP COLUMN IF EXISTS user_id;
+
+ALTER TABLE polls DROP COLUMN IF EXISTS dedup_policy;
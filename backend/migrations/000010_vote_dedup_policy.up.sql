@@ -0,0 +1,29 @@
+-- migrations/000010_vote_dedup_policy.up.sql
+ALTER TABLE polls
+    ADD COLUMN dedup_policy TEXT NOT NULL DEFAULT 'both'
+        CHECK (dedup_policy IN ('fingerprint', 'ip', 'either', 'both', 'user', 'none'));
+
+-- Each vote carries its poll's policy so partial unique indexes can enforce it
+ALTER TABLE votes
+    ADD COLUMN user_id TEXT,
+    ADD COLUMN dedup_policy TEXT NOT NULL DEFAULT 'both'
+        CHECK (dedup_policy IN ('fingerprint', 'ip', 'either', 'both', 'user', 'none')),
+    ADD CONSTRAINT votes_user_policy_requires_user
+        CHECK (dedup_policy <> 'user' OR user_id IS NOT NULL);
+
+ALTER TABLE votes DROP CONSTRAINT IF EXISTS votes_poll_id_ip_hash_fingerprint_hash_key;
+
+-- Indexes
+CREATE UNIQUE INDEX idx_votes_dedup_both ON votes(poll_id, ip_hash, fingerprint_hash)
+    WHERE dedup_policy = 'both';
+CREATE UNIQUE INDEX idx_votes_dedup_ip ON votes(poll_id, ip_hash)
+    WHERE dedup_policy IN ('ip', 'either');
+CREATE UNIQUE INDEX idx_votes_dedup_fingerprint ON votes(poll_id, fingerprint_hash)
+    WHERE dedup_policy IN ('fingerprint', 'either');
+CREATE UNIQUE INDEX idx_votes_dedup_user ON votes(poll_id, user_id)
+    WHERE dedup_policy = 'user';
+
+-- Lookups for HasVoted under the single-identifier policies
+CREATE INDEX idx_votes_poll_id_ip_hash ON votes(poll_id, ip_hash);
+CREATE INDEX idx_votes_poll_id_fingerprint_hash ON votes(poll_id, fingerprint_hash);
+CREATE INDEX idx_votes_poll_id_user_id ON votes(poll_id, user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- migrations/000011_vote_hash_keys.down.sql
+DROP INDEX IF EXISTS idx_votes_hash_key_id;
+
+ALTER TABLE votes DROP COLUMN IF EXISTS hash_key_id;
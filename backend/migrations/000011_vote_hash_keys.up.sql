@@ -0,0 +1,7 @@
+-- migrations/000011_vote_hash_keys.up.sql
+-- Votes cast so far were hashed without a server key
+ALTER TABLE votes
+    ADD COLUMN hash_key_id TEXT NOT NULL DEFAULT 'legacy';
+
+-- Indexes
+CREATE INDEX idx_votes_hash_key_id ON votes(hash_key_id);
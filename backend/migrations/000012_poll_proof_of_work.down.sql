@@ -0,0 +1,2 @@
+-- migrations/000012_poll_proof_of_work.down.sql
+ALTER TABLE polls DROP COLUMN IF EXISTS proof_of_work;
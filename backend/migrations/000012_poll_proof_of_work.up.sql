@@ -0,0 +1,3 @@
+-- migrations/000012_poll_proof_of_work.up.sql
+ALTER TABLE polls
+    ADD COLUMN proof_of_work BOOLEAN NOT NULL DEFAULT false;
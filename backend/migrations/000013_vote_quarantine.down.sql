@@ -0,0 +1,13 @@
+-- migrations/000013_vote_quarantine.down.sql
+DROP INDEX IF EXISTS idx_votes_quarantine;
+DROP INDEX IF EXISTS idx_votes_poll_user_agent_created_at;
+DROP INDEX IF EXISTS idx_votes_poll_subnet_created_at;
+
+ALTER TABLE votes
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS quarantined_at,
+    DROP COLUMN IF EXISTS quarantine_score,
+    DROP COLUMN IF EXISTS quarantine_reasons,
+    DROP COLUMN IF EXISTS quarantine_status,
+    DROP COLUMN IF EXISTS user_agent_hash,
+    DROP COLUMN IF EXISTS subnet_hash;
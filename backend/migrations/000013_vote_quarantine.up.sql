@@ -0,0 +1,18 @@
+-- migrations/000013_vote_quarantine.up.sql
+ALTER TABLE votes
+    ADD COLUMN subnet_hash TEXT,
+    ADD COLUMN user_agent_hash TEXT,
+    ADD COLUMN quarantine_status TEXT
+        CHECK (quarantine_status IN ('quarantined', 'approved', 'rejected')),
+    ADD COLUMN quarantine_reasons TEXT[],
+    ADD COLUMN quarantine_score INTEGER,
+    ADD COLUMN quarantined_at TIMESTAMPTZ,
+    ADD COLUMN reviewed_at TIMESTAMPTZ;
+
+-- Indexes
+CREATE INDEX idx_votes_poll_subnet_created_at ON votes(poll_id, subnet_hash, created_at)
+    WHERE subnet_hash IS NOT NULL;
+CREATE INDEX idx_votes_poll_user_agent_created_at ON votes(poll_id, user_agent_hash, created_at)
+    WHERE user_agent_hash IS NOT NULL;
+CREATE INDEX idx_votes_quarantine ON votes(poll_id, quarantined_at DESC)
+    WHERE quarantine_status IS NOT NULL;
@@ -0,0 +1,3 @@
+-- migrations/000014_audit_events.down.sql
+DROP INDEX IF EXISTS idx_audit_events_poll_created_at;
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,15 @@
+-- migrations/000014_audit_events.up.sql
+-- Audit events are kept after their poll is purged, so there is no foreign key
+CREATE TABLE audit_events (
+    id UUID PRIMARY KEY,
+    poll_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    client_ip_hash TEXT,
+    changes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_audit_events_poll_created_at ON audit_events(poll_id, created_at DESC);
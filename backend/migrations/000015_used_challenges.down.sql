@@ -0,0 +1,2 @@
+-- migrations/000015_used_challenges.down.sql
+DROP TABLE IF EXISTS used_challenges;
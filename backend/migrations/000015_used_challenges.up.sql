@@ -0,0 +1,10 @@
+-- migrations/000015_used_challenges.up.sql
+-- Solved proof-of-work challenges, kept until they expire so each one is
+-- accepted once across every instance
+CREATE TABLE used_challenges (
+    token_hash TEXT PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+-- Indexes
+CREATE INDEX idx_used_challenges_expires_at ON used_challenges(expires_at);
@@ -0,0 +1,8 @@
+-- migrations/000016_poll_search_trigger.down.sql
+DROP TRIGGER IF EXISTS polls_search_vector_update ON polls;
+DROP TRIGGER IF EXISTS polls_search_vector_insert ON polls;
+
+CREATE TRIGGER polls_search_vector
+    BEFORE INSERT OR UPDATE OF question ON polls
+    FOR EACH ROW
+    EXECUTE FUNCTION polls_search_vector_refresh();
@@ -0,0 +1,15 @@
+-- migrations/000016_poll_search_trigger.up.sql
+-- Poll updates always write the question, so only rebuild the search vector
+-- when it actually changed. OLD is not available on insert, hence two triggers.
+DROP TRIGGER IF EXISTS polls_search_vector ON polls;
+
+CREATE TRIGGER polls_search_vector_insert
+    BEFORE INSERT ON polls
+    FOR EACH ROW
+    EXECUTE FUNCTION polls_search_vector_refresh();
+
+CREATE TRIGGER polls_search_vector_update
+    BEFORE UPDATE OF question ON polls
+    FOR EACH ROW
+    WHEN (OLD.question IS DISTINCT FROM NEW.question)
+    EXECUTE FUNCTION polls_search_vector_refresh();
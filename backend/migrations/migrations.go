@@ -0,0 +1,9 @@
+package migrations
+
+import "embed"
+
+// FS holds the versioned SQL migrations so the binary can apply them without
+// the migrations directory being present at runtime.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,76 @@
+package database_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/database"
+	"github.com/Sparker0i/cactro-polls/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		versions []int64
+		wantErr  bool
+	}{
+		{
+			name: "Ordered pairs",
+			fsys: fstest.MapFS{
+				"000002_add_index.up.sql":   {Data: []byte("CREATE INDEX a ON t(a);")},
+				"000002_add_index.down.sql": {Data: []byte("DROP INDEX a;")},
+				"000001_init.up.sql":        {Data: []byte("CREATE TABLE t (a INT);")},
+				"000001_init.down.sql":      {Data: []byte("DROP TABLE t;")},
+				"README.md":                 {Data: []byte("ignored")},
+			},
+			versions: []int64{1, 2},
+		},
+		{
+			name: "Missing down file",
+			fsys: fstest.MapFS{
+				"000001_init.up.sql": {Data: []byte("CREATE TABLE t (a INT);")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Conflicting names for one version",
+			fsys: fstest.MapFS{
+				"000001_init.up.sql":    {Data: []byte("CREATE TABLE t (a INT);")},
+				"000001_other.down.sql": {Data: []byte("DROP TABLE t;")},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loaded, err := database.LoadMigrations(tt.fsys)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			versions := make([]int64, len(loaded))
+			for i, m := range loaded {
+				versions[i] = m.Version
+				assert.NotEmpty(t, m.Up)
+				assert.NotEmpty(t, m.Down)
+			}
+			assert.Equal(t, tt.versions, versions)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	loaded, err := database.LoadMigrations(migrations.FS)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, loaded)
+
+	for i, m := range loaded {
+		assert.Equal(t, int64(i+1), m.Version, "migration versions must be contiguous")
+	}
+}
@@ -0,0 +1,162 @@
+package docs_test
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/health"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/logger"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/docs"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/handler"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/middleware"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/openapi"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/router"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// moduleRoot is the backend directory, relative to this package
+const moduleRoot = "../../.."
+
+type document struct {
+	Servers    []server                  `json:"servers"`
+	Paths      map[string]map[string]any `json:"paths"`
+	Components struct {
+		Schemas map[string]any `json:"schemas"`
+	} `json:"components"`
+}
+
+type server struct {
+	URL string `json:"url"`
+}
+
+func loadSpec(t *testing.T) document {
+	var doc document
+	require.NoError(t, json.Unmarshal(docs.JSON(), &doc))
+	return doc
+}
+
+// servers returns the base URLs a documented path is served under
+func (d document) servers(path string) []string {
+	servers := d.Servers
+	if raw, ok := d.Paths[path]["servers"]; ok {
+		encoded, _ := json.Marshal(raw)
+		servers = nil
+		_ = json.Unmarshal(encoded, &servers)
+	}
+
+	urls := make([]string, len(servers))
+	for i, s := range servers {
+		urls[i] = strings.TrimSuffix(s.URL, "/")
+	}
+	return urls
+}
+
+func newRouter(t *testing.T) *router.Router {
+	gin.SetMode(gin.TestMode)
+
+	logCfg := &config.LoggerConfig{Level: "info", Format: "json", Output: "file", FilePath: filepath.Join(t.TempDir(), "app.log")}
+	log, err := logger.NewLogger(logCfg)
+	require.NoError(t, err)
+
+	r := router.NewRouter(
+		handler.NewPollHandler(nil, nil, nil),
+		handler.NewAnalyticsHandler(nil, nil),
+		handler.NewModerationHandler(nil),
+		handler.NewAdminHandler(zap.NewAtomicLevel()),
+		middleware.NewMiddleware(log, &config.AdminConfig{}, &config.AuthConfig{}, logCfg),
+		health.NewRegistry(time.Second),
+	)
+	r.Setup()
+	return r
+}
+
+var ginParam = regexp.MustCompile(`:(\w+)`)
+
+func TestOpenAPI_CoversEveryRoute(t *testing.T) {
+	doc := loadSpec(t)
+	routes := newRouter(t).Engine().Routes()
+	require.NotEmpty(t, routes)
+
+	for _, route := range routes {
+		path := ginParam.ReplaceAllString(route.Path, "{$1}")
+		method := strings.ToLower(route.Method)
+
+		documented := false
+		for specPath, item := range doc.Paths {
+			if _, ok := item[method]; !ok {
+				continue
+			}
+			for _, base := range doc.servers(specPath) {
+				if base+specPath == path {
+					documented = true
+				}
+			}
+		}
+		assert.True(t, documented, "%s %s is not in the OpenAPI document", route.Method, route.Path)
+	}
+}
+
+func TestOpenAPI_CoversEveryModel(t *testing.T) {
+	doc := loadSpec(t)
+
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(moduleRoot, "internal/interface/api/handler/models.go"), nil, 0)
+	require.NoError(t, err)
+
+	models := 0
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if _, isStruct := ts.Type.(*ast.StructType); isStruct && ts.Name.IsExported() {
+				models++
+				assert.Contains(t, doc.Components.Schemas, ts.Name.Name, "model %s is not in the OpenAPI document", ts.Name.Name)
+			}
+		}
+	}
+	assert.NotZero(t, models)
+}
+
+func TestOpenAPI_UpToDate(t *testing.T) {
+	generated, err := openapi.Generate(moduleRoot)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(generated), string(docs.JSON()),
+		"openapi.json is stale; run go generate ./internal/interface/api/docs")
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	engine := newRouter(t).Engine()
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, docs.JSON(), rec.Body.Bytes())
+
+	rec = httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, rec.Body.String(), "/api/openapi.json")
+
+	// The page may only run the pinned Redoc bundle
+	csp := rec.Header().Get("Content-Security-Policy")
+	assert.Contains(t, csp, "default-src 'none'")
+	assert.Regexp(t, `script-src https://cdn\.redoc\.ly/redoc/v[0-9.]+/bundles/redoc\.standalone\.js;`, csp)
+	assert.Regexp(t, `<script src="https://cdn\.redoc\.ly/redoc/v[0-9.]+/bundles/redoc\.standalone\.js" crossorigin="anonymous"`, rec.Body.String())
+}
@@ -0,0 +1,57 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInvite(t *testing.T) {
+	pollID := uuid.New()
+	past := time.Now().Add(-time.Minute)
+
+	invite, err := entity.NewInvite(pollID, 0, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, invite.MaxUses)
+	assert.NotEmpty(t, invite.Code)
+	assert.Equal(t, entity.HashInviteCode(invite.Code), invite.CodeHash)
+	assert.NotContains(t, invite.CodeHash, invite.Code)
+
+	_, err = entity.NewInvite(pollID, -1, nil)
+	assert.Equal(t, entity.ErrInvalidInvite, err)
+
+	_, err = entity.NewInvite(pollID, 5, &past)
+	assert.Equal(t, entity.ErrInvalidInvite, err)
+}
+
+func TestHashInviteCode_CaseInsensitive(t *testing.T) {
+	assert.Equal(t, entity.HashInviteCode("ABCDEF"), entity.HashInviteCode(" abcdef "))
+}
+
+func TestNewAllowlistEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    entity.AllowlistKind
+		value   string
+		want    string
+		wantErr error
+	}{
+		{name: "Email is lowercased", kind: entity.AllowlistEmail, value: "Jo@Example.com", want: "jo@example.com"},
+		{name: "Display name rejected", kind: entity.AllowlistEmail, value: "Jo <jo@example.com>", wantErr: entity.ErrInvalidAllowlistEntry},
+		{name: "User ID kept as is", kind: entity.AllowlistUserID, value: " User-1 ", want: "User-1"},
+		{name: "Empty user ID", kind: entity.AllowlistUserID, value: " ", wantErr: entity.ErrInvalidAllowlistEntry},
+		{name: "Unknown kind", kind: "phone", value: "123", wantErr: entity.ErrInvalidAllowlistEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := entity.NewAllowlistEntry(tt.kind, tt.value)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, entry.Value)
+		})
+	}
+}
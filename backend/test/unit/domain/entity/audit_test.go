@@ -0,0 +1,95 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffPolls(t *testing.T) {
+	before, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+
+	t.Run("New poll reports every field", func(t *testing.T) {
+		changes := entity.DiffPolls(nil, before)
+		require.NotEmpty(t, changes)
+		assert.Equal(t, "question", changes[0].Field)
+		assert.Nil(t, changes[0].Before)
+		assert.Equal(t, "Test question?", changes[0].After)
+	})
+
+	t.Run("Unchanged poll has no changes", func(t *testing.T) {
+		after := *before
+		assert.Empty(t, entity.DiffPolls(before, &after))
+	})
+
+	t.Run("Only changed fields are reported", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		after := *before
+		after.Question = "New question?"
+		after.IsActive = false
+		after.ExpiresAt = &expiresAt
+
+		changes := entity.DiffPolls(before, &after)
+
+		assert.Equal(t, []entity.FieldChange{
+			{Field: "question", Before: "Test question?", After: "New question?"},
+			{Field: "is_active", Before: true, After: false},
+			{Field: "expires_at", Before: (*time.Time)(nil), After: &expiresAt},
+		}, changes)
+	})
+
+	t.Run("Same instant in another zone is unchanged", func(t *testing.T) {
+		at := time.Now()
+		inUTC := at.UTC()
+		withExpiry, other := *before, *before
+		withExpiry.ExpiresAt = &at
+		other.ExpiresAt = &inUTC
+
+		assert.Empty(t, entity.DiffPolls(&withExpiry, &other))
+	})
+
+	t.Run("Password is reduced to whether it is set", func(t *testing.T) {
+		after := *before
+		after.PasswordHash = "$2a$10$secret"
+
+		changes := entity.DiffPolls(before, &after)
+
+		assert.Equal(t, []entity.FieldChange{{Field: "password_protected", Before: false, After: true}}, changes)
+	})
+}
+
+func TestNewAuditEvent(t *testing.T) {
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+	poll.OwnerToken = "owner-token"
+	poll.OwnerTokenHash = entity.HashOwnerToken(poll.OwnerToken)
+
+	tests := []struct {
+		name  string
+		actor entity.Actor
+		want  string
+	}{
+		{name: "Admin", actor: entity.Actor{IsAdmin: true}, want: "admin"},
+		{name: "Owner", actor: entity.Actor{OwnerToken: poll.OwnerToken}, want: "owner"},
+		{name: "Signed-in user", actor: entity.Actor{UserID: "u1"}, want: "user:u1"},
+		{name: "Anonymous", want: "anonymous"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.actor.RequestID = "req-1"
+			tt.actor.Identifier.IPHash = "ip-hash"
+
+			event := entity.NewAuditEvent(entity.AuditPollCreated, nil, poll, tt.actor)
+
+			assert.Equal(t, poll.ID, event.PollID)
+			assert.Equal(t, tt.want, event.Actor)
+			assert.Equal(t, "req-1", event.RequestID)
+			assert.Equal(t, "ip-hash", event.ClientIPHash)
+		})
+	}
+}
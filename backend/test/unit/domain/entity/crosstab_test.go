@@ -0,0 +1,99 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCrossTab(t *testing.T) {
+	rowPoll, err := entity.NewPoll("Row question?", []string{"Yes", "No"}, nil)
+	require.NoError(t, err)
+	columnPoll, err := entity.NewPoll("Column question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+	yes, no := rowPoll.Options[0].ID, rowPoll.Options[1].ID
+	a, b := columnPoll.Options[0].ID, columnPoll.Options[1].ID
+
+	counts := []entity.CrossTabCount{
+		{RowOptionID: yes, ColumnOptionID: a, Votes: 40},
+		{RowOptionID: yes, ColumnOptionID: b, Votes: 10},
+		{RowOptionID: no, ColumnOptionID: a, Votes: 10},
+		{RowOptionID: no, ColumnOptionID: b, Votes: 40},
+	}
+
+	crossTab := entity.BuildCrossTab(rowPoll, columnPoll, counts, 5)
+
+	assert.Equal(t, 100, crossTab.TotalVoters)
+	require.Len(t, crossTab.Cells, 4)
+	assert.Equal(t, 40, crossTab.Cells[0].Votes)
+	assert.Equal(t, 40.0, crossTab.Cells[0].Percentage)
+	assert.Equal(t, 50, crossTab.Rows[0].Votes)
+	assert.Equal(t, 50, crossTab.Columns[1].Votes)
+
+	assert.False(t, crossTab.TestSuppressed)
+	assert.InDelta(t, 36.0, crossTab.ChiSquare, 0.0001)
+	assert.Equal(t, 1, crossTab.DegreesOfFreedom)
+	assert.Less(t, crossTab.PValue, 0.001)
+}
+
+func TestBuildCrossTab_Independent(t *testing.T) {
+	rowPoll, err := entity.NewPoll("Row question?", []string{"Yes", "No"}, nil)
+	require.NoError(t, err)
+	columnPoll, err := entity.NewPoll("Column question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+
+	var counts []entity.CrossTabCount
+	for _, r := range rowPoll.Options {
+		for _, c := range columnPoll.Options {
+			counts = append(counts, entity.CrossTabCount{RowOptionID: r.ID, ColumnOptionID: c.ID, Votes: 25})
+		}
+	}
+
+	crossTab := entity.BuildCrossTab(rowPoll, columnPoll, counts, 5)
+
+	assert.Equal(t, 0.0, crossTab.ChiSquare)
+	assert.InDelta(t, 1.0, crossTab.PValue, 0.0001)
+}
+
+func TestBuildCrossTab_Suppression(t *testing.T) {
+	rowPoll, err := entity.NewPoll("Row question?", []string{"Yes", "No"}, nil)
+	require.NoError(t, err)
+	columnPoll, err := entity.NewPoll("Column question?", []string{"A", "B", "C"}, nil)
+	require.NoError(t, err)
+	yes, no := rowPoll.Options[0].ID, rowPoll.Options[1].ID
+	a, b, c := columnPoll.Options[0].ID, columnPoll.Options[1].ID, columnPoll.Options[2].ID
+
+	counts := []entity.CrossTabCount{
+		{RowOptionID: yes, ColumnOptionID: a, Votes: 2},
+		{RowOptionID: yes, ColumnOptionID: b, Votes: 20},
+		{RowOptionID: yes, ColumnOptionID: c, Votes: 30},
+		{RowOptionID: no, ColumnOptionID: a, Votes: 12},
+		{RowOptionID: no, ColumnOptionID: b, Votes: 15},
+		{RowOptionID: no, ColumnOptionID: c, Votes: 25},
+	}
+
+	crossTab := entity.BuildCrossTab(rowPoll, columnPoll, counts, 5)
+
+	// Cells are laid out row-major: (yes,a) (yes,b) (yes,c) (no,a) (no,b) (no,c)
+	suppressed := make([]bool, len(crossTab.Cells))
+	for i, cell := range crossTab.Cells {
+		suppressed[i] = cell.Suppressed
+		if cell.Suppressed {
+			assert.Zero(t, cell.Votes)
+			assert.Zero(t, cell.Percentage)
+		}
+	}
+
+	// (yes,a) is below the threshold; its row and column each need a second
+	// hidden cell, which in turn forces a fourth in the other row
+	assert.Equal(t, []bool{true, true, false, true, true, false}, suppressed)
+	assert.Equal(t, 52, crossTab.Rows[0].Votes)
+
+	// The statistic would let the hidden cells be solved for from the margins
+	assert.True(t, crossTab.TestSuppressed)
+	assert.Zero(t, crossTab.ChiSquare)
+	assert.Zero(t, crossTab.DegreesOfFreedom)
+	assert.Zero(t, crossTab.PValue)
+}
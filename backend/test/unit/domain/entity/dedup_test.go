@@ -0,0 +1,61 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDedupPolicy(t *testing.T) {
+	policy, err := entity.ParseDedupPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.DedupBoth, policy)
+
+	policy, err = entity.ParseDedupPolicy("either")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.DedupEither, policy)
+
+	_, err = entity.ParseDedupPolicy("cookie")
+	assert.ErrorIs(t, err, entity.ErrInvalidDedupPolicy)
+}
+
+func TestDedupPolicy_Validate(t *testing.T) {
+	both := entity.NewVoteIdentifier("ip-hash", "fingerprint-hash")
+	ipOnly := entity.NewVoteIdentifier("ip-hash", "")
+	fingerprintOnly := entity.NewVoteIdentifier("", "fingerprint-hash")
+	signedIn := both
+	signedIn.UserID = "u-42"
+
+	tests := []struct {
+		name       string
+		policy     entity.DedupPolicy
+		identifier entity.VoteIdentifier
+		wantErr    error
+	}{
+		{name: "fingerprint with fingerprint", policy: entity.DedupFingerprint, identifier: fingerprintOnly},
+		{name: "fingerprint without fingerprint", policy: entity.DedupFingerprint, identifier: ipOnly, wantErr: entity.ErrInvalidVoteIdentifier},
+		{name: "ip with ip", policy: entity.DedupIP, identifier: ipOnly},
+		{name: "ip without ip", policy: entity.DedupIP, identifier: fingerprintOnly, wantErr: entity.ErrInvalidVoteIdentifier},
+		{name: "either with ip", policy: entity.DedupEither, identifier: ipOnly},
+		{name: "either with fingerprint", policy: entity.DedupEither, identifier: fingerprintOnly},
+		{name: "either with neither", policy: entity.DedupEither, identifier: entity.VoteIdentifier{}, wantErr: entity.ErrInvalidVoteIdentifier},
+		{name: "both with both", policy: entity.DedupBoth, identifier: both},
+		{name: "both with ip only", policy: entity.DedupBoth, identifier: ipOnly, wantErr: entity.ErrInvalidVoteIdentifier},
+		{name: "both with fingerprint only", policy: entity.DedupBoth, identifier: fingerprintOnly, wantErr: entity.ErrInvalidVoteIdentifier},
+		{name: "user signed in", policy: entity.DedupUser, identifier: signedIn},
+		{name: "user anonymous", policy: entity.DedupUser, identifier: both, wantErr: entity.ErrVoterNotAuthenticated},
+		{name: "none with nothing", policy: entity.DedupNone, identifier: entity.VoteIdentifier{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.identifier)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package entity_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestError_MatchesThroughWrapping(t *testing.T) {
+	err := fmt.Errorf("failed to get poll: %w", entity.ErrPollNotFound)
+
+	var domainErr *entity.Error
+	require.True(t, errors.As(err, &domainErr))
+	assert.Equal(t, "POLL_NOT_FOUND", domainErr.Code)
+	assert.Equal(t, http.StatusNotFound, domainErr.Status)
+	assert.True(t, errors.Is(err, entity.ErrPollNotFound))
+	assert.False(t, errors.Is(err, entity.ErrInviteNotFound))
+}
+
+func TestError_WithFields(t *testing.T) {
+	err := entity.ErrValidation.WithFields(
+		entity.FieldError{Field: "question", Message: "This field is required"},
+		entity.FieldError{Field: "options", Message: "Minimum length is 2"},
+	)
+
+	assert.True(t, errors.Is(err, entity.ErrValidation))
+	assert.Empty(t, entity.ErrValidation.Fields, "the sentinel must not be modified")
+	assert.Equal(t, "The request has invalid fields: question: This field is required; options: Minimum length is 2", err.Error())
+}
@@ -98,6 +98,32 @@ func TestPoll_Vote(t *testing.T) {
 			},
 			wantErr: entity.ErrPollInactive,
 		},
+		{
+			name: "Vote on archived poll",
+			setupPoll: func() *entity.Poll {
+				poll, _ := entity.NewPoll("Test?", []string{"A", "B"}, nil)
+				poll.ArchivedAt = &now
+				return poll
+			},
+			identifier: entity.VoteIdentifier{
+				IPHash:          "testhash",
+				FingerprintHash: "fingerprintHash",
+			},
+			wantErr: entity.ErrPollArchived,
+		},
+		{
+			name: "Vote on scheduled poll",
+			setupPoll: func() *entity.Poll {
+				poll, _ := entity.NewPoll("Test?", []string{"A", "B"}, nil)
+				poll.StartsAt = &futureTime
+				return poll
+			},
+			identifier: entity.VoteIdentifier{
+				IPHash:          "testhash",
+				FingerprintHash: "fingerprintHash",
+			},
+			wantErr: entity.ErrPollNotStarted,
+		},
 	}
 
 	for _, tt := range tests {
@@ -123,3 +149,40 @@ func TestPoll_Vote(t *testing.T) {
 		})
 	}
 }
+
+func TestPoll_Status(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		setup    func(p *entity.Poll)
+		expected entity.PollStatus
+	}{
+		{name: "Open poll", setup: func(p *entity.Poll) {}, expected: entity.PollStatusActive},
+		{name: "Not yet started", setup: func(p *entity.Poll) { p.StartsAt = &future }, expected: entity.PollStatusScheduled},
+		{name: "Past expiry", setup: func(p *entity.Poll) { p.ExpiresAt = &past }, expected: entity.PollStatusExpired},
+		{name: "Deactivated", setup: func(p *entity.Poll) { p.IsActive = false }, expected: entity.PollStatusClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll, _ := entity.NewPoll("Test?", []string{"A", "B"}, nil)
+			tt.setup(poll)
+			assert.Equal(t, tt.expected, poll.Status(now))
+		})
+	}
+}
+
+func TestPoll_ApplySettings(t *testing.T) {
+	now := time.Now()
+	expires := now.Add(time.Hour)
+	late := now.Add(2 * time.Hour)
+
+	poll, _ := entity.NewPoll("Test?", []string{"A", "B"}, &expires)
+
+	assert.Equal(t, entity.ErrInvalidSchedule, poll.ApplySettings(entity.PollSettings{StartsAt: &late}))
+	assert.NoError(t, poll.ApplySettings(entity.PollSettings{StartsAt: &now}))
+	assert.Equal(t, &now, poll.StartsAt)
+}
@@ -0,0 +1,81 @@
+package entity_test
+
+import (
+	"testing"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	tests := []struct {
+		name      string
+		successes int
+		total     int
+		wantLow   float64
+		wantHigh  float64
+	}{
+		{name: "No votes", successes: 0, total: 0, wantLow: 0, wantHigh: 1},
+		{name: "Half of 40", successes: 20, total: 40, wantLow: 0.3520, wantHigh: 0.6480},
+		{name: "None of 10", successes: 0, total: 10, wantLow: 0, wantHigh: 0.2775},
+		{name: "All of 10", successes: 10, total: 10, wantLow: 0.7225, wantHigh: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			low, high := entity.WilsonInterval(tt.successes, tt.total)
+			assert.InDelta(t, tt.wantLow, low, 0.0001)
+			assert.InDelta(t, tt.wantHigh, high, 0.0001)
+		})
+	}
+}
+
+func TestNewPollStats(t *testing.T) {
+	tests := []struct {
+		name            string
+		counts          []int
+		wantLeader      int
+		wantRunnerUp    int
+		wantSignificant bool
+	}{
+		{name: "Narrow lead on few votes", counts: []int{21, 19}, wantLeader: 0, wantRunnerUp: 1},
+		{name: "Clear lead", counts: []int{30, 70, 10}, wantLeader: 1, wantRunnerUp: 0, wantSignificant: true},
+		{name: "Tie", counts: []int{5, 5}, wantLeader: 0, wantRunnerUp: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := make([]entity.OptionStats, len(tt.counts))
+			for i, count := range tt.counts {
+				options[i] = entity.OptionStats{OptionID: uuid.New(), VoteCount: count}
+			}
+
+			stats := entity.NewPollStats(options)
+
+			require.NotNil(t, stats.Leader)
+			assert.Equal(t, options[tt.wantLeader].OptionID, stats.Leader.LeaderID)
+			assert.Equal(t, options[tt.wantRunnerUp].OptionID, stats.Leader.RunnerUpID)
+			assert.Equal(t, tt.wantSignificant, stats.Leader.Significant)
+			assert.Equal(t, !tt.wantSignificant, stats.TooCloseToCall)
+
+			for _, opt := range stats.Options {
+				assert.LessOrEqual(t, opt.ConfidenceLow, opt.Percentage)
+				assert.GreaterOrEqual(t, opt.ConfidenceHigh, opt.Percentage)
+			}
+		})
+	}
+}
+
+func TestNewPollStats_NoVotes(t *testing.T) {
+	stats := entity.NewPollStats([]entity.OptionStats{
+		{OptionID: uuid.New()},
+		{OptionID: uuid.New()},
+	})
+
+	assert.Equal(t, 0, stats.TotalVotes)
+	assert.Nil(t, stats.Leader)
+	assert.True(t, stats.TooCloseToCall)
+	assert.Equal(t, 100.0, stats.Options[0].ConfidenceHigh)
+}
@@ -0,0 +1,85 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimelineInterval(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    entity.TimelineInterval
+		wantErr error
+	}{
+		{input: "", want: entity.IntervalHour},
+		{input: "1m", want: entity.IntervalMinute},
+		{input: "1h", want: entity.IntervalHour},
+		{input: "day", want: entity.IntervalDay},
+		{input: "1w", want: entity.IntervalWeek},
+		{input: "5m", wantErr: entity.ErrInvalidBucket},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := entity.ParseTimelineInterval(tt.input)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildTimeline(t *testing.T) {
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+	a, b := poll.Options[0].ID, poll.Options[1].ID
+
+	start := time.Date(2025, 2, 16, 10, 0, 0, 0, time.UTC)
+	counts := []entity.TimelineCount{
+		{Bucket: start, OptionID: a, Votes: 2},
+		{Bucket: start, OptionID: b, Votes: 1},
+		{Bucket: start.Add(time.Hour), OptionID: b, Votes: 1},
+		{Bucket: start.Add(3 * time.Hour), OptionID: b, Votes: 2},
+	}
+
+	timeline := entity.BuildTimeline(poll, entity.IntervalHour, counts)
+
+	require.Len(t, timeline.Buckets, 4)
+	assert.Equal(t, map[string]int{"A": 2, "B": 1}, byText(poll, timeline.Buckets[0].Cumulative))
+	assert.Equal(t, map[string]int{"A": 0, "B": 1}, byText(poll, timeline.Buckets[1].Votes))
+	assert.Equal(t, map[string]int{"A": 2, "B": 4}, byText(poll, timeline.Buckets[3].Cumulative))
+
+	// The hour without votes is kept with zero votes and unchanged totals
+	assert.Equal(t, start.Add(2*time.Hour), timeline.Buckets[2].Start)
+	assert.Equal(t, map[string]int{"A": 0, "B": 0}, byText(poll, timeline.Buckets[2].Votes))
+	assert.Equal(t, map[string]int{"A": 2, "B": 2}, byText(poll, timeline.Buckets[2].Cumulative))
+
+	// A leads first, keeps the lead through the tie, then B overtakes
+	assert.Equal(t, a, *timeline.Buckets[1].Leader)
+	assert.Equal(t, []entity.LeadChange{
+		{OptionID: a, At: start},
+		{OptionID: b, At: start.Add(3 * time.Hour)},
+	}, timeline.LeadChanges)
+}
+
+func TestBuildTimeline_NoVotes(t *testing.T) {
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+
+	timeline := entity.BuildTimeline(poll, entity.IntervalDay, nil)
+
+	assert.Empty(t, timeline.Buckets)
+	assert.Empty(t, timeline.LeadChanges)
+}
+
+func byText(poll *entity.Poll, counts map[uuid.UUID]int) map[string]int {
+	result := make(map[string]int, len(counts))
+	for _, opt := range poll.Options {
+		result[opt.OptionText] = counts[opt.ID]
+	}
+	return result
+}
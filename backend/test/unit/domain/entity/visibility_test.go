@@ -0,0 +1,73 @@
+package entity_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoll_ResultsVisibleTo(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	token := "owner-token"
+
+	tests := []struct {
+		name       string
+		visibility entity.ResultsVisibility
+		expiresAt  *time.Time
+		actor      entity.Actor
+		hasVoted   bool
+		want       bool
+	}{
+		{name: "Public", visibility: entity.ResultsPublic, want: true},
+		{name: "After vote - not voted", visibility: entity.ResultsAfterVote},
+		{name: "After vote - voted", visibility: entity.ResultsAfterVote, hasVoted: true, want: true},
+		{name: "After close - open", visibility: entity.ResultsAfterClose, hasVoted: true},
+		{name: "After close - expired", visibility: entity.ResultsAfterClose, expiresAt: &past, want: true},
+		{name: "Creator only - voter", visibility: entity.ResultsCreatorOnly, hasVoted: true},
+		{name: "Creator only - owner", visibility: entity.ResultsCreatorOnly, actor: entity.Actor{OwnerToken: token}, want: true},
+		{name: "Creator only - wrong token", visibility: entity.ResultsCreatorOnly, actor: entity.Actor{OwnerToken: "guess"}},
+		{name: "Creator only - admin", visibility: entity.ResultsCreatorOnly, actor: entity.Actor{IsAdmin: true}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, tt.expiresAt)
+			require.NoError(t, err)
+			poll.ResultsVisibility = tt.visibility
+			poll.OwnerTokenHash = entity.HashOwnerToken(token)
+
+			assert.Equal(t, tt.want, poll.ResultsVisibleTo(tt.actor, tt.hasVoted, now))
+		})
+	}
+}
+
+func TestPoll_ApplySettings_ResultsVisibility(t *testing.T) {
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, poll.ApplySettings(entity.PollSettings{}))
+	assert.Equal(t, entity.ResultsPublic, poll.ResultsVisibility)
+
+	err = poll.ApplySettings(entity.PollSettings{ResultsVisibility: "friends"})
+	assert.Equal(t, entity.ErrInvalidResultsVisibility, err)
+}
+
+func TestPoll_RedactResults(t *testing.T) {
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+	poll.Options[0].VoteCount = 3
+	poll.Options[0].Percentage = 100
+	original := poll.Options
+
+	poll.RedactResults()
+
+	assert.True(t, poll.ResultsHidden)
+	assert.Zero(t, poll.Options[0].VoteCount)
+	assert.Zero(t, poll.Options[0].Percentage)
+	// The original slice may be shared with a cache and must be left intact
+	assert.Equal(t, 3, original[0].VoteCount)
+}
@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := repository.Cursor{
+		Time:     time.Date(2025, 2, 16, 10, 30, 0, 123456000, time.UTC),
+		ID:       uuid.New(),
+		Backward: true,
+	}
+
+	decoded, err := repository.DecodeCursor(cursor.Encode())
+
+	assert.NoError(t, err)
+	assert.True(t, cursor.Time.Equal(decoded.Time))
+	assert.Equal(t, cursor.ID, decoded.ID)
+	assert.True(t, decoded.Backward)
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "Not base64", input: "%%%"},
+		{name: "Not JSON", input: "bm90LWpzb24"},
+		{name: "Missing fields", input: "e30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := repository.DecodeCursor(tt.input)
+			assert.Nil(t, cursor)
+			assert.ErrorIs(t, err, entity.ErrInvalidCursor)
+		})
+	}
+}
+
+func TestCursor_Matches(t *testing.T) {
+	cursor := repository.Cursor{Sort: repository.SortVotes, Votes: 4, ID: uuid.New()}
+
+	assert.True(t, cursor.Matches(repository.PollFilter{Sort: repository.SortVotes}))
+	assert.False(t, cursor.Matches(repository.PollFilter{Sort: repository.SortVotes, Ascending: true}))
+	assert.False(t, cursor.Matches(repository.PollFilter{}))
+
+	// The default sort is by creation time
+	created := repository.Cursor{Time: time.Now(), ID: uuid.New()}
+	assert.True(t, created.Matches(repository.PollFilter{Sort: repository.SortCreated}))
+}
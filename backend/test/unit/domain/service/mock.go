@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"time"
 
 	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
 	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
@@ -27,6 +28,22 @@ func (m *MockPollRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity
 	return nil, args.Error(1)
 }
 
+func (m *MockPollRepository) GetByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (*entity.Poll, error) {
+	args := m.Called(ctx, id)
+	if poll, ok := args.Get(0).(*entity.Poll); ok {
+		return poll, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockPollRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*entity.Poll, error) {
+	args := m.Called(ctx, ids)
+	if polls, ok := args.Get(0).([]*entity.Poll); ok {
+		return polls, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockPollRepository) Update(ctx context.Context, poll *entity.Poll) error {
 	args := m.Called(ctx, poll)
 	return args.Error(0)
@@ -37,10 +54,30 @@ func (m *MockPollRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
-func (m *MockPollRepository) List(ctx context.Context, page, limit int) ([]*entity.Poll, error) {
-	args := m.Called(ctx, page, limit)
-	if polls, ok := args.Get(0).([]*entity.Poll); ok {
-		return polls, args.Error(1)
+func (m *MockPollRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPollRepository) Archive(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPollRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPollRepository) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPollRepository) List(ctx context.Context, filter repository.PollFilter, page repository.PageRequest) (*repository.PollPage, error) {
+	args := m.Called(ctx, filter, page)
+	if result, ok := args.Get(0).(*repository.PollPage); ok {
+		return result, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
@@ -55,11 +92,21 @@ func (m *MockVoteRepository) Create(ctx context.Context, vote *entity.Vote) erro
 	return args.Error(0)
 }
 
-func (m *MockVoteRepository) HasVoted(ctx context.Context, pollID uuid.UUID, identifier entity.VoteIdentifier) (bool, error) {
-	args := m.Called(ctx, pollID, identifier)
+func (m *MockVoteRepository) HasVoted(ctx context.Context, pollID uuid.UUID, policy entity.DedupPolicy, identifier entity.VoteIdentifier) (bool, error) {
+	args := m.Called(ctx, pollID, policy, identifier)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockVoteRepository) ExpireLegacyIdentifiers(ctx context.Context, castBefore time.Time) (int64, error) {
+	args := m.Called(ctx, castBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockVoteRepository) CountSince(ctx context.Context, pollID uuid.UUID, since time.Time) (int, error) {
+	args := m.Called(ctx, pollID, since)
+	return args.Int(0), args.Error(1)
+}
+
 func (m *MockVoteRepository) GetPollStats(ctx context.Context, pollID uuid.UUID) (*entity.PollStats, error) {
 	args := m.Called(ctx, pollID)
 	if stats, ok := args.Get(0).(*entity.PollStats); ok {
@@ -68,6 +115,106 @@ func (m *MockVoteRepository) GetPollStats(ctx context.Context, pollID uuid.UUID)
 	return nil, args.Error(1)
 }
 
+// MockAccessRepository implements repository.AccessRepository
+type MockAccessRepository struct {
+	mock.Mock
+}
+
+func (m *MockAccessRepository) CreateInvite(ctx context.Context, invite *entity.Invite) error {
+	args := m.Called(ctx, invite)
+	return args.Error(0)
+}
+
+func (m *MockAccessRepository) RevokeInvite(ctx context.Context, pollID, inviteID uuid.UUID) error {
+	args := m.Called(ctx, pollID, inviteID)
+	return args.Error(0)
+}
+
+func (m *MockAccessRepository) RedeemInvite(ctx context.Context, pollID uuid.UUID, codeHash string) error {
+	args := m.Called(ctx, pollID, codeHash)
+	return args.Error(0)
+}
+
+func (m *MockAccessRepository) IsAllowlisted(ctx context.Context, pollID uuid.UUID, userID, email string) (bool, error) {
+	args := m.Called(ctx, pollID, userID, email)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockAuditRepository implements repository.AuditRepository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Record(ctx context.Context, event *entity.AuditEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) ListByPoll(ctx context.Context, pollID uuid.UUID) ([]entity.AuditEvent, error) {
+	args := m.Called(ctx, pollID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entity.AuditEvent), args.Error(1)
+}
+
+// MockAnalyticsRepository implements repository.AnalyticsRepository
+type MockAnalyticsRepository struct {
+	mock.Mock
+}
+
+func (m *MockAnalyticsRepository) TrendingScores(ctx context.Context, since time.Time, halfLife time.Duration, limit int) ([]entity.TrendingScore, error) {
+	args := m.Called(ctx, since, halfLife, limit)
+	if scores, ok := args.Get(0).([]entity.TrendingScore); ok {
+		return scores, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) VoteTimeline(ctx context.Context, pollID uuid.UUID, interval entity.TimelineInterval) ([]entity.TimelineCount, error) {
+	args := m.Called(ctx, pollID, interval)
+	if counts, ok := args.Get(0).([]entity.TimelineCount); ok {
+		return counts, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAnalyticsRepository) CrossTabCounts(ctx context.Context, rowPollID, columnPollID uuid.UUID) ([]entity.CrossTabCount, error) {
+	args := m.Called(ctx, rowPollID, columnPollID)
+	if counts, ok := args.Get(0).([]entity.CrossTabCount); ok {
+		return counts, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// MockQuarantineRepository implements repository.QuarantineRepository
+type MockQuarantineRepository struct {
+	mock.Mock
+}
+
+func (m *MockQuarantineRepository) CountSignal(ctx context.Context, vote *entity.Vote, signal entity.AnomalySignal, since time.Time) (int, error) {
+	args := m.Called(ctx, vote, signal, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockQuarantineRepository) Quarantine(ctx context.Context, voteID uuid.UUID, reasons []entity.AnomalySignal, score int) error {
+	args := m.Called(ctx, voteID, reasons, score)
+	return args.Error(0)
+}
+
+func (m *MockQuarantineRepository) ListQuarantined(ctx context.Context, pollID uuid.UUID) ([]entity.QuarantinedVote, error) {
+	args := m.Called(ctx, pollID)
+	if votes, ok := args.Get(0).([]entity.QuarantinedVote); ok {
+		return votes, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockQuarantineRepository) Review(ctx context.Context, pollID, voteID uuid.UUID, status entity.QuarantineStatus) error {
+	args := m.Called(ctx, pollID, voteID, status)
+	return args.Error(0)
+}
+
 // MockTransactionManager implements repository.TransactionManager
 type MockTransactionManager struct {
 	mock.Mock
@@ -75,15 +222,29 @@ type MockTransactionManager struct {
 
 func (m *MockTransactionManager) Begin(ctx context.Context) (repository.Transaction, error) {
 	args := m.Called(ctx)
-	if tx, ok := args.Get(0).(repository.Transaction); ok {
+	if tx, ok := args.Get(0).(*MockTransaction); ok {
+		tx.ctx = ctx
 		return tx, args.Error(1)
 	}
 	return nil, args.Error(1)
 }
 
-// MockTransaction implements repository.Transaction
+type mockTxKey struct{}
+
+// MockTransaction implements repository.Transaction. Repository calls made
+// inside it receive In(ctx), where ctx is the context passed to Begin.
 type MockTransaction struct {
 	mock.Mock
+	ctx context.Context
+}
+
+// In returns the context a transaction begun with ctx hands to repositories
+func (m *MockTransaction) In(ctx context.Context) context.Context {
+	return context.WithValue(ctx, mockTxKey{}, "tx")
+}
+
+func (m *MockTransaction) Context() context.Context {
+	return m.In(m.ctx)
 }
 
 func (m *MockTransaction) Commit() error {
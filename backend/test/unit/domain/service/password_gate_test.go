@@ -0,0 +1,166 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// attemptLimiter allows a fixed number of attempts per key
+type attemptLimiter struct {
+	mu        sync.Mutex
+	remaining map[string]int
+	limit     int
+}
+
+func (l *attemptLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.remaining[key]; !ok {
+		l.remaining[key] = l.limit
+	}
+	if l.remaining[key] == 0 {
+		return false
+	}
+	l.remaining[key]--
+	return true
+}
+
+func (l *attemptLimiter) Refund(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.remaining[key]++
+}
+
+func newProtectedPoll(t *testing.T) *entity.Poll {
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, poll.ApplySettings(entity.PollSettings{Password: "hunter22"}))
+	return poll
+}
+
+func TestPasswordGate_Authorize(t *testing.T) {
+	tokens, err := security.NewAccessTokens("test-secret", time.Minute)
+	require.NoError(t, err)
+	gate := service.NewPasswordGate(tokens, nil, &attemptLimiter{remaining: map[string]int{}, limit: 5})
+	poll := newProtectedPoll(t)
+
+	token, _, err := gate.Unlock(context.Background(), poll, "hunter22")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		actor   entity.Actor
+		wantErr error
+	}{
+		{name: "No credentials", wantErr: entity.ErrPasswordRequired},
+		{name: "Correct password", actor: entity.Actor{Password: "hunter22"}},
+		{name: "Wrong password", actor: entity.Actor{Password: "hunter2"}, wantErr: entity.ErrIncorrectPassword},
+		{name: "Access token", actor: entity.Actor{AccessToken: token}},
+		{name: "Forged access token", actor: entity.Actor{AccessToken: token + "x"}, wantErr: entity.ErrPasswordRequired},
+		{name: "Admin", actor: entity.Actor{IsAdmin: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := entity.ContextWithActor(context.Background(), tt.actor)
+			assert.Equal(t, tt.wantErr, gate.Authorize(ctx, poll))
+		})
+	}
+}
+
+func TestPasswordGate_LimitsFailedAttempts(t *testing.T) {
+	gate := service.NewPasswordGate(nil, nil, &attemptLimiter{remaining: map[string]int{}, limit: 2})
+	poll := newProtectedPoll(t)
+	client := entity.VoteIdentifier{IPHash: "client-ip"}
+	wrong := entity.ContextWithActor(context.Background(), entity.Actor{Identifier: client, Password: "wrong"})
+	right := entity.ContextWithActor(context.Background(), entity.Actor{Identifier: client, Password: "hunter22"})
+	other := entity.ContextWithActor(context.Background(), entity.Actor{
+		Identifier: entity.VoteIdentifier{IPHash: "other-ip"},
+		Password:   "hunter22",
+	})
+
+	assert.NoError(t, gate.Authorize(right, poll), "successes do not count")
+	assert.Equal(t, entity.ErrIncorrectPassword, gate.Authorize(wrong, poll))
+	assert.Equal(t, entity.ErrIncorrectPassword, gate.Authorize(wrong, poll))
+
+	// Once the limit is spent even the right password is refused
+	assert.Equal(t, entity.ErrTooManyPasswordAttempts, gate.Authorize(wrong, poll))
+	assert.Equal(t, entity.ErrTooManyPasswordAttempts, gate.Authorize(right, poll))
+
+	// Other clients are not locked out
+	assert.NoError(t, gate.Authorize(other, poll))
+}
+
+func TestPasswordGate_LimitsFailedAttemptsPerPoll(t *testing.T) {
+	perPoll := &attemptLimiter{remaining: map[string]int{}, limit: 3}
+	perClient := &attemptLimiter{remaining: map[string]int{}, limit: 2}
+	gate := service.NewPasswordGate(nil, perPoll, perClient)
+	poll := newProtectedPoll(t)
+	from := func(ip, password string) context.Context {
+		return entity.ContextWithActor(context.Background(), entity.Actor{
+			Identifier: entity.VoteIdentifier{IPHash: ip},
+			Password:   password,
+		})
+	}
+
+	assert.NoError(t, gate.Authorize(from("ip-1", "hunter22"), poll), "successes do not count")
+
+	// Rotating addresses only gets as many guesses as the poll allows
+	assert.Equal(t, entity.ErrIncorrectPassword, gate.Authorize(from("ip-1", "wrong"), poll))
+	assert.Equal(t, entity.ErrIncorrectPassword, gate.Authorize(from("ip-2", "wrong"), poll))
+	assert.Equal(t, entity.ErrIncorrectPassword, gate.Authorize(from("ip-3", "wrong"), poll))
+	assert.Equal(t, entity.ErrTooManyPasswordAttempts, gate.Authorize(from("ip-4", "wrong"), poll))
+	assert.Equal(t, entity.ErrTooManyPasswordAttempts, gate.Authorize(from("ip-5", "hunter22"), poll))
+
+	// A refusal by the poll limit does not use up the client's attempts
+	assert.Equal(t, 2, perClient.remaining[poll.ID.String()+":ip-4"])
+
+	// Other polls are unaffected
+	assert.NoError(t, gate.Authorize(from("ip-4", "hunter22"), newProtectedPoll(t)))
+}
+
+func TestPasswordGate_LimitsConcurrentAttempts(t *testing.T) {
+	const limit, guesses = 3, 20
+	gate := service.NewPasswordGate(nil, nil, &attemptLimiter{remaining: map[string]int{}, limit: limit})
+	poll := newProtectedPoll(t)
+	wrong := entity.ContextWithActor(context.Background(), entity.Actor{
+		Identifier: entity.VoteIdentifier{IPHash: "client-ip"},
+		Password:   "wrong",
+	})
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	counts := map[error]int{}
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := gate.Authorize(wrong, poll)
+			mu.Lock()
+			counts[err]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Only the allowed number of guesses reach the password comparison
+	assert.Equal(t, limit, counts[entity.ErrIncorrectPassword])
+	assert.Equal(t, guesses-limit, counts[entity.ErrTooManyPasswordAttempts])
+}
+
+func TestPasswordGate_UnprotectedPoll(t *testing.T) {
+	gate := service.NewPasswordGate(nil, nil, nil)
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+
+	assert.NoError(t, gate.Authorize(context.Background(), poll))
+	assert.False(t, poll.IsPasswordProtected())
+}
@@ -0,0 +1,151 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollService_UpdatePollAudit(t *testing.T) {
+	tests := []struct {
+		name       string
+		isActive   bool
+		question   string
+		wantAction entity.AuditAction
+		wantFields []string
+	}{
+		{name: "Edit", isActive: true, question: "Edited question?", wantAction: entity.AuditPollUpdated, wantFields: []string{"question"}},
+		{name: "Close", isActive: false, question: "Test question?", wantAction: entity.AuditPollClosed, wantFields: []string{"is_active"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+			require.NoError(t, err)
+			ctx := entity.ContextWithActor(context.Background(), entity.Actor{IsAdmin: true, RequestID: "req-7"})
+
+			m := newServiceMocks()
+			m.txManager.On("Begin", ctx).Return(m.tx, nil)
+			m.pollRepo.On("GetByID", m.tx.In(ctx), poll.ID).Return(poll, nil)
+			m.pollRepo.On("Update", m.tx.In(ctx), poll).Return(nil)
+			m.auditRepo.On("Record", m.tx.In(ctx), mock.MatchedBy(func(e *entity.AuditEvent) bool {
+				fields := make([]string, len(e.Changes))
+				for i, change := range e.Changes {
+					fields[i] = change.Field
+				}
+				return e.Action == tt.wantAction && e.Actor == "admin" && e.RequestID == "req-7" &&
+					assert.ObjectsAreEqual(tt.wantFields, fields)
+			})).Return(nil)
+			m.tx.On("Commit").Return(nil)
+			m.tx.On("Rollback").Return(nil)
+
+			err = m.service().UpdatePoll(ctx, poll.ID, tt.question, tt.isActive, nil)
+
+			assert.NoError(t, err)
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_UpdateArchivedPoll(t *testing.T) {
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+	archivedAt := time.Now()
+	poll.ArchivedAt = &archivedAt
+	ctx := context.Background()
+
+	m := newServiceMocks()
+	m.txManager.On("Begin", ctx).Return(m.tx, nil)
+	m.pollRepo.On("GetByID", m.tx.In(ctx), poll.ID).Return(poll, nil)
+	m.tx.On("Rollback").Return(nil)
+
+	err = m.service().UpdatePoll(ctx, poll.ID, "Edited question?", true, nil)
+
+	assert.ErrorIs(t, err, entity.ErrPollArchived)
+	m.pollRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	m.assertExpectations(t)
+}
+
+func TestPollService_RestorePollAudit(t *testing.T) {
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+	deletedAt := time.Now()
+	poll.DeletedAt = &deletedAt
+	ctx := context.Background()
+
+	m := newServiceMocks()
+	m.txManager.On("Begin", ctx).Return(m.tx, nil)
+	m.pollRepo.On("GetByIDIncludingDeleted", m.tx.In(ctx), poll.ID).Return(poll, nil)
+	m.pollRepo.On("Restore", m.tx.In(ctx), poll.ID).Return(nil)
+	m.auditRepo.On("Record", m.tx.In(ctx), mock.MatchedBy(func(e *entity.AuditEvent) bool {
+		return e.Action == entity.AuditPollRestored && len(e.Changes) == 1 &&
+			e.Changes[0].Field == "deleted_at" && e.Changes[0].After == (*time.Time)(nil)
+	})).Return(nil)
+	m.tx.On("Commit").Return(nil)
+	m.tx.On("Rollback").Return(nil)
+
+	assert.NoError(t, m.service().RestorePoll(ctx, poll.ID))
+	m.assertExpectations(t)
+}
+
+func TestPollService_AuditFailureRollsBack(t *testing.T) {
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	m := newServiceMocks()
+	m.txManager.On("Begin", ctx).Return(m.tx, nil)
+	m.pollRepo.On("GetByID", m.tx.In(ctx), poll.ID).Return(poll, nil)
+	m.pollRepo.On("Archive", m.tx.In(ctx), poll.ID).Return(nil)
+	m.auditRepo.On("Record", m.tx.In(ctx), mock.Anything).Return(errors.New("disk full"))
+	m.tx.On("Rollback").Return(nil)
+
+	assert.Error(t, m.service().ArchivePoll(ctx, poll.ID))
+	m.tx.AssertNotCalled(t, "Commit")
+	m.assertExpectations(t)
+}
+
+func TestPollService_AuditLog(t *testing.T) {
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+	poll.OwnerToken = "owner-token"
+	poll.OwnerTokenHash = entity.HashOwnerToken(poll.OwnerToken)
+	events := []entity.AuditEvent{{PollID: poll.ID, Action: entity.AuditPollCreated}}
+
+	tests := []struct {
+		name    string
+		actor   entity.Actor
+		wantErr error
+	}{
+		{name: "Owner", actor: entity.Actor{OwnerToken: poll.OwnerToken}},
+		{name: "Admin", actor: entity.Actor{IsAdmin: true}},
+		{name: "Anyone else", actor: entity.Actor{OwnerToken: "guess"}, wantErr: entity.ErrNotPollOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := entity.ContextWithActor(context.Background(), tt.actor)
+			m := newServiceMocks()
+			m.pollRepo.On("GetByIDIncludingDeleted", ctx, poll.ID).Return(poll, nil)
+			if tt.wantErr == nil {
+				m.auditRepo.On("ListByPoll", ctx, poll.ID).Return(events, nil)
+			}
+
+			got, err := m.service().AuditLog(ctx, poll.ID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, events, got)
+			}
+			m.assertExpectations(t)
+		})
+	}
+}
@@ -2,42 +2,81 @@ package service_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
 	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
+type serviceMocks struct {
+	pollRepo   *MockPollRepository
+	voteRepo   *MockVoteRepository
+	accessRepo *MockAccessRepository
+	auditRepo  *MockAuditRepository
+	txManager  *MockTransactionManager
+	eventBus   *MockEventBus
+	tx         *MockTransaction
+	gate       *service.PasswordGate
+}
+
+func newServiceMocks() *serviceMocks {
+	return &serviceMocks{
+		pollRepo:   new(MockPollRepository),
+		voteRepo:   new(MockVoteRepository),
+		accessRepo: new(MockAccessRepository),
+		auditRepo:  new(MockAuditRepository),
+		txManager:  new(MockTransactionManager),
+		eventBus:   new(MockEventBus),
+		tx:         new(MockTransaction),
+		gate:       service.NewPasswordGate(nil, nil, nil),
+	}
+}
+
+func (m *serviceMocks) service() service.PollService {
+	return service.NewPollService(m.pollRepo, m.voteRepo, m.accessRepo, m.auditRepo, m.txManager, m.eventBus, m.gate)
+}
+
+func (m *serviceMocks) assertExpectations(t *testing.T) {
+	m.pollRepo.AssertExpectations(t)
+	m.voteRepo.AssertExpectations(t)
+	m.accessRepo.AssertExpectations(t)
+	m.auditRepo.AssertExpectations(t)
+	m.txManager.AssertExpectations(t)
+	m.tx.AssertExpectations(t)
+	m.eventBus.AssertExpectations(t)
+}
+
 // Test cases
 func TestPollService_CreatePoll(t *testing.T) {
 	ctx := context.Background()
-	pollRepo := new(MockPollRepository)
-	voteRepo := new(MockVoteRepository)
-	txManager := new(MockTransactionManager)
-	eventBus := new(MockEventBus)
-	tx := new(MockTransaction)
-
-	pollService := service.NewPollService(pollRepo, voteRepo, txManager, eventBus)
 
 	tests := []struct {
 		name      string
 		question  string
 		options   []string
 		expiresAt *time.Time
-		mockSetup func()
+		mockSetup func(m *serviceMocks)
 		wantErr   bool
 	}{
 		{
 			name:     "Successful poll creation",
 			question: "Test question?",
 			options:  []string{"Option 1", "Option 2"},
-			mockSetup: func() {
-				txManager.On("Begin", ctx).Return(tx, nil)
-				pollRepo.On("Create", ctx, mock.AnythingOfType("*entity.Poll")).Return(nil)
-				tx.On("Commit").Return(nil)
-				eventBus.On("Publish", mock.AnythingOfType("service.PollCreatedEvent")).Return()
+			mockSetup: func(m *serviceMocks) {
+				m.txManager.On("Begin", ctx).Return(m.tx, nil)
+				m.pollRepo.On("Create", m.tx.In(ctx), mock.AnythingOfType("*entity.Poll")).Return(nil)
+				m.auditRepo.On("Record", m.tx.In(ctx), mock.MatchedBy(func(e *entity.AuditEvent) bool {
+					return e.Action == entity.AuditPollCreated
+				})).Return(nil)
+				m.tx.On("Commit").Return(nil)
+				m.tx.On("Rollback").Return(nil)
+				m.eventBus.On("Publish", mock.AnythingOfType("service.PollCreatedEvent")).Return()
 			},
 			wantErr: false,
 		},
@@ -45,10 +84,10 @@ func TestPollService_CreatePoll(t *testing.T) {
 			name:     "Failed poll creation - database error",
 			question: "Test question?",
 			options:  []string{"Option 1", "Option 2"},
-			mockSetup: func() {
-				txManager.On("Begin", ctx).Return(tx, nil)
-				pollRepo.On("Create", ctx, mock.AnythingOfType("*entity.Poll")).Return(assert.AnError)
-				tx.On("Rollback").Return(nil)
+			mockSetup: func(m *serviceMocks) {
+				m.txManager.On("Begin", ctx).Return(m.tx, nil)
+				m.pollRepo.On("Create", m.tx.In(ctx), mock.AnythingOfType("*entity.Poll")).Return(assert.AnError)
+				m.tx.On("Rollback").Return(nil)
 			},
 			wantErr: true,
 		},
@@ -57,10 +96,11 @@ func TestPollService_CreatePoll(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup mocks
-			tt.mockSetup()
+			m := newServiceMocks()
+			tt.mockSetup(m)
 
 			// Execute test
-			poll, err := pollService.CreatePoll(ctx, tt.question, tt.options, tt.expiresAt)
+			poll, err := m.service().CreatePoll(ctx, tt.question, tt.options, tt.expiresAt, entity.PollSettings{})
 
 			// Assert results
 			if tt.wantErr {
@@ -74,10 +114,401 @@ func TestPollService_CreatePoll(t *testing.T) {
 			}
 
 			// Verify mock expectations
-			pollRepo.AssertExpectations(t)
-			txManager.AssertExpectations(t)
-			tx.AssertExpectations(t)
-			eventBus.AssertExpectations(t)
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_DeletePoll(t *testing.T) {
+	ctx := context.Background()
+	pollID := uuid.New()
+
+	tests := []struct {
+		name      string
+		mockSetup func(m *serviceMocks)
+		wantErr   error
+	}{
+		{
+			name: "Soft deletes existing poll",
+			mockSetup: func(m *serviceMocks) {
+				m.txManager.On("Begin", ctx).Return(m.tx, nil)
+				m.pollRepo.On("GetByID", m.tx.In(ctx), pollID).Return(&entity.Poll{ID: pollID}, nil)
+				m.pollRepo.On("SoftDelete", m.tx.In(ctx), pollID).Return(nil)
+				m.auditRepo.On("Record", m.tx.In(ctx), mock.MatchedBy(func(e *entity.AuditEvent) bool {
+					return e.Action == entity.AuditPollDeleted
+				})).Return(nil)
+				m.tx.On("Commit").Return(nil)
+				m.tx.On("Rollback").Return(nil)
+			},
+		},
+		{
+			name: "Missing poll",
+			mockSetup: func(m *serviceMocks) {
+				m.txManager.On("Begin", ctx).Return(m.tx, nil)
+				m.pollRepo.On("GetByID", m.tx.In(ctx), pollID).Return(nil, entity.ErrPollNotFound)
+				m.tx.On("Rollback").Return(nil)
+			},
+			wantErr: entity.ErrPollNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newServiceMocks()
+			tt.mockSetup(m)
+
+			err := m.service().DeletePoll(ctx, pollID)
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Hard deletes must never happen on the request path
+			m.pollRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_PurgeDeletedPolls(t *testing.T) {
+	ctx := context.Background()
+	m := newServiceMocks()
+	retention := 24 * time.Hour
+
+	m.pollRepo.On("PurgeDeleted", ctx, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= retention
+	})).Return(int64(3), nil)
+
+	purged, err := m.service().PurgeDeletedPolls(ctx, retention)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	m.assertExpectations(t)
+}
+
+func TestPollService_ExpireLegacyVoterHashes(t *testing.T) {
+	ctx := context.Background()
+	m := newServiceMocks()
+	retention := 30 * 24 * time.Hour
+
+	m.voteRepo.On("ExpireLegacyIdentifiers", ctx, mock.MatchedBy(func(before time.Time) bool {
+		return time.Since(before) >= retention
+	})).Return(int64(12), nil)
+
+	expired, err := m.service().ExpireLegacyVoterHashes(ctx, retention)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), expired)
+	m.assertExpectations(t)
+}
+
+func TestPollService_ListPolls(t *testing.T) {
+	ctx := context.Background()
+	cursor := &repository.Cursor{Time: time.Now(), ID: uuid.New()}
+
+	tests := []struct {
+		name string
+		in   repository.PageRequest
+		want repository.PageRequest
+	}{
+		{
+			name: "Defaults to first page",
+			in:   repository.PageRequest{},
+			want: repository.PageRequest{Limit: 10, Page: 1},
+		},
+		{
+			name: "Cursor overrides page number",
+			in:   repository.PageRequest{Limit: 20, Page: 3, Cursor: cursor},
+			want: repository.PageRequest{Limit: 20, Cursor: cursor},
+		},
+		{
+			name: "Oversized limit is clamped",
+			in:   repository.PageRequest{Limit: 500, Page: 2, IncludeTotal: true},
+			want: repository.PageRequest{Limit: 10, Page: 2, IncludeTotal: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newServiceMocks()
+			m.pollRepo.On("List", ctx, repository.PollFilter{}, tt.want).Return(&repository.PollPage{}, nil)
+
+			_, err := m.service().ListPolls(ctx, repository.PollFilter{}, tt.in)
+
+			assert.NoError(t, err)
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_ListPolls_InvalidFilter(t *testing.T) {
+	m := newServiceMocks()
+
+	_, err := m.service().ListPolls(context.Background(), repository.PollFilter{Sort: "popularity"}, repository.PageRequest{})
+
+	assert.ErrorIs(t, err, entity.ErrValidation)
+	m.pollRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPollService_GetPollStats_ResultsVisibility(t *testing.T) {
+	voter := entity.NewVoteIdentifier("ip-hash", "fingerprint-hash")
+
+	tests := []struct {
+		name       string
+		visibility entity.ResultsVisibility
+		actor      entity.Actor
+		hasVoted   *bool
+		wantHidden bool
+	}{
+		{name: "Public results", visibility: entity.ResultsPublic},
+		{name: "After vote - voted", visibility: entity.ResultsAfterVote, actor: entity.Actor{Identifier: voter}, hasVoted: boolPtr(true)},
+		{name: "After vote - not voted", visibility: entity.ResultsAfterVote, actor: entity.Actor{Identifier: voter}, hasVoted: boolPtr(false), wantHidden: true},
+		{name: "After vote - anonymous", visibility: entity.ResultsAfterVote, wantHidden: true},
+		{name: "Creator only - admin", visibility: entity.ResultsCreatorOnly, actor: entity.Actor{IsAdmin: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := entity.ContextWithActor(context.Background(), tt.actor)
+			m := newServiceMocks()
+
+			poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+			assert.NoError(t, err)
+			poll.ResultsVisibility = tt.visibility
+
+			stats := entity.NewPollStats([]entity.OptionStats{
+				{OptionID: poll.Options[0].ID, VoteCount: 30},
+				{OptionID: poll.Options[1].ID, VoteCount: 10},
+			})
+
+			m.pollRepo.On("GetByID", ctx, poll.ID).Return(poll, nil)
+			m.voteRepo.On("GetPollStats", ctx, poll.ID).Return(stats, nil)
+			if tt.hasVoted != nil {
+				m.voteRepo.On("HasVoted", ctx, poll.ID, entity.DedupBoth, voter).Return(*tt.hasVoted, nil)
+			}
+
+			result, err := m.service().GetPollStats(ctx, poll.ID)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHidden, result.ResultsHidden)
+			if tt.wantHidden {
+				assert.Zero(t, result.TotalVotes)
+				assert.Nil(t, result.Leader)
+				assert.Zero(t, result.Options[0].VoteCount)
+			} else {
+				assert.Equal(t, 40, result.TotalVotes)
+			}
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestPollService_Vote_PrivatePoll(t *testing.T) {
+	identifier := entity.NewVoteIdentifier("ip-hash", "fingerprint-hash")
+
+	tests := []struct {
+		name      string
+		actor     entity.Actor
+		mockSetup func(m *serviceMocks, ctx context.Context, pollID uuid.UUID)
+		saveErr   error
+		wantErr   error
+	}{
+		{
+			name:    "No invite or allowlist entry",
+			wantErr: entity.ErrPollPrivate,
+		},
+		{
+			name:  "Vote not saved",
+			actor: entity.Actor{InviteCode: "abcd-code"},
+			mockSetup: func(m *serviceMocks, ctx context.Context, pollID uuid.UUID) {
+				m.accessRepo.On("RedeemInvite", m.tx.In(ctx), pollID, entity.HashInviteCode("ABCD-CODE")).Return(nil)
+			},
+			saveErr: entity.ErrDuplicateVote,
+			wantErr: entity.ErrDuplicateVote,
+		},
+		{
+			name:  "Valid invite code",
+			actor: entity.Actor{InviteCode: "abcd-code"},
+			mockSetup: func(m *serviceMocks, ctx context.Context, pollID uuid.UUID) {
+				m.accessRepo.On("RedeemInvite", m.tx.In(ctx), pollID, entity.HashInviteCode("ABCD-CODE")).Return(nil)
+			},
+		},
+		{
+			name:  "Used up invite code",
+			actor: entity.Actor{InviteCode: "abcd-code"},
+			mockSetup: func(m *serviceMocks, ctx context.Context, pollID uuid.UUID) {
+				m.accessRepo.On("RedeemInvite", m.tx.In(ctx), pollID, mock.Anything).Return(entity.ErrInvalidInvite)
+			},
+			wantErr: entity.ErrInvalidInvite,
+		},
+		{
+			name:  "Allowlisted user skips the invite",
+			actor: entity.Actor{UserID: "u-42", InviteCode: "abcd-code"},
+			mockSetup: func(m *serviceMocks, ctx context.Context, pollID uuid.UUID) {
+				m.accessRepo.On("IsAllowlisted", m.tx.In(ctx), pollID, "u-42", "").Return(true, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := entity.ContextWithActor(context.Background(), tt.actor)
+			m := newServiceMocks()
+
+			poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+			assert.NoError(t, err)
+			poll.Visibility = entity.VisibilityPrivate
+
+			m.txManager.On("Begin", ctx).Return(m.tx, nil)
+			m.tx.On("Rollback").Return(nil)
+			m.pollRepo.On("GetByID", m.tx.In(ctx), poll.ID).Return(poll, nil)
+			if tt.mockSetup != nil {
+				tt.mockSetup(m, ctx, poll.ID)
+			}
+			admitted := tt.wantErr == nil || tt.saveErr != nil
+			if admitted {
+				m.voteRepo.On("HasVoted", m.tx.In(ctx), poll.ID, entity.DedupBoth, identifier).Return(false, nil)
+				m.voteRepo.On("Create", m.tx.In(ctx), mock.AnythingOfType("*entity.Vote")).Return(tt.saveErr)
+			}
+			if tt.wantErr == nil {
+				m.pollRepo.On("Update", m.tx.In(ctx), poll).Return(nil)
+				m.tx.On("Commit").Return(nil)
+				m.eventBus.On("Publish", mock.AnythingOfType("service.VoteRecordedEvent")).Return()
+			}
+
+			err = m.service().Vote(ctx, poll.ID, poll.Options[0].ID, identifier)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				// The invite redemption is rolled back with the vote
+				m.tx.AssertNotCalled(t, "Commit")
+				if !admitted {
+					// Voters who are not admitted learn nothing about earlier votes
+					m.voteRepo.AssertNotCalled(t, "HasVoted", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+					m.voteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			m.assertExpectations(t)
 		})
 	}
 }
+
+func TestPollService_Vote_DedupPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     entity.DedupPolicy
+		identifier entity.VoteIdentifier
+		hasVoted   bool
+		wantErr    error
+	}{
+		{name: "IP only - new voter", policy: entity.DedupIP, identifier: entity.NewVoteIdentifier("ip-hash", "fp")},
+		{name: "Either - seen before", policy: entity.DedupEither, identifier: entity.NewVoteIdentifier("ip-hash", "fp"), hasVoted: true, wantErr: entity.ErrDuplicateVote},
+		{name: "User - anonymous", policy: entity.DedupUser, identifier: entity.NewVoteIdentifier("ip-hash", "fp"), wantErr: entity.ErrVoterNotAuthenticated},
+		{name: "User - signed in", policy: entity.DedupUser, identifier: entity.VoteIdentifier{IPHash: "ip-hash", FingerprintHash: "fp", UserID: "u-42"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			m := newServiceMocks()
+
+			poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+			assert.NoError(t, err)
+			poll.DedupPolicy = tt.policy
+
+			m.txManager.On("Begin", ctx).Return(m.tx, nil)
+			m.tx.On("Rollback").Return(nil)
+			m.pollRepo.On("GetByID", m.tx.In(ctx), poll.ID).Return(poll, nil)
+			if tt.wantErr != entity.ErrVoterNotAuthenticated {
+				m.voteRepo.On("HasVoted", m.tx.In(ctx), poll.ID, tt.policy, tt.identifier).Return(tt.hasVoted, nil)
+			}
+			if tt.wantErr == nil {
+				m.voteRepo.On("Create", m.tx.In(ctx), mock.MatchedBy(func(v *entity.Vote) bool {
+					return v.DedupPolicy == tt.policy && v.UserID == tt.identifier.UserID
+				})).Return(nil)
+				m.pollRepo.On("Update", m.tx.In(ctx), poll).Return(nil)
+				m.tx.On("Commit").Return(nil)
+				m.eventBus.On("Publish", mock.AnythingOfType("service.VoteRecordedEvent")).Return()
+			}
+
+			err = m.service().Vote(ctx, poll.ID, poll.Options[0].ID, tt.identifier)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				m.voteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestPollService_Vote_ProofOfWork(t *testing.T) {
+	identifier := entity.NewVoteIdentifier("ip-hash", "fingerprint-hash")
+
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	assert.NoError(t, err)
+	poll.ProofOfWork = true
+
+	t.Run("Missing solution", func(t *testing.T) {
+		ctx := context.Background()
+		m := newServiceMocks()
+		m.txManager.On("Begin", ctx).Return(m.tx, nil)
+		m.tx.On("Rollback").Return(nil)
+		m.pollRepo.On("GetByID", m.tx.In(ctx), poll.ID).Return(poll, nil)
+
+		err := m.service().Vote(ctx, poll.ID, poll.Options[0].ID, identifier)
+
+		assert.ErrorIs(t, err, entity.ErrProofOfWorkRequired)
+		m.assertExpectations(t)
+	})
+
+	t.Run("Verified solution", func(t *testing.T) {
+		ctx := entity.ContextWithActor(context.Background(), entity.Actor{ProofOfWork: true})
+		m := newServiceMocks()
+		m.txManager.On("Begin", ctx).Return(m.tx, nil)
+		m.tx.On("Rollback").Return(nil)
+		m.pollRepo.On("GetByID", m.tx.In(ctx), poll.ID).Return(poll, nil)
+		m.voteRepo.On("HasVoted", m.tx.In(ctx), poll.ID, entity.DedupBoth, identifier).Return(false, nil)
+		m.voteRepo.On("Create", m.tx.In(ctx), mock.AnythingOfType("*entity.Vote")).Return(nil)
+		m.pollRepo.On("Update", m.tx.In(ctx), poll).Return(nil)
+		m.tx.On("Commit").Return(nil)
+		m.eventBus.On("Publish", mock.AnythingOfType("service.VoteRecordedEvent")).Return()
+
+		err := m.service().Vote(ctx, poll.ID, poll.Options[0].ID, identifier)
+
+		assert.NoError(t, err)
+		m.assertExpectations(t)
+	})
+}
+
+func TestPollService_RecentVotes(t *testing.T) {
+	ctx := context.Background()
+	since := time.Now().Add(-time.Minute)
+
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	assert.NoError(t, err)
+
+	m := newServiceMocks()
+	m.pollRepo.On("GetByID", ctx, poll.ID).Return(poll, nil)
+
+	_, err = m.service().RecentVotes(ctx, poll.ID, since)
+	assert.ErrorIs(t, err, entity.ErrChallengeNotRequired)
+
+	poll.ProofOfWork = true
+	m.voteRepo.On("CountSince", ctx, poll.ID, since).Return(42, nil)
+
+	count, err := m.service().RecentVotes(ctx, poll.ID, since)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, count)
+	m.assertExpectations(t)
+}
@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var anomalyRules = []entity.AnomalyRule{
+	{Signal: entity.SignalSubnetBurst, Window: time.Minute, Threshold: 20, Weight: 1},
+	{Signal: entity.SignalFingerprintsPerIP, Window: 10 * time.Minute, Threshold: 5, Weight: 2},
+	{Signal: entity.SignalUserAgentBurst, Window: 10 * time.Second, Threshold: 10, Weight: 1},
+}
+
+func newSuspectVote() *entity.Vote {
+	return &entity.Vote{
+		ID:            uuid.New(),
+		PollID:        uuid.New(),
+		OptionID:      uuid.New(),
+		IPHash:        "ip-hash",
+		SubnetHash:    "subnet-hash",
+		UserAgentHash: "ua-hash",
+		CreatedAt:     time.Now(),
+	}
+}
+
+func TestAnomalyService_Inspect(t *testing.T) {
+	tests := []struct {
+		name            string
+		counts          map[entity.AnomalySignal]int
+		wantQuarantined bool
+		wantReasons     []entity.AnomalySignal
+		wantScore       int
+	}{
+		{
+			name:   "Normal traffic",
+			counts: map[entity.AnomalySignal]int{entity.SignalSubnetBurst: 3, entity.SignalFingerprintsPerIP: 1, entity.SignalUserAgentBurst: 2},
+		},
+		{
+			name:            "Subnet burst alone is below the threshold score",
+			counts:          map[entity.AnomalySignal]int{entity.SignalSubnetBurst: 21, entity.SignalFingerprintsPerIP: 1, entity.SignalUserAgentBurst: 2},
+			wantQuarantined: false,
+		},
+		{
+			name:            "Many fingerprints from one IP",
+			counts:          map[entity.AnomalySignal]int{entity.SignalSubnetBurst: 6, entity.SignalFingerprintsPerIP: 6, entity.SignalUserAgentBurst: 2},
+			wantQuarantined: true,
+			wantReasons:     []entity.AnomalySignal{entity.SignalFingerprintsPerIP},
+			wantScore:       2,
+		},
+		{
+			name:            "Subnet and user agent bursts together",
+			counts:          map[entity.AnomalySignal]int{entity.SignalSubnetBurst: 50, entity.SignalFingerprintsPerIP: 1, entity.SignalUserAgentBurst: 11},
+			wantQuarantined: true,
+			wantReasons:     []entity.AnomalySignal{entity.SignalSubnetBurst, entity.SignalUserAgentBurst},
+			wantScore:       2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := new(MockQuarantineRepository)
+			vote := newSuspectVote()
+
+			for _, rule := range anomalyRules {
+				repo.On("CountSignal", ctx, vote, rule.Signal, vote.CreatedAt.Add(-rule.Window)).Return(tt.counts[rule.Signal], nil)
+			}
+			if tt.wantQuarantined {
+				repo.On("Quarantine", ctx, vote.ID, tt.wantReasons, tt.wantScore).Return(nil)
+			}
+
+			svc := service.NewAnomalyService(repo, service.AnomalyOptions{Rules: anomalyRules, QuarantineScore: 2})
+			quarantined, err := svc.Inspect(ctx, vote)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantQuarantined, quarantined)
+			if !tt.wantQuarantined {
+				repo.AssertNotCalled(t, "Quarantine", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAnomalyService_Inspect_SkipsMissingSignals(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockQuarantineRepository)
+	vote := newSuspectVote()
+	vote.UserAgentHash = ""
+
+	repo.On("CountSignal", ctx, vote, entity.SignalSubnetBurst, mock.Anything).Return(0, nil)
+	repo.On("CountSignal", ctx, vote, entity.SignalFingerprintsPerIP, mock.Anything).Return(0, nil)
+
+	svc := service.NewAnomalyService(repo, service.AnomalyOptions{Rules: anomalyRules})
+	quarantined, err := svc.Inspect(ctx, vote)
+
+	assert.NoError(t, err)
+	assert.False(t, quarantined)
+	repo.AssertNotCalled(t, "CountSignal", ctx, vote, entity.SignalUserAgentBurst, mock.Anything)
+}
+
+func TestAnomalyService_ReviewVote(t *testing.T) {
+	ctx := context.Background()
+	pollID, voteID := uuid.New(), uuid.New()
+	repo := new(MockQuarantineRepository)
+	svc := service.NewAnomalyService(repo, service.AnomalyOptions{})
+
+	repo.On("Review", ctx, pollID, voteID, entity.QuarantineApproved).Return(nil).Once()
+	assert.NoError(t, svc.ReviewVote(ctx, pollID, voteID, true))
+
+	repo.On("Review", ctx, pollID, voteID, entity.QuarantineRejected).Return(entity.ErrQuarantinedVoteNotFound).Once()
+	assert.ErrorIs(t, svc.ReviewVote(ctx, pollID, voteID, false), entity.ErrQuarantinedVoteNotFound)
+
+	repo.AssertExpectations(t)
+}
+
+func TestHandleVoteRecorded(t *testing.T) {
+	repo := new(MockQuarantineRepository)
+	vote := newSuspectVote()
+	repo.On("CountSignal", mock.Anything, vote, mock.Anything, mock.Anything).Return(100, nil)
+	repo.On("Quarantine", mock.Anything, vote.ID, mock.Anything, 4).Return(nil)
+
+	svc := service.NewAnomalyService(repo, service.AnomalyOptions{Rules: anomalyRules})
+	handle := service.HandleVoteRecorded(svc, func(err error) { t.Fatal(err) })
+
+	handle(service.VoteRecordedEvent{Vote: vote})
+	handle(service.PollCreatedEvent{})
+
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,121 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTrendingService(pollRepo *MockPollRepository, analyticsRepo *MockAnalyticsRepository, window time.Duration) service.AnalyticsService {
+	return service.NewAnalyticsService(pollRepo, new(MockVoteRepository), analyticsRepo, service.TrendingOptions{
+		Window:     window,
+		HalfLife:   6 * time.Hour,
+		CacheTTL:   time.Minute,
+		MaxResults: 50,
+	}, service.CrossTabOptions{MinCellSize: 5}, service.NewPasswordGate(nil, nil, nil))
+}
+
+func TestAnalyticsService_TrendingPolls(t *testing.T) {
+	ctx := context.Background()
+	pollRepo := new(MockPollRepository)
+	analyticsRepo := new(MockAnalyticsRepository)
+
+	hot, _ := entity.NewPoll("Hot?", []string{"A", "B"}, nil)
+	warm, _ := entity.NewPoll("Warm?", []string{"A", "B"}, nil)
+	hidden, _ := entity.NewPoll("Hidden?", []string{"A", "B"}, nil)
+	hidden.ResultsVisibility = entity.ResultsCreatorOnly
+	gone := uuid.New()
+
+	analyticsRepo.On("TrendingScores", mock.Anything, mock.AnythingOfType("time.Time"), 6*time.Hour, 50).
+		Return([]entity.TrendingScore{
+			{PollID: hot.ID, RecentVotes: 48, Score: 30.5},
+			{PollID: gone, RecentVotes: 20, Score: 10},
+			{PollID: hidden.ID, RecentVotes: 15, Score: 8},
+			{PollID: warm.ID, RecentVotes: 12, Score: 4.2},
+		}, nil).Once()
+	// One lookup for the whole ranking; deleted polls are simply missing
+	pollRepo.On("GetByIDs", mock.Anything, []uuid.UUID{hot.ID, gone, hidden.ID, warm.ID}).
+		Return([]*entity.Poll{hot, hidden, warm}, nil).Once()
+
+	analyticsService := newTrendingService(pollRepo, analyticsRepo, 24*time.Hour)
+
+	// Polls with hidden results are left out rather than revealing their votes
+	trending, err := analyticsService.TrendingPolls(ctx, 10)
+	assert.NoError(t, err)
+	assert.Len(t, trending.Polls, 2)
+	assert.Equal(t, hot.ID, trending.Polls[0].Poll.ID)
+	assert.Equal(t, warm.ID, trending.Polls[1].Poll.ID)
+	assert.InDelta(t, 2.0, trending.Polls[0].VotesPerHour, 0.001)
+
+	// Served from cache: the repositories are not queried again
+	top, err := analyticsService.TrendingPolls(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, top.Polls, 1)
+	assert.Len(t, trending.Polls, 2, "trimming must not alias the cached ranking")
+
+	pollRepo.AssertExpectations(t)
+	analyticsRepo.AssertExpectations(t)
+}
+
+func TestAnalyticsService_TrendingPolls_RepositoryError(t *testing.T) {
+	pollRepo := new(MockPollRepository)
+	analyticsRepo := new(MockAnalyticsRepository)
+
+	poll, _ := entity.NewPoll("Hot?", []string{"A", "B"}, nil)
+	analyticsRepo.On("TrendingScores", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return([]entity.TrendingScore{{PollID: poll.ID, RecentVotes: 1}}, nil)
+	pollRepo.On("GetByIDs", mock.Anything, mock.Anything).Return(nil, assert.AnError).Once()
+	pollRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*entity.Poll{poll}, nil).Once()
+
+	analyticsService := newTrendingService(pollRepo, analyticsRepo, 0)
+
+	// Lookup failures are reported rather than dropping polls, and are not cached
+	_, err := analyticsService.TrendingPolls(context.Background(), 10)
+	assert.ErrorIs(t, err, assert.AnError)
+
+	trending, err := analyticsService.TrendingPolls(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, trending.Polls, 1)
+	assert.Zero(t, trending.Polls[0].VotesPerHour, "an empty window must not divide by zero")
+}
+
+func TestAnalyticsService_TrendingPolls_SharedRefresh(t *testing.T) {
+	pollRepo := new(MockPollRepository)
+	analyticsRepo := new(MockAnalyticsRepository)
+
+	release := make(chan struct{})
+	analyticsRepo.On("TrendingScores", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Run(func(mock.Arguments) { <-release }).Return([]entity.TrendingScore{}, nil).Once()
+	pollRepo.On("GetByIDs", mock.Anything, mock.Anything).Return([]*entity.Poll{}, nil).Once()
+
+	analyticsService := newTrendingService(pollRepo, analyticsRepo, time.Hour)
+
+	// The caller that started the refresh goes away; the refresh carries on
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := analyticsService.TrendingPolls(cancelled, 10)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Concurrent callers wait for the same refresh
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := analyticsService.TrendingPolls(context.Background(), 10)
+			assert.NoError(t, err)
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	analyticsRepo.AssertExpectations(t)
+	pollRepo.AssertExpectations(t)
+}
@@ -0,0 +1,100 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/handler"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/negotiate"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingPollService fails every GetPoll with err
+type failingPollService struct {
+	service.PollService
+	err error
+}
+
+func (s *failingPollService) GetPoll(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Poll, error) {
+	return nil, s.err
+}
+
+type stubVoters struct{}
+
+func (stubVoters) Identify(ip, fingerprint, userAgent string) entity.VoteIdentifier {
+	return entity.VoteIdentifier{IPHash: "ip-hash"}
+}
+
+func newPollEngine(err error) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewPollHandler(&failingPollService{err: err}, stubVoters{}, nil)
+
+	engine := gin.New()
+	engine.GET("/api/polls/:id", negotiate.Version(negotiate.Legacy), h.GetPoll)
+	engine.GET("/api/v1/polls/:id", negotiate.Version(negotiate.V1), h.GetPoll)
+	return engine
+}
+
+func TestErrors_WrappedDomainError(t *testing.T) {
+	engine := newPollEngine(fmt.Errorf("failed to get poll: %w", entity.ErrPollNotFound))
+
+	legacy := serve(engine, http.MethodGet, "/api/polls/"+uuid.NewString(), "", nil)
+	assert.Equal(t, http.StatusNotFound, legacy.Code)
+	errorData := decode(t, legacy)["error"].(map[string]interface{})
+	assert.Equal(t, "POLL_NOT_FOUND", errorData["code"])
+	assert.Equal(t, "Poll not found", errorData["message"])
+	assert.NotContains(t, errorData, "details")
+
+	v1 := serve(engine, http.MethodGet, "/api/v1/polls/"+uuid.NewString(), "", nil)
+	assert.Equal(t, http.StatusNotFound, v1.Code)
+	problem := decode(t, v1)
+	assert.Equal(t, "POLL_NOT_FOUND", problem["code"])
+	assert.Equal(t, negotiate.ProblemType("POLL_NOT_FOUND"), problem["type"])
+}
+
+func TestErrors_InternalDetailsDoNotLeak(t *testing.T) {
+	engine := newPollEngine(fmt.Errorf("failed to get poll: %w", errors.New("pq: password authentication failed for user \"polls\"")))
+
+	for _, path := range []string{"/api/polls/", "/api/v1/polls/"} {
+		rec := serve(engine, http.MethodGet, path+uuid.NewString(), "", nil)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Contains(t, rec.Body.String(), "INTERNAL_ERROR")
+		assert.NotContains(t, rec.Body.String(), "password authentication")
+	}
+}
+
+func TestErrors_InvalidPathParameter(t *testing.T) {
+	rec := serve(newPollEngine(nil), http.MethodGet, "/api/v1/polls/not-a-uuid", "", nil)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	problem := decode(t, rec)
+	assert.Equal(t, "VALIDATION_FAILED", problem["code"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "id", "message": "Must be a valid UUID"},
+	}, problem["errors"])
+	assert.NotContains(t, rec.Body.String(), "invalid UUID length")
+}
+
+func TestErrors_ValidationFields(t *testing.T) {
+	rec := serve(newEngine(t), http.MethodPut, "/api/v1/admin/log-level", `{"level":"loud"}`, nil)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	problem := decode(t, rec)
+	assert.Equal(t, "VALIDATION_FAILED", problem["code"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "level", "message": "Must be one of debug info warn error"},
+	}, problem["errors"])
+
+	legacy := serve(newEngine(t), http.MethodPut, "/api/admin/log-level", `{"level":`, nil)
+	errorData := decode(t, legacy)["error"].(map[string]interface{})
+	assert.Equal(t, "INVALID_REQUEST", errorData["code"])
+	assert.NotContains(t, errorData, "details")
+}
@@ -0,0 +1,163 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/logger"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/handler"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/middleware"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/negotiate"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const adminToken = "admin-token"
+
+// newEngine serves the log level endpoints under both API versions
+func newEngine(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	logCfg := &config.LoggerConfig{
+		Level:    "info",
+		Format:   "json",
+		Output:   "file",
+		FilePath: filepath.Join(t.TempDir(), "app.log"),
+	}
+	log, err := logger.NewLogger(logCfg)
+	require.NoError(t, err)
+
+	m := middleware.NewMiddleware(log, &config.AdminConfig{Token: adminToken}, &config.AuthConfig{}, logCfg)
+	h := handler.NewAdminHandler(zap.NewAtomicLevelAt(zap.InfoLevel))
+
+	engine := gin.New()
+	engine.Use(m.RequestID(), m.Admin())
+	for prefix, version := range map[string]string{"/api": negotiate.Legacy, "/api/v1": negotiate.V1} {
+		admin := engine.Group(prefix+"/admin", negotiate.Version(version), m.RequireAdmin())
+		admin.GET("/log-level", h.GetLogLevel)
+		admin.PUT("/log-level", h.SetLogLevel)
+	}
+	return engine
+}
+
+func serve(engine *gin.Engine, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	req.Header.Set("X-Request-ID", "req-1")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body
+}
+
+func TestResponse_LegacySuccessIsBare(t *testing.T) {
+	rec := serve(newEngine(t), http.MethodGet, "/api/admin/log-level", "", nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, map[string]interface{}{"level": "info"}, decode(t, rec))
+}
+
+func TestResponse_V1SuccessIsEnveloped(t *testing.T) {
+	rec := serve(newEngine(t), http.MethodGet, "/api/v1/admin/log-level", "", nil)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := decode(t, rec)
+	assert.Equal(t, true, body["success"])
+	assert.Equal(t, map[string]interface{}{"level": "info"}, body["data"])
+	meta := body["meta"].(map[string]interface{})
+	assert.Equal(t, "req-1", meta["request_id"])
+	assert.NotEmpty(t, meta["timestamp"])
+}
+
+func TestResponse_LegacyErrorEnvelope(t *testing.T) {
+	rec := serve(newEngine(t), http.MethodPut, "/api/admin/log-level", `{"level":"loud"}`, nil)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+	body := decode(t, rec)
+	assert.Equal(t, false, body["success"])
+	assert.Equal(t, "req-1", body["error"].(map[string]interface{})["request_id"])
+}
+
+func TestResponse_ProblemDetails(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		headers map[string]string
+	}{
+		{name: "V1", path: "/api/v1/admin/log-level"},
+		{name: "Legacy asking for problem details", path: "/api/admin/log-level", headers: map[string]string{
+			"Accept": "application/json;q=0.5, application/problem+json",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(newEngine(t), http.MethodPut, tt.path, `{"level":"loud"}`, tt.headers)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, negotiate.ProblemContentType, rec.Header().Get("Content-Type"))
+			body := decode(t, rec)
+			assert.Equal(t, float64(http.StatusBadRequest), body["status"])
+			assert.Equal(t, tt.path, body["instance"])
+			assert.Equal(t, "req-1", body["request_id"])
+			assert.NotEmpty(t, body["type"])
+			assert.NotEmpty(t, body["title"])
+		})
+	}
+}
+
+func TestResponse_MiddlewareErrors(t *testing.T) {
+	engine := newEngine(t)
+	noAdmin := map[string]string{"Authorization": "Bearer wrong-token"}
+
+	legacy := serve(engine, http.MethodGet, "/api/admin/log-level", "", noAdmin)
+	assert.Equal(t, http.StatusForbidden, legacy.Code)
+	assert.Equal(t, map[string]interface{}{"error": "Admin access required"}, decode(t, legacy))
+
+	v1 := serve(engine, http.MethodGet, "/api/v1/admin/log-level", "", noAdmin)
+	assert.Equal(t, http.StatusForbidden, v1.Code)
+	assert.Equal(t, negotiate.ProblemContentType, v1.Header().Get("Content-Type"))
+	assert.Equal(t, "ADMIN_REQUIRED", decode(t, v1)["code"])
+
+	// A refused problem type leaves legacy clients on the original format
+	refused := serve(engine, http.MethodGet, "/api/admin/log-level", "", map[string]string{
+		"Authorization": "Bearer wrong-token",
+		"Accept":        "application/problem+json;q=0",
+	})
+	assert.Equal(t, map[string]interface{}{"error": "Admin access required"}, decode(t, refused))
+}
+
+func TestResponse_AdminRequiresBearerToken(t *testing.T) {
+	engine := newEngine(t)
+
+	for name, header := range map[string]string{
+		"Missing":      "",
+		"Bare token":   adminToken,
+		"Other scheme": "Basic " + adminToken,
+		"Empty bearer": "Bearer ",
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := serve(engine, http.MethodGet, "/api/v1/admin/log-level", "", map[string]string{"Authorization": header})
+
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+			assert.Equal(t, "ADMIN_TOKEN_REQUIRED", decode(t, rec)["code"])
+		})
+	}
+}
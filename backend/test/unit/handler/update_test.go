@@ -0,0 +1,105 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/handler"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/negotiate"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// updatingPollService remembers the update it was asked to make and fails
+// it with err
+type updatingPollService struct {
+	service.PollService
+	err       error
+	id        uuid.UUID
+	question  string
+	isActive  bool
+	expiresAt *time.Time
+	called    bool
+}
+
+func (s *updatingPollService) UpdatePoll(ctx context.Context, id uuid.UUID, question string, isActive bool, expiresAt *time.Time) error {
+	s.called = true
+	s.id, s.question, s.isActive, s.expiresAt = id, question, isActive, expiresAt
+	return s.err
+}
+
+func updatePoll(t *testing.T, svc *updatingPollService, id, body string) (map[string]interface{}, int) {
+	gin.SetMode(gin.TestMode)
+	h := handler.NewPollHandler(svc, stubVoters{}, nil)
+
+	engine := gin.New()
+	engine.PUT("/api/v1/polls/:id", negotiate.Version(negotiate.V1), h.UpdatePoll)
+
+	rec := serve(engine, http.MethodPut, "/api/v1/polls/"+id, body, nil)
+	if rec.Body.Len() == 0 {
+		return nil, rec.Code
+	}
+	return decode(t, rec), rec.Code
+}
+
+func TestUpdatePoll(t *testing.T) {
+	svc := &updatingPollService{}
+	id := uuid.New()
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	_, code := updatePoll(t, svc, id.String(),
+		`{"question":"  Best colour?  ","is_active":false,"expires_at":"`+expiresAt.Format(time.RFC3339)+`"}`)
+
+	assert.Equal(t, http.StatusNoContent, code)
+	assert.Equal(t, id, svc.id)
+	assert.Equal(t, "Best colour?", svc.question)
+	assert.False(t, svc.isActive)
+	if assert.NotNil(t, svc.expiresAt) {
+		assert.True(t, expiresAt.Equal(*svc.expiresAt))
+	}
+}
+
+func TestUpdatePoll_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantFields map[string]string
+	}{
+		{
+			name:       "Missing fields",
+			body:       `{}`,
+			wantFields: map[string]string{"question": "This field is required", "is_active": "This field is required"},
+		},
+		{
+			name:       "Blank question",
+			body:       `{"question":"   ","is_active":true}`,
+			wantFields: map[string]string{"question": "This field is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &updatingPollService{}
+			problem, code := updatePoll(t, svc, uuid.NewString(), tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.Equal(t, "VALIDATION_FAILED", problem["code"])
+			assert.Equal(t, tt.wantFields, fieldErrors(problem))
+			assert.False(t, svc.called)
+		})
+	}
+}
+
+func TestUpdatePoll_ArchivedPoll(t *testing.T) {
+	svc := &updatingPollService{err: entity.ErrPollArchived}
+
+	problem, code := updatePoll(t, svc, uuid.NewString(), `{"question":"Best colour?","is_active":true}`)
+
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, "POLL_ARCHIVED", problem["code"])
+}
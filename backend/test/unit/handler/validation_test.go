@@ -0,0 +1,212 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/handler"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/negotiate"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPollService remembers the poll it was asked to create
+type recordingPollService struct {
+	service.PollService
+	question  string
+	options   []string
+	expiresAt *time.Time
+	called    bool
+}
+
+func (s *recordingPollService) CreatePoll(ctx context.Context, question string, options []string, expiresAt *time.Time, settings entity.PollSettings) (*entity.Poll, error) {
+	s.called = true
+	s.question, s.options, s.expiresAt = question, options, expiresAt
+	return entity.NewPoll(question, options, expiresAt)
+}
+
+// ListPolls validates the filter as the real service does
+func (s *recordingPollService) ListPolls(ctx context.Context, filter repository.PollFilter, page repository.PageRequest) (*repository.PollPage, error) {
+	s.called = true
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return &repository.PollPage{}, nil
+}
+
+func createPoll(t *testing.T, body string) (*recordingPollService, map[string]interface{}, int) {
+	gin.SetMode(gin.TestMode)
+	svc := &recordingPollService{}
+	h := handler.NewPollHandler(svc, stubVoters{}, nil)
+
+	engine := gin.New()
+	engine.POST("/api/v1/polls", negotiate.Version(negotiate.V1), h.CreatePoll)
+
+	rec := serve(engine, http.MethodPost, "/api/v1/polls", body, nil)
+	return svc, decode(t, rec), rec.Code
+}
+
+// fieldErrors maps each rejected field to its message
+func fieldErrors(problem map[string]interface{}) map[string]string {
+	fields := make(map[string]string)
+	errs, _ := problem["errors"].([]interface{})
+	for _, e := range errs {
+		field := e.(map[string]interface{})
+		fields[field["field"].(string)] = field["message"].(string)
+	}
+	return fields
+}
+
+func TestCreatePoll_NormalizesOptions(t *testing.T) {
+	svc, _, code := createPoll(t, `{"question":"  Best colour?  ","options":[" Red","Blue ","Red","  "]}`)
+
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, "Best colour?", svc.question)
+	assert.Equal(t, []string{"Red", "Blue"}, svc.options)
+	assert.Nil(t, svc.expiresAt, "a missing expiry must not become the zero time")
+}
+
+func TestCreatePoll_ValidationErrors(t *testing.T) {
+	tooMany := `"o1","o2","o3","o4","o5","o6","o7","o8","o9","o10","o11","o12","o13","o14","o15","o16","o17","o18","o19","o20","o21"`
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantFields map[string]string
+	}{
+		{
+			name:       "Missing fields",
+			body:       `{}`,
+			wantFields: map[string]string{"question": "This field is required", "options": "This field is required"},
+		},
+		{
+			name:       "Blank question",
+			body:       `{"question":"     ","options":["A","B"]}`,
+			wantFields: map[string]string{"question": "This field is required"},
+		},
+		{
+			name:       "Duplicates leave too few options",
+			body:       `{"question":"Best colour?","options":["Red"," Red "]}`,
+			wantFields: map[string]string{"options": "Minimum number of items is 2"},
+		},
+		{
+			name:       "Too many options",
+			body:       `{"question":"Best colour?","options":[` + tooMany + `]}`,
+			wantFields: map[string]string{"options": "Maximum number of items is 20"},
+		},
+		{
+			name:       "Long option",
+			body:       `{"question":"Best colour?","options":["A","` + strings.Repeat("x", 201) + `"]}`,
+			wantFields: map[string]string{"options[1]": "Option must be between 1 and 200 characters"},
+		},
+		{
+			name:       "Expiry in the past",
+			body:       `{"question":"Best colour?","options":["A","B"],"expires_at":"` + past + `"}`,
+			wantFields: map[string]string{"expires_at": "Time must be in the future"},
+		},
+		{
+			name:       "Invalid allowlist email",
+			body:       `{"question":"Best colour?","options":["A","B"],"allowlist":{"emails":["nope"]}}`,
+			wantFields: map[string]string{"allowlist.emails[0]": "Must be a valid email address"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, problem, code := createPoll(t, tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, code)
+			assert.Equal(t, "VALIDATION_FAILED", problem["code"])
+			assert.Equal(t, tt.wantFields, fieldErrors(problem))
+			assert.False(t, svc.called)
+		})
+	}
+}
+
+func TestCreatePoll_MalformedBody(t *testing.T) {
+	for _, body := range []string{
+		`{"question":`,
+		`{"question":"Best colour?","options":["A","B"]} trailing`,
+		`{"question":"Best colour?","options":["A","B"]}{}`,
+	} {
+		svc, problem, code := createPoll(t, body)
+
+		assert.Equal(t, http.StatusBadRequest, code, body)
+		assert.Equal(t, "INVALID_REQUEST", problem["code"], body)
+		assert.False(t, svc.called, body)
+	}
+}
+
+func TestQueryParams_ValidationErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	polls := handler.NewPollHandler(&recordingPollService{}, stubVoters{}, nil)
+	analytics := handler.NewAnalyticsHandler(nil, stubVoters{})
+
+	engine := gin.New()
+	engine.GET("/api/v1/polls", negotiate.Version(negotiate.V1), polls.ListPolls)
+	engine.GET("/api/v1/polls/trending", negotiate.Version(negotiate.V1), analytics.TrendingPolls)
+	engine.GET("/api/v1/polls/:id/timeline", negotiate.Version(negotiate.V1), analytics.PollTimeline)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantFields map[string]string
+	}{
+		{
+			name: "Pagination",
+			path: "/api/v1/polls?page=0&limit=500",
+			wantFields: map[string]string{
+				"page":  "Minimum value is 1",
+				"limit": "Maximum value is 100",
+			},
+		},
+		{
+			name: "Order and dates",
+			path: "/api/v1/polls?order=sideways&created_after=yesterday&expires_before=2024-01-01",
+			wantFields: map[string]string{
+				"order":          "Must be one of asc desc",
+				"created_after":  "Must be an RFC3339 time",
+				"expires_before": "Must be an RFC3339 time",
+			},
+		},
+		{
+			name: "Status, sort and ranges",
+			path: "/api/v1/polls?status=open&sort=popularity&created_after=2024-02-01T00:00:00Z&created_before=2024-01-01T00:00:00Z",
+			wantFields: map[string]string{
+				"status":        "Must be one of active expired scheduled",
+				"sort":          "Must be one of created votes expiry",
+				"created_after": "Must not be after created_before",
+			},
+		},
+		{
+			name:       "Trending limit",
+			path:       "/api/v1/polls/trending?limit=ten",
+			wantFields: map[string]string{"limit": "Must be an integer"},
+		},
+		{
+			name:       "Timeline bucket",
+			path:       "/api/v1/polls/" + uuid.NewString() + "/timeline?bucket=1y",
+			wantFields: map[string]string{"bucket": "Must be one of 1m 1h 1d 1w"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(engine, http.MethodGet, tt.path, "", nil)
+			problem := decode(t, rec)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, "VALIDATION_FAILED", problem["code"])
+			assert.Equal(t, tt.wantFields, fieldErrors(problem))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/health"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBus struct {
+	pending int
+	stopped bool
+}
+
+func (b fakeBus) Pending() int  { return b.pending }
+func (b fakeBus) Stopped() bool { return b.stopped }
+
+type fakeMigrator struct {
+	version int64
+	latest  int64
+}
+
+func (m fakeMigrator) Version(ctx context.Context) (int64, error) { return m.version, nil }
+func (m fakeMigrator) Latest() int64                              { return m.latest }
+
+func passing(ctx context.Context) (map[string]interface{}, error) {
+	return map[string]interface{}{"ok": true}, nil
+}
+
+func readyz(t *testing.T, registry *health.Registry) (int, health.Report) {
+	return readyzAs(t, registry, true)
+}
+
+func readyzAs(t *testing.T, registry *health.Registry, admin bool) (int, health.Report) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/readyz", registry.Readyz(func(c *gin.Context) bool { return admin }))
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report health.Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	return rec.Code, report
+}
+
+func TestRegistry_Readyz(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register("database", passing)
+
+	code, report := readyz(t, registry)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusUp, report.Status)
+	assert.Equal(t, health.StatusUp, report.Checks["database"].Status)
+	assert.Equal(t, true, report.Checks["database"].Detail["ok"])
+
+	registry.Register("event_bus", health.EventBus(fakeBus{stopped: true}, 10))
+
+	code, report = readyz(t, registry)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, health.StatusUp, report.Checks["database"].Status)
+	assert.Equal(t, health.StatusDown, report.Checks["event_bus"].Status)
+	assert.Equal(t, "event bus is stopped", report.Checks["event_bus"].Error)
+}
+
+func TestRegistry_ReadyzHidesDetailsFromNonAdmins(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register("database", passing)
+	registry.Register("event_bus", health.EventBus(fakeBus{stopped: true}, 10))
+
+	code, report := readyzAs(t, registry, false)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, health.StatusUp, report.Checks["database"].Status)
+	assert.Nil(t, report.Checks["database"].Detail)
+	assert.Equal(t, health.StatusDown, report.Checks["event_bus"].Status)
+	assert.Empty(t, report.Checks["event_bus"].Error)
+}
+
+func TestRegistry_Health(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register("event_bus", health.EventBus(fakeBus{stopped: true}, 10))
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/health", registry.Health())
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"healthy"}`, rec.Body.String())
+}
+
+func TestRegistry_Timeout(t *testing.T) {
+	registry := health.NewRegistry(10 * time.Millisecond)
+	registry.Register("database", health.DatabasePing(pingFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})))
+
+	report := registry.Run(context.Background())
+	assert.False(t, report.Ready())
+	assert.Contains(t, report.Checks["database"].Error, context.DeadlineExceeded.Error())
+}
+
+func TestRegistry_MarkShuttingDown(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register("database", passing)
+	registry.MarkShuttingDown()
+
+	code, report := readyz(t, registry)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.True(t, report.ShuttingDown)
+	assert.Equal(t, health.StatusUp, report.Checks["database"].Status)
+
+	// Liveness is unaffected so the process is not restarted mid-drain
+	engine := gin.New()
+	engine.GET("/livez", registry.Livez())
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestChecks(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		check   health.Check
+		wantErr bool
+	}{
+		{"event bus keeping up", health.EventBus(fakeBus{pending: 3}, 10), false},
+		{"event bus backlog", health.EventBus(fakeBus{pending: 10}, 10), true},
+		{"schema current", health.MigrationVersion(fakeMigrator{version: 13, latest: 13}), false},
+		{"schema behind", health.MigrationVersion(fakeMigrator{version: 12, latest: 13}), true},
+		{"database answers", health.DatabasePing(pingFunc(func(context.Context) error { return nil })), false},
+		{"database down", health.DatabasePing(pingFunc(func(context.Context) error { return errors.New("refused") })), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.check(ctx)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+type pingFunc func(ctx context.Context) error
+
+func (f pingFunc) Ping(ctx context.Context) error { return f(ctx) }
@@ -0,0 +1,112 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func fileConfig(t *testing.T, format string) *config.LoggerConfig {
+	return &config.LoggerConfig{
+		Level:            "info",
+		Format:           format,
+		Output:           "file",
+		FilePath:         filepath.Join(t.TempDir(), "app.log"),
+		MaxSizeMB:        1,
+		MaxAgeDays:       1,
+		MaxBackups:       1,
+		SampleTick:       time.Minute,
+		SampleInitial:    2,
+		SampleThereafter: 100,
+	}
+}
+
+func readLines(t *testing.T, path string) []string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestNewLogger_Console(t *testing.T) {
+	cfg := fileConfig(t, "console")
+	log, err := logger.NewLogger(cfg)
+	require.NoError(t, err)
+
+	log.Info("hello", logger.String("who", "world"))
+
+	lines := readLines(t, cfg.FilePath)
+	require.Len(t, lines, 1)
+	assert.False(t, strings.HasPrefix(lines[0], "{"), "console output should not be JSON: %s", lines[0])
+	assert.Contains(t, lines[0], "INFO")
+	assert.Contains(t, lines[0], "hello")
+	assert.Contains(t, lines[0], `{"who": "world"}`)
+}
+
+func TestNewLogger_JSON(t *testing.T) {
+	cfg := fileConfig(t, "json")
+	log, err := logger.NewLogger(cfg)
+	require.NoError(t, err)
+
+	log.Info("hello")
+
+	lines := readLines(t, cfg.FilePath)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"msg":"hello"`)
+}
+
+func TestLogger_Level(t *testing.T) {
+	cfg := fileConfig(t, "json")
+	log, err := logger.NewLogger(cfg)
+	require.NoError(t, err)
+
+	child := log.With(logger.String("component", "test"))
+	child.Debug("hidden")
+
+	// Raising verbosity on the parent reaches loggers already derived from it
+	log.Level().SetLevel(zapcore.DebugLevel)
+	child.Debug("shown")
+
+	lines := readLines(t, cfg.FilePath)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"msg":"shown"`)
+}
+
+func TestLogger_Sampled(t *testing.T) {
+	cfg := fileConfig(t, "json")
+	log, err := logger.NewLogger(cfg)
+	require.NoError(t, err)
+
+	sampled := log.Sampled()
+	for i := 0; i < 10; i++ {
+		sampled.Info("request completed")
+	}
+	log.Info("unsampled")
+	log.Info("unsampled")
+	log.Info("unsampled")
+
+	lines := readLines(t, cfg.FilePath)
+	requests := 0
+	for _, line := range lines {
+		if strings.Contains(line, "request completed") {
+			requests++
+		}
+	}
+	assert.Equal(t, cfg.SampleInitial, requests)
+	assert.Len(t, lines, cfg.SampleInitial+3)
+}
+
+func TestNewLogger_InvalidLevel(t *testing.T) {
+	cfg := fileConfig(t, "json")
+	cfg.Level = "loud"
+
+	_, err := logger.NewLogger(cfg)
+	assert.Error(t, err)
+}
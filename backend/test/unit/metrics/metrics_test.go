@@ -0,0 +1,128 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/metrics"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/ratelimit"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/security"
+	"github.com/Sparker0i/cactro-polls/test/unit/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := metrics.NewMetrics()
+
+	engine := gin.New()
+	engine.Use(m.Middleware())
+	engine.GET("/api/polls/:id", func(c *gin.Context) {
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/api/polls/1", "/api/polls/2", "/nowhere"} {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `polls_http_requests_total{method="GET",route="/api/polls/:id",status="404"} 2`)
+	assert.Contains(t, body, `polls_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `polls_http_request_duration_seconds_count{method="GET",route="/api/polls/:id",status="404"} 2`)
+}
+
+func TestInstrumentPollService_Vote(t *testing.T) {
+	m := metrics.NewMetrics()
+	ctx := context.Background()
+
+	failing := metrics.InstrumentPollService(&testutil.VotingService{Err: entity.ErrDuplicateVote}, m)
+	_ = failing.Vote(ctx, uuid.New(), uuid.New(), entity.VoteIdentifier{})
+	_ = failing.Vote(ctx, uuid.New(), uuid.New(), entity.VoteIdentifier{})
+
+	succeeding := metrics.InstrumentPollService(&testutil.VotingService{}, m)
+	assert.NoError(t, succeeding.Vote(ctx, uuid.New(), uuid.New(), entity.VoteIdentifier{}))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `polls_votes_rejected_total{reason="duplicate"} 2`)
+	assert.Contains(t, body, `polls_votes_cast_total 1`)
+}
+
+func TestMetrics_ScrapeTimeSources(t *testing.T) {
+	m := metrics.NewMetrics()
+	m.RegisterEventBus(func() int { return 3 })
+	m.RegisterRateLimiter("poll_password", func() uint64 { return 7 })
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `polls_event_bus_pending_handlers 3`)
+	assert.Contains(t, body, `polls_rate_limiter_rejections_total{limiter="poll_password"} 7`)
+}
+
+func TestInstrumentChallenges_Verify(t *testing.T) {
+	m := metrics.NewMetrics()
+	issuer, err := security.NewChallenges(security.ChallengeOptions{
+		Secret:         "test-secret",
+		TTL:            time.Minute,
+		BaseDifficulty: 8,
+		MaxDifficulty:  8,
+		Window:         time.Minute,
+		VotesPerStep:   1,
+	}, nil)
+	require.NoError(t, err)
+	challenges := metrics.InstrumentChallenges(issuer, m)
+
+	pollID := uuid.New()
+	challenge, err := challenges.Issue(pollID, 0)
+	require.NoError(t, err)
+	assert.ErrorIs(t, challenges.Verify(context.Background(), challenge.Token, "not-a-solution", pollID), entity.ErrInvalidProofOfWork)
+	assert.ErrorIs(t, challenges.Verify(context.Background(), "forged", "0", pollID), entity.ErrInvalidProofOfWork)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `polls_votes_rejected_total{reason="challenge"} 2`)
+}
+
+func TestRegisterRateLimiter_PasswordGate(t *testing.T) {
+	m := metrics.NewMetrics()
+	limiter := ratelimit.NewRateLimiter(&config.RateLimitConfig{
+		Enabled:           true,
+		RequestsPerMinute: 2,
+		TTL:               time.Minute,
+	})
+	t.Cleanup(limiter.Stop)
+	m.RegisterRateLimiter("poll_password", limiter.Rejected)
+
+	poll, err := entity.NewPoll("Test question?", []string{"A", "B"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, poll.ApplySettings(entity.PollSettings{Password: "hunter22"}))
+
+	gate := service.NewPasswordGate(nil, nil, limiter)
+	ctx := entity.ContextWithActor(context.Background(), entity.Actor{
+		Identifier: entity.VoteIdentifier{IPHash: "client-ip"},
+		Password:   "wrong",
+	})
+	for i := 0; i < 5; i++ {
+		_ = gate.Authorize(ctx, poll)
+	}
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `polls_rate_limiter_rejections_total{limiter="poll_password"} 3`)
+}
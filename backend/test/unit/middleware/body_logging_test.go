@@ -0,0 +1,127 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/logger"
+	"github.com/Sparker0i/cactro-polls/internal/interface/api/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setup returns an engine logging to a file, and a function reading back
+// the last request log line
+func setup(t *testing.T, maxBytes int) (*gin.Engine, func() map[string]interface{}) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.LoggerConfig{
+		Level:        "info",
+		Format:       "json",
+		Output:       "file",
+		FilePath:     filepath.Join(t.TempDir(), "app.log"),
+		MaxSizeMB:    1,
+		BodyRoutes:   []string{"POST /api/polls/:id/vote", " PUT   /api/admin/log-level "},
+		BodyMaxBytes: maxBytes,
+		BodyRedact:   []string{"fingerprint_hash", "$.settings.password", "error.*.token"},
+	}
+	log, err := logger.NewLogger(cfg)
+	require.NoError(t, err)
+
+	m := middleware.NewMiddleware(log, &config.AdminConfig{}, &config.AuthConfig{}, cfg)
+	engine := gin.New()
+	engine.Use(m.RequestID(), m.Logger())
+
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusBadRequest, "application/json", body)
+	}
+	engine.POST("/api/polls/:id/vote", echo)
+	engine.POST("/api/polls", echo)
+
+	lastLine := func() map[string]interface{} {
+		data, err := os.ReadFile(cfg.FilePath)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(lines[len(lines)-1]), &entry))
+		return entry
+	}
+	return engine, lastLine
+}
+
+func post(engine *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("X-Request-ID", "req-42")
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLogger_BodyLogging(t *testing.T) {
+	engine, lastLine := setup(t, 1024)
+	body := `{"option_id":"a","fingerprint_hash":"secret-fp","settings":{"password":"hunter2","title":"kept"},"error":{"inner":{"token":"t0k"}}}`
+
+	rec := post(engine, "/api/polls/123/vote", body)
+
+	// The handler still sees the whole, unredacted body
+	assert.JSONEq(t, body, rec.Body.String())
+
+	entry := lastLine()
+	assert.Equal(t, "req-42", entry["request_id"])
+
+	for _, field := range []string{"request_body", "response_body"} {
+		logged, ok := entry[field].(string)
+		require.True(t, ok, field)
+		assert.NotContains(t, logged, "secret-fp")
+		assert.NotContains(t, logged, "hunter2")
+		assert.NotContains(t, logged, "t0k")
+		assert.Contains(t, logged, `"title":"kept"`)
+		assert.Contains(t, logged, `"fingerprint_hash":"[REDACTED]"`)
+	}
+}
+
+func TestLogger_BodyLoggingOptIn(t *testing.T) {
+	engine, lastLine := setup(t, 1024)
+
+	post(engine, "/api/polls", `{"question":"not logged"}`)
+
+	entry := lastLine()
+	assert.NotContains(t, entry, "request_body")
+	assert.NotContains(t, entry, "response_body")
+}
+
+func TestLogger_BodyLoggingLimits(t *testing.T) {
+	engine, lastLine := setup(t, 16)
+
+	body := `{"option_id":"a very long option id"}`
+	rec := post(engine, "/api/polls/123/vote", body)
+	assert.Equal(t, body, rec.Body.String())
+	assert.Equal(t, "[omitted: larger than 16 bytes]", lastLine()["request_body"])
+
+	post(engine, "/api/polls/123/vote", `fingerprint=abc`)
+	assert.Equal(t, "[omitted: not JSON]", lastLine()["request_body"])
+}
+
+func TestLogger_BodyLoggingRedactsAnyKeyCase(t *testing.T) {
+	engine, lastLine := setup(t, 1024)
+
+	// encoding/json binds these to the configured fields, so they must be
+	// redacted too
+	post(engine, "/api/polls/123/vote", `{"Fingerprint_Hash":"secret-fp","SETTINGS":{"Password":"hunter2"}}`)
+
+	logged, ok := lastLine()["request_body"].(string)
+	require.True(t, ok)
+	assert.NotContains(t, logged, "secret-fp")
+	assert.NotContains(t, logged, "hunter2")
+	assert.Contains(t, logged, `"Fingerprint_Hash":"[REDACTED]"`)
+}
@@ -0,0 +1,37 @@
+package postgres_test
+
+import (
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/interface/repository/postgres"
+)
+
+// votedPoll creates a poll that allows repeat votes and casts one vote per
+// option index from the same identity
+func (s *PollRepositoryTestSuite) votedPoll(identifier entity.VoteIdentifier, options ...int) *entity.Poll {
+	poll, err := entity.NewPoll("Test question?", []string{"Option 1", "Option 2"}, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(poll.ApplySettings(entity.PollSettings{DedupPolicy: entity.DedupNone}))
+	s.Require().NoError(s.pollRepo.Create(s.ctx, poll))
+
+	voteRepo := postgres.NewVoteRepository(s.db.Pool())
+	for _, option := range options {
+		vote, err := poll.Vote(poll.Options[option].ID, identifier)
+		s.Require().NoError(err)
+		s.Require().NoError(voteRepo.Create(s.ctx, vote))
+	}
+	return poll
+}
+
+func (s *PollRepositoryTestSuite) TestCrossTabCountsVotersOnce() {
+	identifier := entity.NewVoteIdentifier("ip", "fingerprint")
+	identifier.KeyID = "k1"
+	row := s.votedPoll(identifier, 0, 1)
+	column := s.votedPoll(identifier, 0, 0)
+
+	counts, err := postgres.NewAnalyticsRepository(s.db.Pool()).CrossTabCounts(s.ctx, row.ID, column.ID)
+	s.Require().NoError(err)
+	s.Require().Len(counts, 1)
+	s.Equal(1, counts[0].Votes)
+	s.Equal(row.Options[1].ID, counts[0].RowOptionID)
+	s.Equal(column.Options[0].ID, counts[0].ColumnOptionID)
+}
@@ -0,0 +1,29 @@
+package postgres_test
+
+import (
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/interface/repository/postgres"
+)
+
+func (s *PollRepositoryTestSuite) TestChallengeMarkUsed() {
+	repo := postgres.NewChallengeRepository(s.db.Pool())
+	_, err := s.db.Pool().Exec(s.ctx, "TRUNCATE used_challenges")
+	s.Require().NoError(err)
+
+	now := time.Now()
+	fresh, err := repo.MarkUsed(s.ctx, "live", now.Add(time.Minute))
+	s.Require().NoError(err)
+	s.True(fresh)
+
+	fresh, err = repo.MarkUsed(s.ctx, "live", now.Add(time.Minute))
+	s.Require().NoError(err)
+	s.False(fresh, "a challenge is only accepted once")
+
+	_, err = repo.MarkUsed(s.ctx, "expired", now.Add(-time.Minute))
+	s.Require().NoError(err)
+
+	purged, err := repo.PurgeExpired(s.ctx, now)
+	s.Require().NoError(err)
+	s.Equal(int64(1), purged)
+}
@@ -3,12 +3,14 @@ package postgres_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
 	"github.com/Sparker0i/cactro-polls/internal/domain/repository"
 	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
 	"github.com/Sparker0i/cactro-polls/internal/infrastructure/database"
 	"github.com/Sparker0i/cactro-polls/internal/interface/repository/postgres"
+	"github.com/Sparker0i/cactro-polls/migrations"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
 )
@@ -42,7 +44,10 @@ func (s *PollRepositoryTestSuite) SetupSuite() {
 	s.ctx = context.Background()
 
 	// Run migrations
-	// You would typically use a migration tool here
+	migrator, err := database.NewMigrator(db.Pool(), migrations.FS)
+	s.Require().NoError(err)
+	_, err = migrator.Up(s.ctx)
+	s.Require().NoError(err)
 }
 
 func (s *PollRepositoryTestSuite) TearDownSuite() {
@@ -112,3 +117,107 @@ func (s *PollRepositoryTestSuite) TestGetByID() {
 		})
 	}
 }
+
+func (s *PollRepositoryTestSuite) TestListKeysetPagination() {
+	for i := 0; i < 5; i++ {
+		poll, err := entity.NewPoll("Test question?", []string{"Option 1", "Option 2"}, nil)
+		s.Require().NoError(err)
+		s.Require().NoError(s.pollRepo.Create(s.ctx, poll))
+	}
+
+	first, err := s.pollRepo.List(s.ctx, repository.PollFilter{}, repository.PageRequest{Limit: 2, IncludeTotal: true})
+	s.Require().NoError(err)
+	s.Len(first.Polls, 2)
+	s.Require().NotNil(first.Total)
+	s.Equal(5, *first.Total)
+	s.Nil(first.PrevCursor)
+	s.Require().NotNil(first.NextCursor)
+
+	second, err := s.pollRepo.List(s.ctx, repository.PollFilter{}, repository.PageRequest{Limit: 2, Cursor: first.NextCursor})
+	s.Require().NoError(err)
+	s.Len(second.Polls, 2)
+	s.NotEqual(first.Polls[1].ID, second.Polls[0].ID)
+	s.Require().NotNil(second.PrevCursor)
+
+	back, err := s.pollRepo.List(s.ctx, repository.PollFilter{}, repository.PageRequest{Limit: 2, Cursor: second.PrevCursor})
+	s.Require().NoError(err)
+	s.Require().Len(back.Polls, 2)
+	s.Equal(first.Polls[0].ID, back.Polls[0].ID)
+	s.Equal(first.Polls[1].ID, back.Polls[1].ID)
+	s.Nil(back.PrevCursor)
+}
+
+func (s *PollRepositoryTestSuite) TestListSearch() {
+	pizza, err := entity.NewPoll("Best lunch option?", []string{"Pizza", "Salad"}, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(s.pollRepo.Create(s.ctx, pizza))
+
+	other, err := entity.NewPoll("Favourite colour?", []string{"Red", "Blue"}, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(s.pollRepo.Create(s.ctx, other))
+
+	// Matches option text as well as the question
+	result, err := s.pollRepo.List(s.ctx, repository.PollFilter{Search: "pizza"}, repository.PageRequest{Limit: 10})
+	s.Require().NoError(err)
+	s.Require().Len(result.Polls, 1)
+	s.Equal(pizza.ID, result.Polls[0].ID)
+}
+
+func (s *PollRepositoryTestSuite) TestListStatusScheduled() {
+	open, err := entity.NewPoll("Open now?", []string{"Yes", "No"}, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(s.pollRepo.Create(s.ctx, open))
+
+	start := time.Now().Add(time.Hour)
+	scheduled, err := entity.NewPoll("Opens later?", []string{"Yes", "No"}, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(scheduled.ApplySettings(entity.PollSettings{StartsAt: &start}))
+	s.Require().NoError(s.pollRepo.Create(s.ctx, scheduled))
+
+	result, err := s.pollRepo.List(s.ctx, repository.PollFilter{Status: entity.PollStatusScheduled}, repository.PageRequest{Limit: 10})
+	s.Require().NoError(err)
+	s.Require().Len(result.Polls, 1)
+	s.Equal(scheduled.ID, result.Polls[0].ID)
+
+	result, err = s.pollRepo.List(s.ctx, repository.PollFilter{Status: entity.PollStatusActive}, repository.PageRequest{Limit: 10})
+	s.Require().NoError(err)
+	s.Require().Len(result.Polls, 1)
+	s.Equal(open.ID, result.Polls[0].ID)
+}
+
+func (s *PollRepositoryTestSuite) TestUpdateKeepsSearchVector() {
+	poll, err := entity.NewPoll("Best lunch option?", []string{"Pizza", "Salad"}, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(s.pollRepo.Create(s.ctx, poll))
+
+	// Updates without a question change leave the search document intact
+	poll.IsActive = false
+	s.Require().NoError(s.pollRepo.Update(s.ctx, poll))
+	result, err := s.pollRepo.List(s.ctx, repository.PollFilter{Search: "pizza"}, repository.PageRequest{Limit: 10})
+	s.Require().NoError(err)
+	s.Len(result.Polls, 1)
+
+	poll.Question = "Favourite dessert?"
+	s.Require().NoError(s.pollRepo.Update(s.ctx, poll))
+	result, err = s.pollRepo.List(s.ctx, repository.PollFilter{Search: "dessert"}, repository.PageRequest{Limit: 10})
+	s.Require().NoError(err)
+	s.Len(result.Polls, 1)
+}
+
+func (s *PollRepositoryTestSuite) TestGetByIDs() {
+	first, err := entity.NewPoll("First?", []string{"Option 1", "Option 2"}, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(s.pollRepo.Create(s.ctx, first))
+	second, err := entity.NewPoll("Second?", []string{"Option 1", "Option 2", "Option 3"}, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(s.pollRepo.Create(s.ctx, second))
+
+	// Missing polls are skipped and the requested order is kept
+	polls, err := s.pollRepo.GetByIDs(s.ctx, []uuid.UUID{second.ID, uuid.New(), first.ID})
+	s.Require().NoError(err)
+	s.Require().Len(polls, 2)
+	s.Equal(second.ID, polls[0].ID)
+	s.Len(polls[0].Options, 3)
+	s.Equal(first.ID, polls[1].ID)
+	s.Len(polls[1].Options, 2)
+}
@@ -0,0 +1,53 @@
+package postgres_test
+
+import (
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/interface/repository/postgres"
+)
+
+// archiveWithAudit archives a new poll and records the audit event in one
+// transaction, rolling back instead of committing when commit is false
+func (s *PollRepositoryTestSuite) archiveWithAudit(commit bool) *entity.Poll {
+	poll, err := entity.NewPoll("Test question?", []string{"Option 1", "Option 2"}, nil)
+	s.Require().NoError(err)
+	s.Require().NoError(s.pollRepo.Create(s.ctx, poll))
+
+	auditRepo := postgres.NewAuditRepository(s.db.Pool())
+	tx, err := postgres.NewTransactionManager(s.db.Pool()).Begin(s.ctx)
+	s.Require().NoError(err)
+
+	ctx := tx.Context()
+	s.Require().NoError(s.pollRepo.Archive(ctx, poll.ID))
+	s.Require().NoError(auditRepo.Record(ctx, entity.NewAuditEvent(entity.AuditPollArchived, poll, poll, entity.Actor{IsAdmin: true})))
+
+	if commit {
+		s.Require().NoError(tx.Commit())
+	} else {
+		s.Require().NoError(tx.Rollback())
+	}
+	return poll
+}
+
+func (s *PollRepositoryTestSuite) TestTransactionCommitsChangeWithAudit() {
+	poll := s.archiveWithAudit(true)
+
+	saved, err := s.pollRepo.GetByIDIncludingDeleted(s.ctx, poll.ID)
+	s.Require().NoError(err)
+	s.NotNil(saved.ArchivedAt)
+
+	events, err := postgres.NewAuditRepository(s.db.Pool()).ListByPoll(s.ctx, poll.ID)
+	s.Require().NoError(err)
+	s.Len(events, 1)
+}
+
+func (s *PollRepositoryTestSuite) TestTransactionRollsBackChangeWithAudit() {
+	poll := s.archiveWithAudit(false)
+
+	saved, err := s.pollRepo.GetByIDIncludingDeleted(s.ctx, poll.ID)
+	s.Require().NoError(err)
+	s.Nil(saved.ArchivedAt)
+
+	events, err := postgres.NewAuditRepository(s.db.Pool()).ListByPoll(s.ctx, poll.ID)
+	s.Require().NoError(err)
+	s.Empty(events)
+}
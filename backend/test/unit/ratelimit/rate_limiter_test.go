@@ -0,0 +1,60 @@
+package ratelimit_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLimiter(t *testing.T, perMinute int) ratelimit.RateLimiter {
+	rl := ratelimit.NewRateLimiter(&config.RateLimitConfig{
+		Enabled:           true,
+		RequestsPerMinute: perMinute,
+		TTL:               time.Minute,
+	})
+	t.Cleanup(rl.Stop)
+	return rl
+}
+
+func TestRateLimiter_ConcurrentAllow(t *testing.T) {
+	rl := newLimiter(t, 5)
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rl.Allow("key") {
+				allowed.Add(1)
+			}
+			rl.RemainingTokens("key")
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(5), allowed.Load())
+	assert.Equal(t, uint64(45), rl.Rejected())
+}
+
+func TestRateLimiter_Refund(t *testing.T) {
+	rl := newLimiter(t, 2)
+
+	assert.True(t, rl.Allow("key"))
+	rl.Refund("key")
+	assert.True(t, rl.Allow("key"))
+	assert.True(t, rl.Allow("key"))
+	assert.False(t, rl.Allow("key"))
+
+	// Refunds never raise a bucket above its capacity
+	rl.Refund("other")
+	assert.Equal(t, 2, rl.RemainingTokens("other"))
+
+	// Keys are limited independently
+	assert.True(t, rl.Allow("other"))
+}
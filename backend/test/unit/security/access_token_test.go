@@ -0,0 +1,44 @@
+package security_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/security"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTokens(t *testing.T) {
+	tokens, err := security.NewAccessTokens("test-secret", time.Minute)
+	require.NoError(t, err)
+	pollID := uuid.New()
+
+	token, expiresAt, err := tokens.Issue(pollID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, 2*time.Second)
+
+	assert.True(t, tokens.Verify(token, pollID))
+	assert.False(t, tokens.Verify(token, uuid.New()), "token is bound to its poll")
+
+	other, err := security.NewAccessTokens("other-secret", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, other.Verify(token, pollID), "token is bound to its secret")
+
+	payload, sig, _ := strings.Cut(token, ".")
+	assert.False(t, tokens.Verify(payload+"."+sig[1:], pollID))
+	assert.False(t, tokens.Verify("garbage", pollID))
+}
+
+func TestAccessTokens_Expired(t *testing.T) {
+	tokens, err := security.NewAccessTokens("test-secret", -time.Minute)
+	require.NoError(t, err)
+	pollID := uuid.New()
+
+	token, _, err := tokens.Issue(pollID)
+	require.NoError(t, err)
+
+	assert.False(t, tokens.Verify(token, pollID))
+}
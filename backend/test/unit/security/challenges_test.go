@@ -0,0 +1,142 @@
+package security_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/security"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newChallenges(t *testing.T) *security.Challenges {
+	challenges, err := security.NewChallenges(security.ChallengeOptions{
+		Secret:         "test-secret",
+		TTL:            time.Minute,
+		BaseDifficulty: 4,
+		MaxDifficulty:  8,
+		Window:         time.Minute,
+		VotesPerStep:   10,
+	}, nil)
+	require.NoError(t, err)
+	return challenges
+}
+
+func solve(token string, difficulty int) string {
+	for i := 0; ; i++ {
+		if solution := strconv.Itoa(i); security.Solves(token, solution, difficulty) {
+			return solution
+		}
+	}
+}
+
+func TestChallenges_Difficulty(t *testing.T) {
+	challenges := newChallenges(t)
+
+	assert.Equal(t, 4, challenges.Difficulty(0))
+	assert.Equal(t, 4, challenges.Difficulty(9))
+	assert.Equal(t, 5, challenges.Difficulty(10))
+	assert.Equal(t, 6, challenges.Difficulty(20))
+	assert.Equal(t, 7, challenges.Difficulty(40))
+	assert.Equal(t, 8, challenges.Difficulty(10000), "capped at the maximum")
+}
+
+func TestChallenges_Verify(t *testing.T) {
+	challenges := newChallenges(t)
+	pollID := uuid.New()
+
+	challenge, err := challenges.Issue(pollID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, challenge.Difficulty)
+
+	solution := solve(challenge.Token, challenge.Difficulty)
+
+	assert.ErrorIs(t, challenges.Verify(context.Background(), challenge.Token, solution, uuid.New()), entity.ErrInvalidProofOfWork, "bound to its poll")
+	assert.NoError(t, challenges.Verify(context.Background(), challenge.Token, solution, pollID))
+	assert.ErrorIs(t, challenges.Verify(context.Background(), challenge.Token, solution, pollID), entity.ErrInvalidProofOfWork, "single use")
+}
+
+func TestChallenges_RejectsBadSolutions(t *testing.T) {
+	challenges := newChallenges(t)
+	pollID := uuid.New()
+
+	challenge, err := challenges.Issue(pollID, 100)
+	require.NoError(t, err)
+
+	// Find a solution that misses the required difficulty
+	wrong := ""
+	for i := 0; ; i++ {
+		if !security.Solves(challenge.Token, strconv.Itoa(i), challenge.Difficulty) {
+			wrong = strconv.Itoa(i)
+			break
+		}
+	}
+	assert.ErrorIs(t, challenges.Verify(context.Background(), challenge.Token, wrong, pollID), entity.ErrInvalidProofOfWork)
+	assert.ErrorIs(t, challenges.Verify(context.Background(), "garbage", "0", pollID), entity.ErrInvalidProofOfWork)
+
+	// A challenge lowered to an easier difficulty no longer matches its signature
+	other := newChallenges(t)
+	easy, err := other.Issue(pollID, 0)
+	require.NoError(t, err)
+	forged := easy.Token[:len(easy.Token)-2] + "AA"
+	assert.ErrorIs(t, challenges.Verify(context.Background(), forged, solve(forged, easy.Difficulty), pollID), entity.ErrInvalidProofOfWork)
+}
+
+func TestChallenges_Expired(t *testing.T) {
+	challenges, err := security.NewChallenges(security.ChallengeOptions{
+		Secret: "test-secret",
+		TTL:    -time.Minute,
+	}, nil)
+	require.NoError(t, err)
+	pollID := uuid.New()
+
+	challenge, err := challenges.Issue(pollID, 0)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, challenges.Verify(context.Background(), challenge.Token, "0", pollID), entity.ErrInvalidProofOfWork)
+}
+
+// sharedChallenges is a used-challenge store shared by several issuers, as
+// the database is shared by several instances
+type sharedChallenges struct {
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+func (s *sharedChallenges) MarkUsed(ctx context.Context, tokenHash string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.used[tokenHash]; ok {
+		return false, nil
+	}
+	s.used[tokenHash] = expiresAt
+	return true, nil
+}
+
+func (s *sharedChallenges) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestChallenges_SingleUseAcrossInstances(t *testing.T) {
+	used := &sharedChallenges{used: map[string]time.Time{}}
+	opts := security.ChallengeOptions{Secret: "test-secret", TTL: time.Minute, BaseDifficulty: 4, MaxDifficulty: 4, VotesPerStep: 1}
+	first, err := security.NewChallenges(opts, used)
+	require.NoError(t, err)
+	second, err := security.NewChallenges(opts, used)
+	require.NoError(t, err)
+	pollID := uuid.New()
+
+	challenge, err := first.Issue(pollID, 0)
+	require.NoError(t, err)
+	solution := solve(challenge.Token, challenge.Difficulty)
+
+	assert.NoError(t, first.Verify(context.Background(), challenge.Token, solution, pollID))
+	assert.ErrorIs(t, second.Verify(context.Background(), challenge.Token, solution, pollID), entity.ErrInvalidProofOfWork,
+		"a challenge solved on one instance cannot be replayed on another")
+	assert.NotContains(t, used.used, challenge.Token, "only a hash of the token is stored")
+}
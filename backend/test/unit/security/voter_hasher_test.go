@@ -0,0 +1,87 @@
+package security_test
+
+import (
+	"testing"
+
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoterHasher_Identify(t *testing.T) {
+	hasher, err := security.NewVoterHasher("", map[string]string{"k1": "secret-one"}, false)
+	require.NoError(t, err)
+
+	identifier := hasher.Identify("203.0.113.7", "fingerprint", "")
+
+	assert.Equal(t, "k1", identifier.KeyID)
+	assert.Len(t, identifier.IPHash, 64)
+	assert.NotEqual(t, identifier.IPHash, identifier.FingerprintHash)
+	assert.NotEqual(t, "fingerprint", identifier.FingerprintHash)
+	assert.Empty(t, identifier.Rotated)
+	assert.Equal(t, identifier, hasher.Identify("203.0.113.7", "fingerprint", ""), "hashing is deterministic")
+
+	other, err := security.NewVoterHasher("", map[string]string{"k1": "secret-two"}, false)
+	require.NoError(t, err)
+	assert.NotEqual(t, identifier.IPHash, other.Identify("203.0.113.7", "fingerprint", "").IPHash)
+
+	assert.Empty(t, hasher.Identify("203.0.113.7", "", "").FingerprintHash)
+}
+
+func TestVoterHasher_Rotation(t *testing.T) {
+	before, err := security.NewVoterHasher("k1", map[string]string{"k1": "secret-one"}, true)
+	require.NoError(t, err)
+	after, err := security.NewVoterHasher("k2", map[string]string{"k1": "secret-one", "k2": "secret-two"}, true)
+	require.NoError(t, err)
+
+	old := before.Identify("203.0.113.7", "fingerprint", "")
+	current := after.Identify("203.0.113.7", "fingerprint", "")
+
+	assert.Equal(t, "k2", current.KeyID)
+	require.Len(t, current.Rotated, 2)
+
+	// The voter is still recognised by the hashes stored under the old key
+	rotated := current.Rotated[0]
+	assert.Equal(t, "k1", rotated.KeyID)
+	assert.Equal(t, old.IPHash, rotated.IPHash)
+	assert.Equal(t, old.FingerprintHash, rotated.FingerprintHash)
+
+	legacy := current.Rotated[1]
+	assert.Equal(t, security.LegacyKeyID, legacy.KeyID)
+	assert.Equal(t, "fingerprint", legacy.FingerprintHash)
+}
+
+func TestNewVoterHasher_InvalidKeys(t *testing.T) {
+	_, err := security.NewVoterHasher("", map[string]string{"k1": "a", "k2": "b"}, false)
+	assert.Error(t, err, "current key is ambiguous")
+
+	_, err = security.NewVoterHasher("k3", map[string]string{"k1": "a"}, false)
+	assert.Error(t, err, "current key is missing")
+
+	_, err = security.NewVoterHasher("", map[string]string{security.LegacyKeyID: "a"}, false)
+	assert.Error(t, err, "legacy ID is reserved")
+
+	hasher, err := security.NewVoterHasher("", nil, false)
+	require.NoError(t, err)
+	assert.NotEmpty(t, hasher.Identify("203.0.113.7", "fp", "").IPHash)
+}
+
+func TestVoterHasher_AnomalySignals(t *testing.T) {
+	hasher, err := security.NewVoterHasher("", map[string]string{"k1": "secret-one"}, false)
+	require.NoError(t, err)
+
+	a := hasher.Identify("203.0.113.7", "fp", "curl/8.0")
+	b := hasher.Identify("203.0.113.200", "fp", "curl/8.0")
+	other := hasher.Identify("198.51.100.7", "fp", "Mozilla/5.0")
+
+	assert.NotEqual(t, a.IPHash, b.IPHash)
+	assert.Equal(t, a.SubnetHash, b.SubnetHash, "same /24")
+	assert.NotEqual(t, a.SubnetHash, other.SubnetHash)
+	assert.Equal(t, a.UserAgentHash, b.UserAgentHash)
+	assert.NotEqual(t, a.UserAgentHash, other.UserAgentHash)
+
+	v6a := hasher.Identify("2001:db8:1:2::1", "fp", "")
+	v6b := hasher.Identify("2001:db8:1:ffff::1", "fp", "")
+	assert.Equal(t, v6a.SubnetHash, v6b.SubnetHash, "same /48")
+	assert.Empty(t, v6a.UserAgentHash)
+}
@@ -0,0 +1,21 @@
+// Package testutil holds fixtures shared by the unit test packages
+package testutil
+
+import (
+	"context"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/domain/service"
+	"github.com/google/uuid"
+)
+
+// VotingService fails every vote with Err, or records it when Err is nil.
+// Any other PollService method panics.
+type VotingService struct {
+	service.PollService
+	Err error
+}
+
+func (s *VotingService) Vote(ctx context.Context, pollID, optionID uuid.UUID, identifier entity.VoteIdentifier) error {
+	return s.Err
+}
@@ -0,0 +1,145 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Sparker0i/cactro-polls/internal/domain/entity"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/config"
+	"github.com/Sparker0i/cactro-polls/internal/infrastructure/tracing"
+	"github.com/Sparker0i/cactro-polls/test/unit/testutil"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// keptExporter holds on to spans after shutdown so they can be inspected
+type keptExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keptExporter) Shutdown(context.Context) error {
+	return nil
+}
+
+func newTracing(t *testing.T) (*tracing.Tracing, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tr := tracing.NewTracing(&config.TracingConfig{
+		ServiceName: "test",
+		SampleRatio: 1,
+	}, keptExporter{exporter}, nil)
+	return tr, exporter
+}
+
+// flush shuts tr down and returns every span it recorded
+func flush(t *testing.T, tr *tracing.Tracing, exporter *tracetest.InMemoryExporter) tracetest.SpanStubs {
+	require.NoError(t, tr.Shutdown(context.Background()))
+	return exporter.GetSpans()
+}
+
+func attr(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracing_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tr, exporter := newTracing(t)
+
+	var handlerSpan trace.SpanContext
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("request_id", "req-1")
+	})
+	engine.Use(tr.Middleware())
+	engine.GET("/api/polls/:id", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/polls/1", nil)
+	req.Header.Set("traceparent", traceparent)
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	spans := flush(t, tr, exporter)
+	require.Len(t, spans, 1)
+	span := spans[0]
+
+	assert.Equal(t, "GET /api/polls/:id", span.Name)
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Equal(t, "req-1", attr(span, tracing.RequestIDKey).AsString())
+	assert.Equal(t, int64(500), attr(span, "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Error, span.Status.Code)
+
+	// The handler runs inside the span and the response carries it onwards
+	assert.Equal(t, span.SpanContext.SpanID(), handlerSpan.SpanID())
+	assert.Contains(t, rec.Header().Get("traceparent"), span.SpanContext.SpanID().String())
+}
+
+func TestTracing_QueryLogger(t *testing.T) {
+	tr, exporter := newTracing(t)
+	logger := tr.QueryLogger()
+
+	ctx, parent := tr.Tracer().Start(context.Background(), "parent")
+	logger.Log(ctx, pgx.LogLevelInfo, "Dialing PostgreSQL server", map[string]interface{}{"host": "localhost"})
+	logger.Log(ctx, pgx.LogLevelInfo, "Query", map[string]interface{}{
+		"sql":  "SELECT 1",
+		"args": []interface{}{"secret"},
+		"time": 20 * time.Millisecond,
+	})
+	logger.Log(ctx, pgx.LogLevelError, "Exec", map[string]interface{}{
+		"sql":  "DELETE FROM polls",
+		"err":  errors.New("boom"),
+		"time": time.Millisecond,
+	})
+	parent.End()
+
+	spans := flush(t, tr, exporter)
+	require.Len(t, spans, 3)
+
+	query, exec := spans[0], spans[1]
+	assert.Equal(t, "db.Query", query.Name)
+	assert.Equal(t, "SELECT 1", attr(query, "db.statement").AsString())
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent.SpanID())
+	assert.Equal(t, 20*time.Millisecond, query.EndTime.Sub(query.StartTime))
+	for _, kv := range query.Attributes {
+		assert.NotContains(t, kv.Value.Emit(), "secret")
+	}
+
+	assert.Equal(t, "db.Exec", exec.Name)
+	assert.Equal(t, codes.Error, exec.Status.Code)
+}
+
+func TestTracePollService_Vote(t *testing.T) {
+	tr, exporter := newTracing(t)
+	pollID := uuid.New()
+
+	svc := tracing.TracePollService(&testutil.VotingService{Err: entity.ErrDuplicateVote}, tr)
+	err := svc.Vote(context.Background(), pollID, uuid.New(), entity.VoteIdentifier{})
+	assert.ErrorIs(t, err, entity.ErrDuplicateVote)
+
+	spans := flush(t, tr, exporter)
+	require.Len(t, spans, 1)
+	assert.Equal(t, "PollService.Vote", spans[0].Name)
+	assert.Equal(t, pollID.String(), attr(spans[0], tracing.PollIDKey).AsString())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}